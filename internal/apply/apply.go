@@ -0,0 +1,126 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"swag-cli/internal/docker"
+
+	"github.com/fatih/color"
+)
+
+// Strategy 决定配置变更后如何让 SWAG 生效
+type Strategy string
+
+const (
+	StrategyAuto    Strategy = "auto"    // 先 reload，失败时回退为重启容器
+	StrategyReload  Strategy = "reload"  // nginx -t 通过后执行 nginx -s reload
+	StrategyRestart Strategy = "restart" // 重启容器并等待就绪
+	StrategyNone    Strategy = "none"    // 不做任何操作（批量修改时使用）
+)
+
+// Strategies 返回所有可用策略
+func Strategies() []string {
+	return []string{string(StrategyAuto), string(StrategyReload), string(StrategyRestart), string(StrategyNone)}
+}
+
+// ParseStrategy 解析策略名，空字符串视为 auto
+func ParseStrategy(s string) (Strategy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return StrategyAuto, nil
+	}
+	for _, v := range Strategies() {
+		if s == v {
+			return Strategy(s), nil
+		}
+	}
+	return "", fmt.Errorf("未知的 reload 策略: %s (可选: %s)", s, strings.Join(Strategies(), ", "))
+}
+
+// Docker 是 Service 依赖的容器操作，由 *docker.Client 实现
+type Docker interface {
+	TestNginx(ctx context.Context, name string) error
+	ReloadNginx(ctx context.Context, name string) error
+	RestartContainer(ctx context.Context, name string) error
+	WaitReady(ctx context.Context, name string, timeout time.Duration) error
+	StartupLogs(ctx context.Context, name string, w io.Writer) error
+}
+
+// Service 按策略让 SWAG 应用配置变更，CLI 与 TUI 共用
+type Service struct {
+	Docker      Docker
+	Container   string
+	Strategy    Strategy
+	WaitTimeout time.Duration
+	// LogWriter 非 nil 时，重启后未能就绪会把容器启动日志写入其中
+	LogWriter io.Writer
+}
+
+// Apply 按策略应用变更，返回 nil 表示已生效（或策略为 none）。
+//
+// auto 策略下 nginx -t 报告配置错误时不会重启容器（重启同样会失败并导致 SWAG 不可用）。
+func (s Service) Apply(ctx context.Context) error {
+	switch s.Strategy {
+	case StrategyNone:
+		color.Yellow("已跳过 reload（策略: none），稍后可执行: swag-cli reload")
+		return nil
+	case StrategyReload:
+		return s.reload(ctx)
+	case StrategyRestart:
+		return s.restart(ctx)
+	case StrategyAuto, "":
+		err := s.reload(ctx)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, docker.ErrNginxConfig) {
+			return err
+		}
+		color.Yellow("Nginx 重载失败: %v", err)
+		color.Yellow("将重启 SWAG 容器以应用配置...")
+		return s.restart(ctx)
+	default:
+		return fmt.Errorf("未知的 reload 策略: %s", s.Strategy)
+	}
+}
+
+func (s Service) reload(ctx context.Context) error {
+	color.Yellow("正在重载 SWAG (%s) Nginx...", s.Container)
+	if err := s.Docker.TestNginx(ctx, s.Container); err != nil {
+		return err
+	}
+	if err := s.Docker.ReloadNginx(ctx, s.Container); err != nil {
+		return err
+	}
+	color.Green("Nginx 重载成功！站点应已生效。")
+	return nil
+}
+
+func (s Service) restart(ctx context.Context) error {
+	color.Yellow("正在重启 SWAG 容器 (%s)...", s.Container)
+	if err := s.Docker.RestartContainer(ctx, s.Container); err != nil {
+		return fmt.Errorf("重启 SWAG 容器失败: %w", err)
+	}
+
+	timeout := s.WaitTimeout
+	if timeout <= 0 {
+		timeout = docker.DefaultReadyTimeout
+	}
+	color.Yellow("等待 SWAG 就绪（最长 %s）...", timeout)
+	if err := s.Docker.WaitReady(ctx, s.Container, timeout); err != nil {
+		if s.LogWriter != nil {
+			color.Yellow("容器启动日志:")
+			if logErr := s.Docker.StartupLogs(ctx, s.Container, s.LogWriter); logErr != nil {
+				color.Red("%v", logErr)
+			}
+		}
+		return fmt.Errorf("SWAG 未能就绪: %w", err)
+	}
+	color.Green("SWAG 容器重启成功！站点应已生效。")
+	return nil
+}
@@ -0,0 +1,120 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"swag-cli/internal/docker"
+)
+
+func TestParseStrategy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]Strategy{
+		"":         StrategyAuto,
+		"auto":     StrategyAuto,
+		" Reload ": StrategyReload,
+		"restart":  StrategyRestart,
+		"none":     StrategyNone,
+	}
+	for in, want := range cases {
+		got, err := ParseStrategy(in)
+		if err != nil {
+			t.Fatalf("ParseStrategy(%q) error = %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("ParseStrategy(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := ParseStrategy("graceful"); err == nil {
+		t.Fatalf("ParseStrategy() should reject unknown strategy")
+	}
+}
+
+func TestApplyStrategies(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		strategy  Strategy
+		reloadErr error
+		testErr   error
+		wantCalls string
+		wantErr   bool
+	}{
+		{name: "none", strategy: StrategyNone, wantCalls: ""},
+		{name: "reload", strategy: StrategyReload, wantCalls: "test,reload"},
+		{name: "reload failure", strategy: StrategyReload, reloadErr: errors.New("boom"), wantCalls: "test,reload", wantErr: true},
+		{name: "restart", strategy: StrategyRestart, wantCalls: "restart,wait"},
+		{name: "auto reloads", strategy: StrategyAuto, wantCalls: "test,reload"},
+		{name: "auto falls back", strategy: StrategyAuto, reloadErr: errors.New("nginx not running"), wantCalls: "test,reload,restart,wait"},
+		{name: "auto keeps running on bad config", strategy: StrategyAuto, testErr: fmt.Errorf("%w: bad", docker.ErrNginxConfig), wantCalls: "test", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeDocker{testErr: tc.testErr, reloadErr: tc.reloadErr}
+			err := Service{Docker: fake, Container: "swag", Strategy: tc.strategy}.Apply(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := strings.Join(fake.calls, ","); got != tc.wantCalls {
+				t.Fatalf("calls = %q, want %q", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestApplyRestartWritesLogsWhenNotReady(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeDocker{waitErr: errors.New("timeout")}
+	var logs strings.Builder
+	err := Service{Docker: fake, Container: "swag", Strategy: StrategyRestart, LogWriter: &logs}.Apply(context.Background())
+	if err == nil {
+		t.Fatalf("Apply() should fail when SWAG is not ready")
+	}
+	if logs.String() != "startup log\n" {
+		t.Fatalf("unexpected logs: %q", logs.String())
+	}
+}
+
+type fakeDocker struct {
+	calls     []string
+	testErr   error
+	reloadErr error
+	waitErr   error
+}
+
+func (f *fakeDocker) TestNginx(ctx context.Context, name string) error {
+	f.calls = append(f.calls, "test")
+	return f.testErr
+}
+
+func (f *fakeDocker) ReloadNginx(ctx context.Context, name string) error {
+	f.calls = append(f.calls, "reload")
+	return f.reloadErr
+}
+
+func (f *fakeDocker) RestartContainer(ctx context.Context, name string) error {
+	f.calls = append(f.calls, "restart")
+	return nil
+}
+
+func (f *fakeDocker) WaitReady(ctx context.Context, name string, timeout time.Duration) error {
+	f.calls = append(f.calls, "wait")
+	return f.waitErr
+}
+
+func (f *fakeDocker) StartupLogs(ctx context.Context, name string, w io.Writer) error {
+	_, err := io.WriteString(w, "startup log\n")
+	return err
+}
@@ -0,0 +1,121 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"swag-cli/internal/compose"
+	"swag-cli/internal/docker"
+	"swag-cli/internal/swagenv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/fatih/color"
+)
+
+// CoverageDocker 是证书覆盖检查依赖的容器操作，由 *docker.Client 实现
+type CoverageDocker interface {
+	InspectContainer(ctx context.Context, name string) (*types.ContainerJSON, error)
+	RecreateContainer(ctx context.Context, name string, opts docker.RecreateOptions) (docker.RecreateResult, error)
+}
+
+// CertCoverage 检查新站点是否被 SWAG 证书覆盖（非 wildcard 证书时需要把子域名加入 SUBDOMAINS），
+// 未覆盖时更新 compose.yaml 并重建 SWAG 容器，CLI 与 TUI 共用
+type CertCoverage struct {
+	Docker      CoverageDocker
+	Container   string
+	ComposePath string
+	WaitTimeout time.Duration
+	// Confirm 在修改 compose.yaml 前询问用户，为 nil 时直接执行
+	Confirm func(prompt string) (bool, error)
+	// LogWriter 非 nil 时，新容器未能就绪会把其启动日志写入其中
+	LogWriter io.Writer
+}
+
+// Ensure 检查并按需处理子域名的证书覆盖，recreated 为 true 表示已重建容器，调用方无需再 reload；
+// 读取容器、更新 compose.yaml 或重建失败时返回错误，由调用方输出并决定是否退出
+func (c CertCoverage) Ensure(ctx context.Context, subdomain string) (recreated bool, err error) {
+	info, err := c.Docker.InspectContainer(ctx, c.Container)
+	if err != nil {
+		return false, fmt.Errorf("读取 SWAG 容器 (%s) 失败: %w", c.Container, err)
+	}
+	if info.Config == nil {
+		return false, fmt.Errorf("SWAG 容器 (%s) 缺少配置信息，无法检查证书覆盖", c.Container)
+	}
+
+	cov, err := swagenv.CheckCoverage(info.Config.Env, c.ComposePath, c.Container, subdomain)
+	if errors.Is(err, swagenv.ErrNoURL) {
+		color.Yellow("SWAG 容器未设置 URL 环境变量，无法确定基础域名，跳过证书覆盖检查")
+		return false, nil
+	}
+	if cov.Covered {
+		return false, nil
+	}
+	color.Yellow("注意: 证书未覆盖 %s（SWAG 未使用 wildcard 证书，且 SUBDOMAINS/EXTRA_DOMAINS 不包含该子域名）", cov.Host)
+	if err != nil {
+		color.Yellow("无法自动更新 SUBDOMAINS: %v", err)
+		color.Yellow("请手动将 %s 加入 SWAG 的 SUBDOMAINS 环境变量并重建容器。", cov.Subdomain)
+		return false, nil
+	}
+
+	if cov.ComposeCovered {
+		color.Yellow("compose.yaml 已包含该子域名，但 SWAG 容器尚未重建。")
+	} else {
+		color.Cyan("将更新 %s (服务 %s):\n  SUBDOMAINS: %q -> %q", cov.ComposePath, cov.ComposeService, cov.OldSubdomains, cov.NewSubdomains)
+	}
+	if c.Confirm != nil {
+		ok, err := c.Confirm("是否更新 compose.yaml 并重建 SWAG 容器以申请证书？")
+		if err != nil || !ok {
+			color.Yellow("已跳过，证书覆盖需手动处理。")
+			return false, nil
+		}
+	}
+
+	backup, err := swagenv.ApplyCoverage(cov)
+	if err != nil {
+		return false, fmt.Errorf("更新 compose.yaml 失败: %w", err)
+	}
+	if backup != "" {
+		color.Cyan("已创建备份: %s", backup)
+		color.Green("已更新: %s", cov.ComposePath)
+	}
+
+	f, err := compose.Load(cov.ComposePath)
+	if err != nil {
+		return false, err
+	}
+	var opts docker.RecreateOptions
+	if err := ComposeRuntime(&opts, f, cov.ComposeService); err != nil {
+		return false, err
+	}
+	opts.HealthTimeout = c.WaitTimeout
+	opts.Progress = func(msg string) { color.Cyan("  %s", msg) }
+	opts.LogWriter = c.LogWriter
+
+	color.Yellow("正在重建 SWAG 容器 (%s)...", c.Container)
+	res, err := c.Docker.RecreateContainer(ctx, c.Container, opts)
+	if err != nil {
+		if res.RolledBack {
+			return false, fmt.Errorf("重建 SWAG 容器失败（已回滚到旧容器，SWAG 仍以原配置运行）: %w", err)
+		}
+		return false, fmt.Errorf("重建 SWAG 容器失败: %w", err)
+	}
+	color.Green("SWAG 容器已重建，证书将包含 %s。", cov.Host)
+	return true, nil
+}
+
+// ComposeRuntime 用 compose 服务定义填充重建参数
+func ComposeRuntime(opts *docker.RecreateOptions, f *compose.File, service string) error {
+	rt, err := f.Runtime(service)
+	if err != nil {
+		return err
+	}
+	opts.Image = rt.Image
+	opts.Env = rt.Env
+	opts.Binds = rt.Binds
+	opts.AnonymousVolumes = rt.AnonymousVolumes
+	opts.Networks = rt.Networks
+	return nil
+}
@@ -0,0 +1,113 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"swag-cli/internal/docker"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCertCoverageEnsure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	composePath := filepath.Join(dir, "compose.yaml")
+	compose := "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    container_name: swag\n    environment:\n      - URL=example.com\n      - SUBDOMAINS=www\n"
+	if err := os.WriteFile(composePath, []byte(compose), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cases := []struct {
+		name        string
+		env         []string
+		inspectErr  error
+		wantRebuilt bool
+		wantErr     bool
+	}{
+		{name: "inspect error", inspectErr: os.ErrNotExist, wantErr: true},
+		{name: "no URL", env: []string{"SUBDOMAINS=www"}},
+		{name: "covered", env: []string{"URL=example.com", "SUBDOMAINS=wildcard"}},
+		{name: "not covered", env: []string{"URL=example.com", "SUBDOMAINS=www"}, wantRebuilt: true},
+	}
+	for _, tc := range cases {
+		fake := &fakeCoverageDocker{env: tc.env, inspectErr: tc.inspectErr}
+		c := CertCoverage{Docker: fake, Container: "swag", ComposePath: composePath}
+		got, err := c.Ensure(context.Background(), "app")
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: Ensure() error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+		if got != tc.wantRebuilt {
+			t.Fatalf("%s: Ensure() = %v, want %v", tc.name, got, tc.wantRebuilt)
+		}
+		if tc.wantRebuilt != (fake.recreated != nil) {
+			t.Fatalf("%s: recreated = %+v", tc.name, fake.recreated)
+		}
+	}
+
+	b, err := os.ReadFile(composePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(b), "SUBDOMAINS=www,app") {
+		t.Fatalf("compose not updated:\n%s", b)
+	}
+}
+
+func TestCertCoverageEnsureRecreateError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	composePath := filepath.Join(dir, "compose.yaml")
+	compose := "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    container_name: swag\n    environment:\n      - URL=example.com\n      - SUBDOMAINS=www\n"
+	if err := os.WriteFile(composePath, []byte(compose), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := &fakeCoverageDocker{
+		env:         []string{"URL=example.com", "SUBDOMAINS=www"},
+		recreateErr: os.ErrDeadlineExceeded,
+		rolledBack:  true,
+	}
+	c := CertCoverage{Docker: fake, Container: "swag", ComposePath: composePath}
+	got, err := c.Ensure(context.Background(), "app")
+	if got || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Ensure() = %v, %v, want false, %v", got, err, os.ErrDeadlineExceeded)
+	}
+	if !strings.Contains(err.Error(), "已回滚") {
+		t.Fatalf("Ensure() error = %v, want rollback note", err)
+	}
+}
+
+type fakeCoverageDocker struct {
+	env         []string
+	inspectErr  error
+	recreateErr error
+	rolledBack  bool
+	recreated   *docker.RecreateOptions
+}
+
+func (f *fakeCoverageDocker) InspectContainer(ctx context.Context, name string) (*types.ContainerJSON, error) {
+	if f.inspectErr != nil {
+		return nil, f.inspectErr
+	}
+	return &types.ContainerJSON{Config: &container.Config{Env: f.env}}, nil
+}
+
+func (f *fakeCoverageDocker) RecreateContainer(ctx context.Context, name string, opts docker.RecreateOptions) (docker.RecreateResult, error) {
+	if !slices.Contains(opts.Env, "SUBDOMAINS=www,app") {
+		return docker.RecreateResult{}, os.ErrInvalid
+	}
+	if f.recreateErr != nil {
+		return docker.RecreateResult{RolledBack: f.rolledBack}, f.recreateErr
+	}
+	f.recreated = &opts
+	return docker.RecreateResult{}, nil
+}
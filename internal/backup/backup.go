@@ -0,0 +1,285 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"swag-cli/internal/swagexport"
+)
+
+const (
+	// IndexName 为备份目录中记录全部备份的索引文件
+	IndexName = "index.json"
+
+	filePrefix  = "swag-backup."
+	timeLayout  = "20060102-150405"
+	indexFormat = 1
+)
+
+// Entry 为索引中的一条备份记录
+type Entry struct {
+	File       string              `json:"file"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	Size       int64               `json:"size"`
+	FileCount  int                 `json:"fileCount"`
+	TotalBytes int64               `json:"totalBytes"`
+	Encrypted  bool                `json:"encrypted"`
+	Format     string              `json:"format"`
+	Manifest   swagexport.Manifest `json:"manifest"`
+
+	// Missing 表示索引中记录的归档文件已不存在（不写入索引）
+	Missing bool `json:"-"`
+	// Untracked 表示归档存在但未记录在索引中（不写入索引）
+	Untracked bool `json:"-"`
+}
+
+// Index 为 index.json 的内容
+type Index struct {
+	FormatVersion int     `json:"formatVersion"`
+	Backups       []Entry `json:"backups"`
+}
+
+// Options 描述一次备份
+type Options struct {
+	// Dir 为备份目录
+	Dir string
+	// Export 为导出参数，OutPath 由备份目录与时间戳决定
+	Export swagexport.Options
+}
+
+// Create 在备份目录写入带时间戳的归档，并追加到索引
+func Create(opts Options) (Entry, error) {
+	dir := filepath.Clean(strings.TrimSpace(opts.Dir))
+	if strings.TrimSpace(opts.Dir) == "" {
+		return Entry{}, errors.New("备份目录为空")
+	}
+	exp := opts.Export
+	if exp.Now == nil {
+		exp.Now = time.Now
+	}
+	now := exp.Now()
+	exp.Now = func() time.Time { return now }
+
+	if exp.Format == "" {
+		exp.Format = swagexport.FormatZip
+	}
+	// 备份目录位于 swag-dir 内时（默认 <swag-dir>/backups）排除它，避免把之前的备份打包进新备份
+	if rel, ok := relInside(exp.SwagDir, dir); ok {
+		exp.ExcludeGlobs = append(append([]string(nil), exp.ExcludeGlobs...), rel+"/**")
+	}
+	name := filePrefix + now.Format(timeLayout) + exp.Format.Ext()
+	if exp.Encryption.Enabled() {
+		name += ".age"
+	}
+	exp.OutPath = filepath.Join(dir, name)
+	if _, err := os.Stat(exp.OutPath); err == nil {
+		return Entry{}, fmt.Errorf("备份文件已存在: %s", exp.OutPath)
+	}
+
+	res, err := swagexport.Export(exp)
+	if err != nil {
+		return Entry{}, err
+	}
+	st, err := os.Stat(res.OutPath)
+	if err != nil {
+		return Entry{}, fmt.Errorf("读取备份文件失败 (%s): %w", res.OutPath, err)
+	}
+
+	e := Entry{
+		File:       name,
+		CreatedAt:  now,
+		Size:       st.Size(),
+		FileCount:  res.FileCount,
+		TotalBytes: res.TotalBytes,
+		Encrypted:  exp.Encryption.Enabled(),
+		Format:     string(exp.Format),
+		Manifest:   res.Manifest,
+	}
+
+	idx, err := LoadIndex(dir)
+	if err != nil {
+		return e, err
+	}
+	idx.Backups = append(idx.Backups, e)
+	return e, SaveIndex(dir, idx)
+}
+
+// LoadIndex 读取备份目录中的索引，不存在时返回空索引
+func LoadIndex(dir string) (Index, error) {
+	idx := Index{FormatVersion: indexFormat}
+	p := filepath.Join(dir, IndexName)
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return idx, fmt.Errorf("读取备份索引失败 (%s): %w", p, err)
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return idx, nil
+	}
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return idx, fmt.Errorf("解析备份索引失败 (%s): %w", p, err)
+	}
+	return idx, nil
+}
+
+// SaveIndex 按时间排序后原子写入索引
+func SaveIndex(dir string, idx Index) error {
+	idx.FormatVersion = indexFormat
+	sortNewestFirst(idx.Backups)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建备份目录失败 (%s): %w", dir, err)
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化备份索引失败: %w", err)
+	}
+
+	p := filepath.Join(dir, IndexName)
+	tmp := p + ".tmp"
+	// 索引包含完整的 manifest，与归档一样只允许当前用户读取
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("写入备份索引失败 (%s): %w", tmp, err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入备份索引失败 (%s): %w", p, err)
+	}
+	return nil
+}
+
+// List 返回索引中的备份（按时间从新到旧），并标记已丢失的归档与目录中未记录的归档
+func List(dir string) ([]Entry, error) {
+	idx, err := LoadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(idx.Backups))
+	out := make([]Entry, 0, len(idx.Backups))
+	for _, e := range idx.Backups {
+		known[e.File] = true
+		if _, err := os.Stat(filepath.Join(dir, e.File)); err != nil {
+			e.Missing = true
+		}
+		out = append(out, e)
+	}
+
+	items, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取备份目录失败 (%s): %w", dir, err)
+	}
+	for _, it := range items {
+		if it.IsDir() || known[it.Name()] {
+			continue
+		}
+		e, ok := untrackedEntry(dir, it.Name())
+		if ok {
+			out = append(out, e)
+		}
+	}
+
+	sortNewestFirst(out)
+	return out, nil
+}
+
+// untrackedEntry 根据文件名中的时间戳为未记录的归档构造条目；未加密时读取其 manifest
+func untrackedEntry(dir, name string) (Entry, bool) {
+	ts := strings.TrimPrefix(name, filePrefix)
+	if ts == name {
+		return Entry{}, false
+	}
+	encrypted := strings.HasSuffix(ts, ".age")
+	ts = strings.TrimSuffix(ts, ".age")
+	format, ok := swagexport.FormatFromPath(ts)
+	if !ok {
+		return Entry{}, false
+	}
+	ts = strings.TrimSuffix(ts, format.Ext())
+	created, err := time.ParseInLocation(timeLayout, ts, time.Local)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	e := Entry{File: name, CreatedAt: created, Encrypted: encrypted, Format: string(format), Untracked: true}
+	p := filepath.Join(dir, name)
+	if st, err := os.Stat(p); err == nil {
+		e.Size = st.Size()
+	}
+	if !encrypted {
+		if m, err := swagexport.ReadManifest(p, swagexport.Decryption{}); err == nil {
+			e.Manifest = m
+			e.FileCount = len(m.Files)
+			for _, f := range m.Files {
+				e.TotalBytes += f.Size
+			}
+		}
+	}
+	return e, true
+}
+
+// Prune 按保留策略删除多余的备份并更新索引；dryRun 时只返回将删除的备份。
+//
+// 索引中已丢失的归档会从索引中移除；目录中未记录的归档参与保留计算并在保留时补录到索引。
+func Prune(dir string, r Retention, dryRun bool) (kept, removed []Entry, err error) {
+	entries, err := List(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var present []Entry
+	for _, e := range entries {
+		if !e.Missing {
+			present = append(present, e)
+		}
+	}
+	kept, removed = r.Apply(present)
+	if dryRun {
+		return kept, removed, nil
+	}
+
+	for _, e := range removed {
+		if err := os.Remove(filepath.Join(dir, e.File)); err != nil && !os.IsNotExist(err) {
+			return kept, removed, fmt.Errorf("删除备份失败 (%s): %w", e.File, err)
+		}
+	}
+
+	idx := Index{Backups: make([]Entry, 0, len(kept))}
+	for _, e := range kept {
+		e.Untracked = false
+		idx.Backups = append(idx.Backups, e)
+	}
+	return kept, removed, SaveIndex(dir, idx)
+}
+
+func sortNewestFirst(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+}
+
+// relInside 返回 dir 相对 base 的路径（斜杠分隔），dir 不在 base 之内时 ok 为 false
+func relInside(base, dir string) (string, bool) {
+	if strings.TrimSpace(base) == "" {
+		return "", false
+	}
+	absBase, err := filepath.Abs(filepath.Clean(strings.TrimSpace(base)))
+	if err != nil {
+		return "", false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absBase, absDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"swag-cli/internal/swagexport"
+)
+
+func TestRetentionApply(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, 3, 31, 12, 0, 0, 0, time.Local)
+	var entries []Entry
+	// 每天两个备份，共 60 天
+	for d := 0; d < 60; d++ {
+		for _, h := range []int{0, 6} {
+			entries = append(entries, Entry{File: "f", CreatedAt: base.AddDate(0, 0, -d).Add(-time.Duration(h) * time.Hour)})
+		}
+	}
+
+	kept, removed := Retention{KeepLast: 3, KeepDaily: 5}.Apply(entries)
+	// 最近 3 个覆盖 2 天，再加上 5 天中每天最新的一个（其中 2 个已被保留）
+	if len(kept) != 6 || len(kept)+len(removed) != len(entries) {
+		t.Fatalf("kept %d, removed %d", len(kept), len(removed))
+	}
+
+	// 60 天只跨越 3 月与 2 月
+	kept, _ = Retention{KeepMonthly: 3}.Apply(entries)
+	if len(kept) != 2 {
+		t.Fatalf("monthly kept = %d", len(kept))
+	}
+	for _, e := range kept {
+		if e.CreatedAt.Hour() != 12 {
+			t.Fatalf("monthly rule should keep the newest backup of each month: %v", e.CreatedAt)
+		}
+	}
+
+	kept, _ = Retention{}.Apply(entries)
+	if len(kept) != 1 || !kept[0].CreatedAt.Equal(base) {
+		t.Fatalf("empty retention should keep only the newest backup: %+v", kept)
+	}
+}
+
+func TestCreateListPrune(t *testing.T) {
+	t.Parallel()
+
+	swagDir := t.TempDir()
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services: {}\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "nginx.conf"), "worker_processes  1;\n")
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	base := time.Date(2024, 1, 10, 3, 0, 0, 0, time.Local)
+	for d := 0; d < 4; d++ {
+		now := base.AddDate(0, 0, d)
+		e, err := Create(Options{Dir: dir, Export: swagexport.Options{
+			SwagDir: swagDir,
+			Profile: swagexport.ProfileMinimal,
+			Now:     func() time.Time { return now },
+		}})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if e.FileCount != 2 || e.Manifest.Digest == "" {
+			t.Fatalf("entry = %+v", e)
+		}
+	}
+	// 归档与索引可能包含密钥，只允许当前用户读取
+	for _, name := range []string{"swag-backup.20240110-030000.zip", IndexName} {
+		if st, err := os.Stat(filepath.Join(dir, name)); err != nil || st.Mode().Perm() != 0o600 {
+			t.Fatalf("%s mode = %v, %v; want 0600", name, st.Mode().Perm(), err)
+		}
+	}
+
+	// 手动放入的归档与被删除的归档
+	if err := os.Rename(filepath.Join(dir, "swag-backup.20240110-030000.zip"), filepath.Join(dir, "swag-backup.20240101-030000.zip")); err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("List() = %+v", entries)
+	}
+	if !entries[3].Missing || !entries[4].Untracked || entries[4].FileCount != 2 {
+		t.Fatalf("missing/untracked not detected: %+v", entries[3:])
+	}
+
+	kept, removed, err := Prune(dir, Retention{KeepLast: 2}, true)
+	if err != nil {
+		t.Fatalf("Prune(dry-run) error = %v", err)
+	}
+	if len(kept) != 2 || len(removed) != 2 {
+		t.Fatalf("Prune(dry-run) kept %d removed %d", len(kept), len(removed))
+	}
+	if _, err := os.Stat(filepath.Join(dir, removed[0].File)); err != nil {
+		t.Fatalf("dry-run should not delete files: %v", err)
+	}
+
+	if _, _, err := Prune(dir, Retention{KeepLast: 2}, false); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	entries, err = List(dir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].File != "swag-backup.20240113-030000.zip" || entries[1].Missing || entries[1].Untracked {
+		t.Fatalf("after prune = %+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "swag-backup.20240101-030000.zip")); !os.IsNotExist(err) {
+		t.Fatalf("untracked old backup should be removed: %v", err)
+	}
+}
+
+func mustWrite(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file error = %v", err)
+	}
+}
+
+func TestCreateExcludesBackupDirInsideSwagDir(t *testing.T) {
+	t.Parallel()
+
+	swagDir := t.TempDir()
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services: {}\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "nginx.conf"), "worker_processes  1;\n")
+	dir := filepath.Join(swagDir, "backups")
+
+	base := time.Date(2024, 1, 10, 3, 0, 0, 0, time.Local)
+	for d := 0; d < 2; d++ {
+		now := base.AddDate(0, 0, d)
+		e, err := Create(Options{Dir: dir, Export: swagexport.Options{
+			SwagDir:      swagDir,
+			Profile:      swagexport.ProfileMinimal,
+			IncludeGlobs: []string{"**"},
+			Now:          func() time.Time { return now },
+		}})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		for _, f := range e.Manifest.Files {
+			if strings.HasPrefix(f.Path, "backups/") {
+				t.Fatalf("backup %d contains %s from the backup directory", d, f.Path)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Retention 为备份保留策略，各规则取并集；全部为 0 时只保留最新的一个备份
+type Retention struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+// String 返回保留策略的简短描述
+func (r Retention) String() string {
+	var parts []string
+	if r.KeepLast > 0 {
+		parts = append(parts, fmt.Sprintf("最近 %d 个", r.KeepLast))
+	}
+	if r.KeepDaily > 0 {
+		parts = append(parts, fmt.Sprintf("%d 天", r.KeepDaily))
+	}
+	if r.KeepWeekly > 0 {
+		parts = append(parts, fmt.Sprintf("%d 周", r.KeepWeekly))
+	}
+	if r.KeepMonthly > 0 {
+		parts = append(parts, fmt.Sprintf("%d 月", r.KeepMonthly))
+	}
+	if len(parts) == 0 {
+		return "仅保留最新 1 个"
+	}
+	return strings.Join(parts, "，")
+}
+
+// Apply 将备份分为保留与删除两组（均按时间从新到旧）。
+//
+// 按天/周/月的规则在每个时间段内保留最新的备份，并计入最近 N 个有备份的时间段（按本地时间划分，周为 ISO 周）。
+func (r Retention) Apply(entries []Entry) (kept, removed []Entry) {
+	sorted := append([]Entry(nil), entries...)
+	sortNewestFirst(sorted)
+
+	keep := make([]bool, len(sorted))
+	for i := 0; i < len(sorted) && i < r.KeepLast; i++ {
+		keep[i] = true
+	}
+	keepPerPeriod(sorted, keep, r.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") })
+	keepPerPeriod(sorted, keep, r.KeepWeekly, func(t time.Time) string {
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	})
+	keepPerPeriod(sorted, keep, r.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+	if len(sorted) > 0 {
+		keep[0] = true
+	}
+
+	for i, e := range sorted {
+		if keep[i] {
+			kept = append(kept, e)
+		} else {
+			removed = append(removed, e)
+		}
+	}
+	return kept, removed
+}
+
+func keepPerPeriod(sorted []Entry, keep []bool, n int, period func(time.Time) string) {
+	seen := make(map[string]bool)
+	for i, e := range sorted {
+		if len(seen) >= n {
+			return
+		}
+		p := period(e.CreatedAt.Local())
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		keep[i] = true
+	}
+}
@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"context"
 	"os"
 	"swag-cli/internal/config"
-	"swag-cli/internal/docker"
 	"swag-cli/internal/nginx"
 
 	"github.com/fatih/color"
@@ -26,6 +24,7 @@ var addCmd = &cobra.Command{
 		subdomain, _ := cmd.Flags().GetString("subdomain")
 		port, _ := cmd.Flags().GetInt("port")
 		proto, _ := cmd.Flags().GetString("proto")
+		siteLogs, _ := cmd.Flags().GetBool("site-log")
 		swagDir, _ := cmd.Flags().GetString("swag-dir") // Inherited from root
 
 		// 简单的校验 (实际场景可能需要更复杂的交互逻辑如果缺少参数)
@@ -45,6 +44,7 @@ var addCmd = &cobra.Command{
 			ContainerName: containerName,
 			ContainerPort: port,
 			Protocol:      proto,
+			SiteLogs:      siteLogs,
 		}
 
 		// 3. 生成配置
@@ -59,20 +59,13 @@ var addCmd = &cobra.Command{
 
 		color.Green("成功生成配置文件: %s", path)
 
-		// 触发 Nginx reload
-		swagContainer, _ := cmd.Flags().GetString("swag-container")
-		client, err := docker.NewClient()
-		if err == nil {
-			color.Yellow("正在重载 SWAG (%s) Nginx...", swagContainer)
-			if err := client.ReloadNginx(context.Background(), swagContainer); err != nil {
-				color.Red("Nginx 重载失败: %v", err)
-				// 不退出，因为配置已生成
-			} else {
-				color.Green("Nginx 重载成功！站点应已生效。")
-			}
-		} else {
-			color.Yellow("无法连接 Docker，跳过 Nginx 重载: %v", err)
+		// 检查证书覆盖；若已重建容器则无需再 reload
+		if ensureCertCoverage(cmd, subdomain) {
+			return
 		}
+
+		// 应用配置（失败时不退出，因为配置已生成）
+		applySwagChanges(cmd)
 	},
 }
 
@@ -80,6 +73,8 @@ func init() {
 	addCmd.Flags().StringP("subdomain", "s", "", "子域名 (默认为容器名)")
 	addCmd.Flags().IntP("port", "p", 80, "容器内部端口")
 	addCmd.Flags().String("proto", "http", "协议 (http/https)")
+	addCmd.Flags().Bool("site-log", false, "为站点写入单独的 access_log/error_log（log/nginx/<subdomain>.access.log），便于 swag-cli logs 查看")
+	addCmd.Flags().Bool("update-subdomains", false, "证书未覆盖该子域名时，自动更新 compose.yaml 的 SUBDOMAINS 并重建 SWAG 容器")
 
 	rootCmd.AddCommand(addCmd)
 }
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"swag-cli/internal/apply"
+	"swag-cli/internal/docker"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// applySwagChanges 按 --reload-strategy（--no-reload 等同 none）让 SWAG 应用配置变更，返回是否成功
+func applySwagChanges(cmd *cobra.Command) bool {
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+	strategyName, _ := cmd.Flags().GetString("reload-strategy")
+	noReload, _ := cmd.Flags().GetBool("no-reload")
+	timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+
+	strategy, err := apply.ParseStrategy(strategyName)
+	if err != nil {
+		color.Red("%v", err)
+		return false
+	}
+	if noReload {
+		strategy = apply.StrategyNone
+	}
+
+	svc := apply.Service{Container: swagContainer, Strategy: strategy, WaitTimeout: timeout, LogWriter: os.Stderr}
+	if strategy != apply.StrategyNone {
+		client, err := docker.NewClient()
+		if err != nil {
+			color.Yellow("无法连接 Docker，跳过应用配置: %v", err)
+			return false
+		}
+		defer client.Close()
+		svc.Docker = client
+	}
+
+	if err := svc.Apply(context.Background()); err != nil {
+		color.Red("应用配置失败: %v", err)
+		return false
+	}
+	return true
+}
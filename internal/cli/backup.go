@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"swag-cli/internal/backup"
+	"swag-cli/internal/config"
+	"swag-cli/internal/swagexport"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var swagBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "在备份目录创建带时间戳的归档，并按保留策略清理旧备份",
+	Long: `在备份目录（config: backup-dir，默认 <swag-dir>/backups）写入 swag-backup.<时间>.zip，
+记录到目录下的 index.json，然后按保留策略（config: backup-keep-last/daily/weekly/monthly）删除多余的备份。
+适合放入 cron 定期执行。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		profileStr, _ := cmd.Flags().GetString("profile")
+		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+		proxyConfOnly, _ := cmd.Flags().GetBool("proxy-conf-only")
+		excludeGlobs, _ := cmd.Flags().GetStringArray("exclude-glob")
+		includeGlobs, _ := cmd.Flags().GetStringArray("include-glob")
+		noPrune, _ := cmd.Flags().GetBool("no-prune")
+		formatStr, _ := cmd.Flags().GetString("format")
+
+		format, err := swagexport.ParseArchiveFormat(formatStr)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		profile, custom := exportProfile(profileStr)
+
+		cfg := backupConfig(cmd)
+		dir := backupDir(cmd, cfg)
+
+		enc, err := exportEncryption(cmd)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if profile == swagexport.ProfileFull && includeSecrets && !enc.Enabled() {
+			color.Yellow("警告: 备份将以明文包含 DNS API token、私钥等敏感内容，建议使用 --encrypt 或 --recipient 加密")
+		}
+
+		e, err := backup.Create(backup.Options{
+			Dir: dir,
+			Export: swagexport.Options{
+				SwagDir:        cfg.BaseDir(),
+				Profile:        profile,
+				IncludeSecrets: includeSecrets,
+				ProxyConfOnly:  proxyConfOnly,
+				ExcludeGlobs:   excludeGlobs,
+				IncludeGlobs:   includeGlobs,
+				Custom:         custom,
+				Version:        cmd.Root().Version,
+				Encryption:     enc,
+				Format:         format,
+				Container:      swagContainerMetadata(cmd),
+			},
+		})
+		if err != nil {
+			color.Red("备份失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("备份完成: %s (%d 个文件, %s)", e.File, e.FileCount, formatSize(e.Size))
+
+		if noPrune {
+			return
+		}
+		pruneBackups(dir, backupRetention(cmd, cfg), false)
+	},
+}
+
+var swagBackupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出备份目录中的备份",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := backupDir(cmd, backupConfig(cmd))
+		entries, err := backup.List(dir)
+		if err != nil {
+			color.Red("读取备份失败: %v", err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			color.Yellow("备份目录中没有备份: %s", dir)
+			return
+		}
+
+		color.Cyan("备份目录: %s", dir)
+		fmt.Printf("%-40s %-20s %-9s %6s %10s  %s\n", "FILE", "CREATED", "PROFILE", "FILES", "SIZE", "NOTE")
+		for _, e := range entries {
+			var notes []string
+			if e.Encrypted {
+				notes = append(notes, "加密")
+			}
+			if e.Manifest.IncludeSecrets {
+				notes = append(notes, "含敏感内容")
+			}
+			if e.Untracked {
+				notes = append(notes, "未记录在索引中")
+			}
+			line := fmt.Sprintf("%-40s %-20s %-9s %6d %10s  %s", e.File, e.CreatedAt.Local().Format("2006-01-02 15:04:05"), e.Manifest.Profile, e.FileCount, formatSize(e.Size), strings.Join(notes, ", "))
+			if e.Missing {
+				color.Red("%s 文件已丢失", line)
+				continue
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+var swagBackupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "按保留策略删除多余的备份",
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		cfg := backupConfig(cmd)
+		pruneBackups(backupDir(cmd, cfg), backupRetention(cmd, cfg), dryRun)
+	},
+}
+
+func pruneBackups(dir string, r backup.Retention, dryRun bool) {
+	kept, removed, err := backup.Prune(dir, r, dryRun)
+	if err != nil {
+		color.Red("清理备份失败: %v", err)
+		os.Exit(1)
+	}
+	if len(removed) == 0 {
+		color.Cyan("保留策略（%s）: 无需删除，共 %d 个备份", r, len(kept))
+		return
+	}
+
+	verb := "已删除"
+	if dryRun {
+		verb = "将删除"
+	}
+	color.Yellow("保留策略（%s）: %s %d 个备份，保留 %d 个", r, verb, len(removed), len(kept))
+	for _, e := range removed {
+		fmt.Printf("  - %s\n", e.File)
+	}
+}
+
+// backupConfig 读取配置文件，并以命令行的 --swag-dir 为准
+func backupConfig(cmd *cobra.Command) config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		color.Yellow("读取配置失败，使用默认保留策略: %v", err)
+	}
+	if v, _ := cmd.Flags().GetString("swag-dir"); strings.TrimSpace(v) != "" {
+		cfg.SwagDir = v
+	}
+	return cfg
+}
+
+func backupDir(cmd *cobra.Command, cfg config.Config) string {
+	if v, _ := cmd.Flags().GetString("dir"); strings.TrimSpace(v) != "" {
+		cfg.BackupDir = v
+	}
+	return cfg.BackupDirPath()
+}
+
+// backupRetention 以配置中的保留策略为基础，命令行显式指定的 --keep-* 优先
+func backupRetention(cmd *cobra.Command, cfg config.Config) backup.Retention {
+	r := backup.Retention{
+		KeepLast:    cfg.BackupKeepLast,
+		KeepDaily:   cfg.BackupKeepDaily,
+		KeepWeekly:  cfg.BackupKeepWeekly,
+		KeepMonthly: cfg.BackupKeepMonthly,
+	}
+	for name, field := range map[string]*int{
+		"keep-last":    &r.KeepLast,
+		"keep-daily":   &r.KeepDaily,
+		"keep-weekly":  &r.KeepWeekly,
+		"keep-monthly": &r.KeepMonthly,
+	} {
+		if cmd.Flags().Changed(name) {
+			*field, _ = cmd.Flags().GetInt(name)
+			*field = max(*field, 0)
+		}
+	}
+	return r
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func init() {
+	swagBackupCmd.PersistentFlags().String("dir", "", "备份目录（默认使用配置 backup-dir，未配置时为 <swag-dir>/backups）")
+
+	swagBackupCmd.Flags().String("profile", string(swagexport.ProfileStandard), "备份档位: minimal|standard|full，或自定义档位")
+	swagBackupCmd.Flags().Bool("include-secrets", false, "在 full 档位下包含 dns-conf/keys/letsencrypt 等敏感内容")
+	swagBackupCmd.Flags().Bool("proxy-conf-only", true, "proxy-confs 仅备份 .conf/.conf.disabled，排除 sample/example（与 swag export 一致）")
+	swagBackupCmd.Flags().StringArray("exclude-glob", nil, "额外排除的路径（相对 swag-dir，支持 ** 通配），可重复指定")
+	swagBackupCmd.Flags().StringArray("include-glob", nil, "额外备份的路径（相对 swag-dir，支持 ** 通配），可重复指定")
+	swagBackupCmd.Flags().String("format", string(swagexport.FormatZip), "归档格式: zip|tar.gz|tar.zst")
+	swagBackupCmd.Flags().Bool("no-prune", false, "只创建备份，不按保留策略清理")
+	swagBackupCmd.Flags().Bool("no-container", false, "不在 manifest 中记录 SWAG 容器的运行参数")
+	swagBackupCmd.Flags().Bool("encrypt", false, "使用 passphrase 加密备份（age/scrypt）")
+	swagBackupCmd.Flags().StringArray("recipient", nil, "使用 age 公钥 (age1...) 加密备份，可重复指定")
+	swagBackupCmd.Flags().StringArray("recipients-file", nil, "从文件读取 age 公钥（每行一个）")
+	swagBackupCmd.Flags().String("passphrase-file", "", "从文件读取 passphrase（也可使用环境变量 SWAG_CLI_PASSPHRASE）")
+
+	for _, c := range []*cobra.Command{swagBackupCmd, swagBackupPruneCmd} {
+		c.Flags().Int("keep-last", 0, "保留最近 N 个备份（默认使用配置 backup-keep-last）")
+		c.Flags().Int("keep-daily", 0, "保留最近 N 天每天最新的备份（默认使用配置 backup-keep-daily）")
+		c.Flags().Int("keep-weekly", 0, "保留最近 N 周每周最新的备份（默认使用配置 backup-keep-weekly）")
+		c.Flags().Int("keep-monthly", 0, "保留最近 N 月每月最新的备份（默认使用配置 backup-keep-monthly）")
+	}
+	swagBackupPruneCmd.Flags().Bool("dry-run", false, "只列出将删除的备份，不实际删除")
+
+	swagBackupCmd.AddCommand(swagBackupListCmd)
+	swagBackupCmd.AddCommand(swagBackupPruneCmd)
+	swagCmd.AddCommand(swagBackupCmd)
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"swag-cli/internal/apply"
+	"swag-cli/internal/config"
+	"swag-cli/internal/docker"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ensureCertCoverage 检查新站点是否被 SWAG 证书覆盖，未覆盖时提示用户更新 compose.yaml 并重建 SWAG 容器。
+//
+// 返回 true 表示已重建容器，调用方无需再 reload；处理失败时输出错误并退出。
+func ensureCertCoverage(cmd *cobra.Command, subdomain string) bool {
+	swagDir, _ := cmd.Flags().GetString("swag-dir")
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+	autoYes, _ := cmd.Flags().GetBool("update-subdomains")
+	waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+
+	client, err := docker.NewClient()
+	if err != nil {
+		return false
+	}
+	defer client.Close()
+
+	c := apply.CertCoverage{
+		Docker:      client,
+		Container:   swagContainer,
+		ComposePath: config.Config{SwagDir: swagDir}.ComposePath(),
+		WaitTimeout: waitTimeout,
+		Confirm:     confirm,
+		LogWriter:   os.Stderr,
+	}
+	if autoYes {
+		c.Confirm = nil
+	}
+	recreated, err := c.Ensure(context.Background(), subdomain)
+	if err != nil {
+		color.Red("证书覆盖处理失败: %v", err)
+		os.Exit(1)
+	}
+	return recreated
+}
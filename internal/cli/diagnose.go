@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/diagnose"
+	"swag-cli/internal/docker"
+	"swag-cli/internal/nginx"
+	"swag-cli/internal/nginxlog"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose <site>",
+	Short: "诊断站点的上游错误（502 等）",
+	Long: `扫描 nginx error log 中与站点上游（$upstream_app）相关的错误，按类型汇总
+（连接被拒绝、无法解析主机名、上游超时、SSL 握手失败、连接中断），结合上游容器的状态与网络给出排查建议。
+站点未配置单独 error_log 时读取共享的 error.log（含轮转文件），按上游地址与 server_name 归属到站点。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		swagContainer, _ := cmd.Flags().GetString("swag-container")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		site := args[0]
+
+		since, err := nginxlog.ParseTimeBound(sinceStr, time.Now())
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		cfg := config.Config{SwagDir: swagDir}
+		s, sc, err := findSite(cfg, site)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		target := diagnose.Target{
+			Site:        site,
+			ServerNames: sc.ServerNames,
+			App:         s.TargetDest,
+			Port:        s.ContainerPort,
+		}
+		if s.TargetType == nginx.TargetContainer {
+			target.Container = s.ContainerName
+		} else if s.TargetType != nginx.TargetIP {
+			target.App = ""
+		}
+		opts := diagnose.Options{
+			Target:        target,
+			Files:         diagnoseLogFiles(cfg, site, sc),
+			Since:         since,
+			SwagContainer: strings.TrimSpace(swagContainer),
+		}
+		if target.Container != "" {
+			client, err := docker.NewClient()
+			if err != nil {
+				printWarning("警告: 无法连接 Docker，跳过容器检查: %v", err)
+			} else {
+				opts.Docker = client
+			}
+		}
+
+		rep, err := diagnose.Run(context.Background(), opts)
+		if err != nil {
+			color.Red("诊断失败: %v", err)
+			os.Exit(1)
+		}
+		if printJSONIfRequested(cmd, rep) {
+			return
+		}
+		printDiagnoseReport(rep, sinceStr)
+	},
+}
+
+// diagnoseLogFiles 返回站点的 error log 及其轮转文件（主机路径）
+func diagnoseLogFiles(cfg config.Config, site string, sc nginxlog.SiteConf) []nginxlog.LogFile {
+	paths, shared := sc.LogFiles(true)
+	owner := site
+	if shared {
+		owner = ""
+	}
+	var files []nginxlog.LogFile
+	for _, p := range paths {
+		hostPath := nginxlog.HostPath(cfg.BaseDir(), p)
+		rotated, _ := filepath.Glob(hostPath + ".*")
+		sort.Strings(rotated)
+		for _, fp := range append([]string{hostPath}, rotated...) {
+			files = append(files, nginxlog.LogFile{Path: fp, Site: owner})
+		}
+	}
+	return files
+}
+
+func printDiagnoseReport(rep diagnose.Report, since string) {
+	if rep.Upstream != "" {
+		color.Cyan("站点 %s，上游 %s", rep.Site, rep.Upstream)
+	} else {
+		color.Cyan("站点 %s（未设置 $upstream_app，只检查 error log）", rep.Site)
+	}
+
+	if c := rep.Container; c != nil {
+		state := c.Status
+		if c.Health != "" {
+			state += " (" + c.Health + ")"
+		}
+		switch {
+		case !c.Found:
+			color.Red("容器 %s: 不存在", c.Name)
+		case c.Running && c.Health != "unhealthy":
+			color.Green("容器 %s: %s，网络 %s", c.Name, state, strings.Join(c.Networks, ","))
+		default:
+			color.Red("容器 %s: %s，重启 %d 次", c.Name, state, c.RestartCount)
+		}
+	}
+
+	window := "全部日志中"
+	if since != "" {
+		window = "最近 " + since + " 内"
+	}
+	fmt.Println()
+	if len(rep.Files) == 0 {
+		color.Yellow("没有可读取的 error log")
+	} else if len(rep.Issues) == 0 {
+		color.Green("%s没有与该站点上游相关的错误（已读取 %d 个日志文件）", window, len(rep.Files))
+	} else {
+		color.Cyan("%s的上游错误（已读取 %d 个日志文件）:", window, len(rep.Files))
+		for _, is := range rep.Issues {
+			fmt.Printf("  %-20s %6d 次  %s ~ %s\n", is.Kind.Description(), is.Count,
+				is.First.Format("01-02 15:04"), is.Last.Format("01-02 15:04"))
+			fmt.Printf("    %s\n", is.Sample)
+		}
+	}
+
+	if len(rep.Hints) > 0 {
+		fmt.Println()
+		color.Yellow("建议:")
+		for _, h := range rep.Hints {
+			fmt.Printf("  - %s\n", h)
+		}
+	}
+}
+
+func init() {
+	diagnoseCmd.Flags().String("since", "24h", "只检查该时间之后的错误：时长（30m、24h、7d）或时间（2026-01-02 15:04）；为空时检查全部")
+	diagnoseCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+
+	rootCmd.AddCommand(diagnoseCmd)
+}
@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/docker"
+	"swag-cli/internal/fail2ban"
+	"swag-cli/internal/nginx"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var fail2banCmd = &cobra.Command{
+	Use:   "fail2ban",
+	Short: "查看 SWAG 内置 fail2ban 的 jail 状态，封禁/解封 IP",
+	Long:  "在 SWAG 容器内执行 fail2ban-client（通过 docker exec），需要 SWAG 容器正在运行。",
+}
+
+var fail2banStatusCmd = &cobra.Command{
+	Use:   "status [jail]",
+	Short: "显示所有 jail 的失败与封禁统计，或单个 jail 的详情",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := fail2banClient(cmd)
+		ctx := context.Background()
+
+		if len(args) == 1 {
+			js, err := client.Jail(ctx, args[0])
+			if err != nil {
+				color.Red("读取 jail 状态失败: %v", err)
+				os.Exit(1)
+			}
+			if printJSONIfRequested(cmd, js) {
+				return
+			}
+			color.Cyan("jail: %s", js.Name)
+			fmt.Printf("  当前失败: %d (累计 %d)\n", js.CurrentlyFailed, js.TotalFailed)
+			fmt.Printf("  当前封禁: %d (累计 %d)\n", js.CurrentlyBanned, js.TotalBanned)
+			if len(js.Files) > 0 {
+				fmt.Printf("  日志文件: %s\n", strings.Join(js.Files, ", "))
+			}
+			for _, ip := range js.BannedIPs {
+				fmt.Printf("  - %s\n", ip)
+			}
+			return
+		}
+
+		jails, err := client.Jails(ctx)
+		if err != nil {
+			color.Red("读取 fail2ban 状态失败: %v", err)
+			os.Exit(1)
+		}
+		if printJSONIfRequested(cmd, jails) {
+			return
+		}
+		if len(jails) == 0 {
+			color.Yellow("未启用任何 jail")
+			return
+		}
+		fmt.Printf("%-28s %8s %8s %8s %8s\n", "JAIL", "FAILED", "TOTAL", "BANNED", "TOTAL")
+		for _, js := range jails {
+			fmt.Printf("%-28s %8d %8d %8d %8d\n", js.Name, js.CurrentlyFailed, js.TotalFailed, js.CurrentlyBanned, js.TotalBanned)
+		}
+	},
+}
+
+var fail2banJailsCmd = &cobra.Command{
+	Use:   "jails",
+	Short: "列出已启用的 jail",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		st, err := fail2banClient(cmd).Status(context.Background())
+		if err != nil {
+			color.Red("读取 fail2ban 状态失败: %v", err)
+			os.Exit(1)
+		}
+		if printJSONIfRequested(cmd, st.Jails) {
+			return
+		}
+		if len(st.Jails) == 0 {
+			color.Yellow("未启用任何 jail")
+			return
+		}
+		for _, j := range st.Jails {
+			fmt.Println(j)
+		}
+	},
+}
+
+var fail2banBannedCmd = &cobra.Command{
+	Use:   "banned",
+	Short: "列出当前被封禁的 IP（按 jail 分组）",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jail, _ := cmd.Flags().GetString("jail")
+		client := fail2banClient(cmd)
+		ctx := context.Background()
+
+		var jails []fail2ban.JailStatus
+		if strings.TrimSpace(jail) != "" {
+			js, err := client.Jail(ctx, jail)
+			if err != nil {
+				color.Red("读取 jail 状态失败: %v", err)
+				os.Exit(1)
+			}
+			jails = []fail2ban.JailStatus{js}
+		} else {
+			all, err := client.Jails(ctx)
+			if err != nil {
+				color.Red("读取 fail2ban 状态失败: %v", err)
+				os.Exit(1)
+			}
+			jails = all
+		}
+
+		banned := make(map[string][]string, len(jails))
+		for _, js := range jails {
+			banned[js.Name] = js.BannedIPs
+		}
+		if printJSONIfRequested(cmd, banned) {
+			return
+		}
+
+		total := 0
+		for _, js := range jails {
+			if len(js.BannedIPs) == 0 {
+				continue
+			}
+			color.Cyan("%s (%d):", js.Name, len(js.BannedIPs))
+			for _, ip := range js.BannedIPs {
+				fmt.Printf("  %s\n", ip)
+			}
+			total += len(js.BannedIPs)
+		}
+		if total == 0 {
+			color.Green("当前没有被封禁的 IP")
+		}
+	},
+}
+
+var fail2banUnbanCmd = &cobra.Command{
+	Use:   "unban <ip>",
+	Short: "解封 IP（默认从所有 jail 解封）",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jail, _ := cmd.Flags().GetString("jail")
+		ip := strings.TrimSpace(args[0])
+
+		jails, err := fail2banClient(cmd).Unban(context.Background(), jail, ip)
+		for _, j := range jails {
+			color.Green("已解封: %s (jail: %s)", ip, j)
+		}
+		if err != nil {
+			color.Red("解封失败: %v", err)
+			os.Exit(1)
+		}
+		if len(jails) == 0 {
+			color.Yellow("%s 当前未被封禁", ip)
+		}
+	},
+}
+
+var fail2banBanCmd = &cobra.Command{
+	Use:   "ban <ip>",
+	Short: "在指定 jail 中封禁 IP",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jail, _ := cmd.Flags().GetString("jail")
+		ip := strings.TrimSpace(args[0])
+
+		if err := fail2banClient(cmd).Ban(context.Background(), jail, ip); err != nil {
+			color.Red("封禁失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("已封禁: %s (jail: %s)", ip, jail)
+	},
+}
+
+var fail2banJailCmd = &cobra.Command{
+	Use:   "jail",
+	Short: "为被代理的应用添加 fail2ban jail（防暴力破解）",
+}
+
+var fail2banJailAddCmd = &cobra.Command{
+	Use:   "add <site>",
+	Short: "按内置过滤规则为站点添加 jail",
+	Long: `在 fail2ban 目录写入 filter.d/<site>.local，并在 jail.local 中添加 [<site>] jail，日志文件取站点配置中的 access_log（默认 /config/log/nginx/access.log）。
+写入前会在 SWAG 容器内用 fail2ban-regex 测试过滤规则对现有日志的匹配情况；写入后复制到容器并执行 fail2ban-client reload。
+可用的过滤规则见 swag-cli fail2ban jail presets。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		presetName, _ := cmd.Flags().GetString("filter")
+		logPaths, _ := cmd.Flags().GetStringArray("logpath")
+		maxRetry, _ := cmd.Flags().GetInt("maxretry")
+		findTime, _ := cmd.Flags().GetDuration("findtime")
+		banTime, _ := cmd.Flags().GetDuration("bantime")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noTest, _ := cmd.Flags().GetBool("no-test")
+		noReload, _ := cmd.Flags().GetBool("no-reload")
+		site := args[0]
+
+		preset, err := fail2ban.LookupPreset(presetName)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		name, err := fail2ban.JailName(site)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		cfg := config.Config{SwagDir: swagDir}
+		if len(logPaths) == 0 {
+			logPaths = siteLogPaths(cfg, site)
+		}
+		spec := fail2ban.JailSpec{Name: name, Preset: preset, LogPaths: logPaths, MaxRetry: maxRetry, FindTime: findTime, BanTime: banTime}
+
+		// 只测试/写入文件时（--no-test 且 --dry-run 或 --no-reload）不需要连接 Docker
+		var client fail2ban.Client
+		if !noTest || (!dryRun && !noReload) {
+			client = fail2banClient(cmd)
+		}
+		ctx := context.Background()
+		if !noTest {
+			for _, lp := range logPaths {
+				res, err := client.TestFilter(ctx, lp, preset)
+				if err != nil {
+					color.Yellow("fail2ban-regex 测试失败: %v", err)
+					continue
+				}
+				color.Cyan("fail2ban-regex %s: %d 行中匹配 %d 行", res.LogPath, res.Lines, res.Matched)
+			}
+		}
+
+		if dryRun {
+			color.Cyan("将写入 %s:", filepath.Join(cfg.Fail2banDir(), "filter.d", name+".local"))
+			fmt.Print(fail2ban.FilterContent(spec))
+			color.Cyan("将写入 %s:", filepath.Join(cfg.Fail2banDir(), "jail.local"))
+			fmt.Print(fail2ban.JailContent(spec))
+			return
+		}
+
+		files, err := fail2ban.WriteJail(cfg.Fail2banDir(), spec)
+		if err != nil {
+			color.Red("添加 jail 失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("已写入: %s", files.FilterPath)
+		if files.Replaced {
+			color.Green("已更新: %s ([%s])", files.JailPath, name)
+		} else {
+			color.Green("已写入: %s ([%s])", files.JailPath, name)
+		}
+
+		if noReload {
+			color.Yellow("已跳过 fail2ban reload，重启 SWAG 容器后生效")
+			return
+		}
+		if err := client.ApplyJail(ctx, name); err != nil {
+			color.Red("重新加载 fail2ban 失败: %v", err)
+			color.Yellow("配置已写入，重启 SWAG 容器后生效")
+			os.Exit(1)
+		}
+		color.Green("fail2ban 已重新加载，jail %s 已启用", name)
+	},
+}
+
+var fail2banJailPresetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "列出内置的过滤规则",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, p := range fail2ban.Presets {
+			fmt.Printf("  %-12s %s\n", p.Name, p.Description)
+		}
+	},
+}
+
+// siteLogPaths 返回站点配置中的 access_log；找不到站点配置时使用 SWAG 默认日志
+func siteLogPaths(cfg config.Config, site string) []string {
+	sites, err := nginx.NewManager(cfg.ProxyConfsDir()).ListSites()
+	if err == nil {
+		for _, s := range sites {
+			if s.Name != site {
+				continue
+			}
+			paths, err := fail2ban.SiteLogPaths(filepath.Join(cfg.ProxyConfsDir(), s.Filename))
+			if err == nil {
+				return paths
+			}
+			color.Yellow("%v", err)
+		}
+	}
+	color.Yellow("未找到站点 %s 的代理配置，使用默认日志 %s", site, fail2ban.DefaultLogPath)
+	return []string{fail2ban.DefaultLogPath}
+}
+
+// fail2banClient 创建在 SWAG 容器内执行 fail2ban-client 的客户端
+func fail2banClient(cmd *cobra.Command) fail2ban.Client {
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+	if strings.TrimSpace(swagContainer) == "" {
+		color.Red("未指定 SWAG 容器名称，请使用 --swag-container 标志或检查配置文件")
+		os.Exit(1)
+	}
+	client, err := docker.NewClient()
+	if err != nil {
+		color.Red("连接 Docker 失败: %v", err)
+		os.Exit(1)
+	}
+	return fail2ban.Client{Docker: client, Container: swagContainer}
+}
+
+// printJSONIfRequested 在指定 --json 时以 JSON 输出 v 并返回 true
+func printJSONIfRequested(cmd *cobra.Command, v any) bool {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if !asJSON {
+		return false
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		color.Red("序列化失败: %v", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(b))
+	return true
+}
+
+// printWarning 以黄色输出警告到 stderr，避免混入 --json 的输出
+func printWarning(format string, args ...any) {
+	fmt.Fprintln(os.Stderr, color.YellowString(format, args...))
+}
+
+func init() {
+	for _, c := range []*cobra.Command{fail2banStatusCmd, fail2banJailsCmd, fail2banBannedCmd} {
+		c.Flags().Bool("json", false, "以 JSON 格式输出")
+	}
+	fail2banBannedCmd.Flags().String("jail", "", "只列出指定 jail")
+	fail2banUnbanCmd.Flags().String("jail", "", "只从指定 jail 解封")
+	fail2banBanCmd.Flags().String("jail", "", "封禁所用的 jail（必填）")
+	_ = fail2banBanCmd.MarkFlagRequired("jail")
+
+	fail2banJailAddCmd.Flags().String("filter", "", "过滤规则（见 swag-cli fail2ban jail presets）")
+	_ = fail2banJailAddCmd.MarkFlagRequired("filter")
+	fail2banJailAddCmd.Flags().StringArray("logpath", nil, "日志文件（容器内路径），可重复指定；默认取站点配置的 access_log")
+	fail2banJailAddCmd.Flags().Int("maxretry", 5, "findtime 内失败多少次后封禁")
+	fail2banJailAddCmd.Flags().Duration("findtime", 10*time.Minute, "统计失败次数的时间窗口")
+	fail2banJailAddCmd.Flags().Duration("bantime", time.Hour, "封禁时长")
+	fail2banJailAddCmd.Flags().Bool("dry-run", false, "只测试过滤规则并显示将写入的内容，不写入文件")
+	fail2banJailAddCmd.Flags().Bool("no-test", false, "跳过 fail2ban-regex 测试")
+	fail2banJailCmd.AddCommand(fail2banJailAddCmd)
+	fail2banJailCmd.AddCommand(fail2banJailPresetsCmd)
+	fail2banCmd.AddCommand(fail2banJailCmd)
+
+	fail2banCmd.AddCommand(fail2banStatusCmd)
+	fail2banCmd.AddCommand(fail2banJailsCmd)
+	fail2banCmd.AddCommand(fail2banBannedCmd)
+	fail2banCmd.AddCommand(fail2banUnbanCmd)
+	fail2banCmd.AddCommand(fail2banBanCmd)
+	rootCmd.AddCommand(fail2banCmd)
+}
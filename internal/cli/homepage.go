@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"context"
 	"os"
 	"swag-cli/internal/config"
-	"swag-cli/internal/docker"
 	"swag-cli/internal/nginx"
 
 	"github.com/fatih/color"
@@ -66,7 +64,7 @@ var homepageSetCmd = &cobra.Command{
 		}
 		color.Green("已更新: %s", defaultPath)
 
-		reloadSwagNginx(cmd)
+		applySwagChanges(cmd)
 	},
 }
 
@@ -109,26 +107,10 @@ var homepageClearCmd = &cobra.Command{
 		}
 		color.Green("已更新: %s", defaultPath)
 
-		reloadSwagNginx(cmd)
+		applySwagChanges(cmd)
 	},
 }
 
-func reloadSwagNginx(cmd *cobra.Command) {
-	swagContainer, _ := cmd.Flags().GetString("swag-container")
-	client, err := docker.NewClient()
-	if err != nil {
-		color.Yellow("无法连接 Docker，跳过 Nginx reload: %v", err)
-		return
-	}
-	color.Yellow("正在重载 SWAG (%s) Nginx...", swagContainer)
-	if err := client.ReloadNginx(context.Background(), swagContainer); err != nil {
-		color.Red("Nginx 重载失败: %v", err)
-		color.Yellow("可尝试执行: swag-cli reload (重启容器) 以应用配置。")
-		return
-	}
-	color.Green("Nginx 重载成功！")
-}
-
 func init() {
 	homepageSetCmd.Flags().String("domain", "", "根域名 (例如 example.com)")
 	homepageSetCmd.Flags().IntP("port", "p", 80, "容器内部端口")
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/nginx"
+	"swag-cli/internal/nginxlog"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <site>",
+	Short: "查看站点的 nginx 日志（-f 持续跟踪）",
+	Long: `读取站点配置中的 access_log/error_log；站点未配置单独日志时，按 server_name 过滤 SWAG 的共享日志
+（共享日志需要在 log_format 中包含 $host 才能区分站点，建议使用 swag-cli add --site-log 为站点写入单独日志）。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		follow, _ := cmd.Flags().GetBool("follow")
+		lines, _ := cmd.Flags().GetInt("lines")
+		errorLog, _ := cmd.Flags().GetBool("error")
+		statusStr, _ := cmd.Flags().GetString("status")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+		site := args[0]
+
+		cfg := config.Config{SwagDir: swagDir}
+		sc, err := findSiteConf(cfg, site)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		var filter nginxlog.Filter
+		now := time.Now()
+		if filter.Status, err = nginxlog.ParseStatusFilter(statusStr); err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if filter.Since, err = nginxlog.ParseTimeBound(sinceStr, now); err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if filter.Until, err = nginxlog.ParseTimeBound(untilStr, now); err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if errorLog && filter.Status != nil {
+			color.Yellow("提示: error log 没有状态码，已忽略 --status")
+			filter.Status = nil
+		}
+
+		paths, shared := sc.LogFiles(errorLog)
+		if shared {
+			filter.ServerNames = sc.ServerNames
+			color.Yellow("站点 %s 未配置单独日志，按 server_name (%v) 过滤共享日志", site, sc.ServerNames)
+		}
+
+		var mu sync.Mutex
+		withHost := 0
+		keep := func(line string) bool {
+			e, ok := nginxlog.Parse(line)
+			if !ok {
+				// 无法解析的行（如多行错误信息）只在不过滤时输出
+				return !shared && filter.Status == nil && filter.Since.IsZero() && filter.Until.IsZero()
+			}
+			if e.Host != "" {
+				withHost++
+			}
+			return filter.Match(e)
+		}
+
+		offsets := make([]int64, len(paths))
+		for i, p := range paths {
+			hostPath := nginxlog.HostPath(cfg.BaseDir(), p)
+			if len(paths) > 1 {
+				color.Cyan("==> %s <==", hostPath)
+			}
+			out, off, err := nginxlog.Last(hostPath, lines, keep)
+			if err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			offsets[i] = off
+			for _, l := range out {
+				printLogLine(l)
+			}
+		}
+		if shared && withHost == 0 {
+			color.Yellow("共享日志中没有主机名字段，无法按站点过滤；请使用 swag-cli add --site-log 或在 log_format 中加入 $host")
+		}
+
+		if !follow {
+			return
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		var wg sync.WaitGroup
+		for i, p := range paths {
+			wg.Add(1)
+			go func(path string, offset int64) {
+				defer wg.Done()
+				err := nginxlog.Follow(ctx, path, offset, 0, func(line string) {
+					mu.Lock()
+					defer mu.Unlock()
+					if keep(line) {
+						printLogLine(line)
+					}
+				})
+				if err != nil {
+					color.Red("%v", err)
+				}
+			}(nginxlog.HostPath(cfg.BaseDir(), p), offsets[i])
+		}
+		wg.Wait()
+	},
+}
+
+// findSiteConf 按站点名查找 proxy-confs 中的配置并解析日志相关指令
+func findSiteConf(cfg config.Config, site string) (nginxlog.SiteConf, error) {
+	_, sc, err := findSite(cfg, site)
+	return sc, err
+}
+
+// findSite 按站点名查找 proxy-confs 中的配置，返回站点信息与日志相关指令
+func findSite(cfg config.Config, site string) (nginx.SiteConfig, nginxlog.SiteConf, error) {
+	sites, err := nginx.NewManager(cfg.ProxyConfsDir()).ListSites()
+	if err != nil {
+		return nginx.SiteConfig{}, nginxlog.SiteConf{}, fmt.Errorf("读取站点配置失败: %w", err)
+	}
+	for _, s := range sites {
+		if s.Name == site {
+			sc, err := nginxlog.ParseSiteConf(filepath.Join(cfg.ProxyConfsDir(), s.Filename))
+			return s, sc, err
+		}
+	}
+	return nginx.SiteConfig{}, nginxlog.SiteConf{}, fmt.Errorf("未找到站点 %s 的配置 (在 %s)", site, cfg.ProxyConfsDir())
+}
+
+// printLogLine 按状态码或错误级别着色输出一行日志
+func printLogLine(line string) {
+	e, ok := nginxlog.Parse(line)
+	switch {
+	case !ok:
+		fmt.Println(line)
+	case e.Error && (e.Level == "error" || e.Level == "crit" || e.Level == "alert" || e.Level == "emerg"):
+		color.Red("%s", line)
+	case e.Error && e.Level == "warn":
+		color.Yellow("%s", line)
+	case e.Status >= 500:
+		color.Red("%s", line)
+	case e.Status >= 400:
+		color.Yellow("%s", line)
+	default:
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	logsCmd.Flags().BoolP("follow", "f", false, "持续输出新写入的日志（Ctrl+C 结束）")
+	logsCmd.Flags().IntP("lines", "n", 50, "先输出最后多少行（0 表示全部）")
+	logsCmd.Flags().Bool("error", false, "查看 error log（默认 access log）")
+	logsCmd.Flags().String("status", "", "只显示指定状态码，如 4xx,502")
+	logsCmd.Flags().String("since", "", "只显示该时间之后的日志：时长（30m、2h、7d）或时间（2026-01-02 15:04）")
+	logsCmd.Flags().String("until", "", "只显示该时间之前的日志，格式同 --since")
+
+	rootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/docker"
+	"swag-cli/internal/mods"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var modsCmd = &cobra.Command{
+	Use:   "mods",
+	Short: "管理 SWAG 的 DOCKER_MODS",
+	Long:  "SWAG 的扩展功能（auto-proxy、dashboard、maxmind、crowdsec、cloudflare real-ip 等）通过 DOCKER_MODS 环境变量启用。",
+}
+
+var modsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出当前启用的 mods 以及常用 mods",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		swagContainer, _ := cmd.Flags().GetString("swag-container")
+		liveValue, liveKnown := liveDockerMods(swagContainer)
+		liveMods := mods.Parse(liveValue)
+
+		// 以 docker run 启动的 SWAG 没有 compose.yaml，此时只显示容器中的配置
+		composePath, hasCompose := swagComposePath(cmd)
+		var composeMods []string
+		source := "compose: " + composePath
+		if hasCompose {
+			f, service := loadSwagCompose(cmd)
+			env, err := f.EnvMap(service)
+			if err != nil {
+				color.Red("读取 compose 环境变量失败: %v", err)
+				os.Exit(1)
+			}
+			composeMods = mods.Parse(env["DOCKER_MODS"])
+			if !liveKnown {
+				color.Yellow("警告: 无法读取 SWAG 容器 (%s) 的环境变量，仅显示 compose.yaml 中的配置", swagContainer)
+			}
+		} else {
+			if !liveKnown {
+				color.Red("未找到 %s，且无法读取 SWAG 容器 (%s) 的环境变量", composePath, swagContainer)
+				os.Exit(1)
+			}
+			color.Yellow("警告: 未找到 %s，仅显示 SWAG 容器中的 DOCKER_MODS", composePath)
+			// 没有 compose 可对比，容器中的 mods 即为当前配置
+			composeMods = liveMods
+			source = "容器: " + swagContainer
+		}
+
+		color.Cyan("已配置的 mods (%s):", source)
+		if len(composeMods) == 0 && len(liveMods) == 0 {
+			fmt.Println("  (无)")
+		}
+		for _, m := range union(composeMods, liveMods) {
+			inCompose := mods.Contains(composeMods, m)
+			inLive := mods.Contains(liveMods, m)
+
+			state := color.GreenString("已生效")
+			switch {
+			case !liveKnown:
+				state = "-"
+			case inCompose && !inLive:
+				state = color.YellowString("待重建")
+			case !inCompose && inLive:
+				state = color.YellowString("已从 compose 移除，待重建")
+			}
+
+			desc := ""
+			if km, ok := mods.Lookup(m); ok {
+				desc = km.Description
+			}
+			fmt.Printf("  %-45s %-12s %s\n", m, state, desc)
+		}
+
+		fmt.Println()
+		color.Cyan("常用 mods:")
+		for _, m := range mods.Known {
+			mark := " "
+			if mods.Contains(composeMods, m.Image) {
+				mark = "*"
+			}
+			fmt.Printf(" %s %-20s %-42s %s\n", mark, m.Name, m.Image, m.Description)
+		}
+	},
+}
+
+var modsAddCmd = &cobra.Command{
+	Use:   "add <mod> [mod...]",
+	Short: "向 DOCKER_MODS 添加 mod（可用简称，如 dashboard）",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		editMods(cmd, args, mods.Add, "已添加")
+	},
+}
+
+var modsRemoveCmd = &cobra.Command{
+	Use:   "remove <mod> [mod...]",
+	Short: "从 DOCKER_MODS 移除 mod",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		editMods(cmd, args, mods.Remove, "已移除")
+	},
+}
+
+// editMods 修改 compose.yaml 中的 DOCKER_MODS，并询问是否重建 SWAG 容器。
+// 没有 compose.yaml 时只根据容器当前的 DOCKER_MODS 计算新值并提示手动修改。
+func editMods(cmd *cobra.Command, args []string, op func(value, mod string) (string, bool), verb string) {
+	if composePath, ok := swagComposePath(cmd); !ok {
+		swagContainer, _ := cmd.Flags().GetString("swag-container")
+		value, ok := liveDockerMods(swagContainer)
+		if !ok {
+			color.Red("未找到 %s，且无法读取 SWAG 容器 (%s) 的环境变量", composePath, swagContainer)
+			os.Exit(1)
+		}
+		color.Yellow("警告: 未找到 %s，以下基于 SWAG 容器当前的 DOCKER_MODS", composePath)
+		value, changed := applyModChanges(value, args, op, verb)
+		if !changed {
+			return
+		}
+		color.Yellow("没有 compose.yaml，无法保存变更；请在启动 SWAG 的命令中设置以下值后重建容器:")
+		fmt.Printf("DOCKER_MODS=%s\n", value)
+		os.Exit(1)
+	}
+
+	f, service := loadSwagCompose(cmd)
+	env, err := f.EnvMap(service)
+	if err != nil {
+		color.Red("读取 compose 环境变量失败: %v", err)
+		os.Exit(1)
+	}
+	value, changed := applyModChanges(env["DOCKER_MODS"], args, op, verb)
+	if !changed {
+		return
+	}
+
+	if value == "" {
+		_, err = f.UnsetEnv(service, "DOCKER_MODS")
+	} else {
+		_, err = f.SetEnv(service, "DOCKER_MODS", value)
+	}
+	if err != nil {
+		color.Red("更新 DOCKER_MODS 失败: %v", err)
+		os.Exit(1)
+	}
+
+	backup, err := f.Save()
+	if err != nil {
+		color.Red("保存 compose 文件失败: %v", err)
+		os.Exit(1)
+	}
+	if backup != "" {
+		color.Cyan("已创建备份: %s", backup)
+	}
+	color.Green("已更新: %s", f.Path)
+	fmt.Printf("DOCKER_MODS=%s\n", value)
+
+	recreate, _ := cmd.Flags().GetBool("recreate")
+	if !recreate {
+		ok, err := confirm("mods 变更需要重建 SWAG 容器才能生效，是否立即重建？")
+		if err != nil || !ok {
+			color.Yellow("已跳过重建，可稍后执行: swag-cli recreate")
+			return
+		}
+	}
+	if !recreateSwagService(cmd, f.Path, service) {
+		os.Exit(1)
+	}
+}
+
+// applyModChanges 依次对 DOCKER_MODS 原始值应用 op，返回新值及是否有变化
+func applyModChanges(value string, args []string, op func(value, mod string) (string, bool), verb string) (string, bool) {
+	changed := false
+	for _, arg := range args {
+		image := mods.Resolve(arg)
+		next, ok := op(value, image)
+		if !ok {
+			color.Yellow("未变化: %s", image)
+			continue
+		}
+		value = next
+		changed = true
+		color.Green("%s: %s", verb, image)
+	}
+	return value, changed
+}
+
+// swagComposePath 返回 SWAG 的 compose.yaml 路径及其是否存在
+func swagComposePath(cmd *cobra.Command) (string, bool) {
+	swagDir, _ := cmd.Flags().GetString("swag-dir")
+	p := config.Config{SwagDir: swagDir}.ComposePath()
+	_, err := os.Stat(p)
+	return p, !os.IsNotExist(err)
+}
+
+// liveDockerMods 读取 SWAG 容器当前的 DOCKER_MODS 原始值，ok 为 false 表示无法读取容器
+func liveDockerMods(swagContainer string) (string, bool) {
+	client, err := docker.NewClient()
+	if err != nil {
+		return "", false
+	}
+	defer client.Close()
+	info, err := client.InspectContainer(context.Background(), swagContainer)
+	if err != nil || info.Config == nil {
+		return "", false
+	}
+	for _, kv := range info.Config.Env {
+		if v, ok := strings.CutPrefix(kv, "DOCKER_MODS="); ok {
+			return v, true
+		}
+	}
+	return "", true
+}
+
+func union(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, m := range b {
+		if !mods.Contains(out, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func init() {
+	modsCmd.PersistentFlags().String("service", "", "compose 中 SWAG 服务名（默认自动识别）")
+	modsAddCmd.Flags().BoolP("recreate", "y", false, "修改后直接重建 SWAG 容器，不再询问")
+	modsRemoveCmd.Flags().BoolP("recreate", "y", false, "修改后直接重建 SWAG 容器，不再询问")
+
+	modsCmd.AddCommand(modsListCmd)
+	modsCmd.AddCommand(modsAddCmd)
+	modsCmd.AddCommand(modsRemoveCmd)
+	rootCmd.AddCommand(modsCmd)
+}
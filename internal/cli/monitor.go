@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/monitor"
+	"swag-cli/internal/sitecheck"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "周期性检查站点并在状态变化时发送通知",
+	Long: `周期性执行与 test 相同的内部/外部检查，站点变为不可用、恢复、证书进入过期提醒期或证书更新后发送通知。
+同一状态只通知一次（可用 --remind 在不可用期间定期重复提醒），状态保存在 config.json 旁的 monitor-state.json，
+因此也可以在 cron 中使用 --once 运行。
+
+通知渠道配置在 config.json 旁的 monitor.json，例如：
+
+  {"notifiers": [
+    {"type": "webhook", "url": "https://example.com/hook", "headers": {"Authorization": "Bearer xxx"}},
+    {"type": "ntfy", "url": "https://ntfy.sh", "topic": "my-swag", "token": "tk_xxx"},
+    {"type": "gotify", "url": "https://gotify.example.com", "token": "app-token"},
+    {"type": "telegram", "token": "123456:ABC-DEF", "chatId": "12345678"},
+    {"type": "email", "host": "smtp.example.com", "port": 587, "username": "me", "password": "xxx",
+     "from": "swag@example.com", "to": ["me@example.com"]}
+  ]}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		threshold, _ := cmd.Flags().GetInt("fail-threshold")
+		remind, _ := cmd.Flags().GetDuration("remind")
+		statePath, _ := cmd.Flags().GetString("state")
+		testNotify, _ := cmd.Flags().GetBool("test-notify")
+
+		if !once && interval < 10*time.Second {
+			color.Red("--interval 不能小于 10s")
+			os.Exit(1)
+		}
+
+		notifiers, err := loadMonitorNotifiers()
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if testNotify {
+			sendTestNotification(notifiers)
+			return
+		}
+		if len(notifiers) == 0 {
+			color.Yellow("未配置通知渠道，状态变化只输出到终端（配置文件: monitor.json，位于 config.json 旁）")
+		}
+
+		if statePath == "" {
+			if statePath, err = config.MonitorStatePath(); err != nil {
+				color.Red("无法确定状态文件路径: %v", err)
+				os.Exit(1)
+			}
+		}
+		state, err := monitor.LoadState(statePath)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		policy := monitor.Policy{FailThreshold: threshold, Remind: remind}
+
+		// Docker 客户端只创建一次，每轮只重新读取站点配置
+		checker, err := newSiteChecker(cmd, func(format string, args ...any) {
+			logMonitor(color.YellowString(format, args...))
+		})
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		defer checker.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		round := func() {
+			plan, err := checker.plan()
+			if err != nil {
+				logMonitor(color.RedString("检查失败: %v", err))
+				return
+			}
+			results := sitecheck.RunAll(ctx, plan.checks, checker.workers)
+			if ctx.Err() != nil {
+				return
+			}
+			failed := 0
+			for _, r := range results {
+				if r.Failed() {
+					failed++
+				}
+			}
+			summary := fmt.Sprintf("检查 %d 个站点的 %d 项，失败 %d 项", len(plan.sites), len(results), failed)
+			if failed > 0 {
+				summary = color.YellowString(summary)
+			}
+			logMonitor(summary)
+
+			events := state.Update(results, policy, time.Now())
+			state.Deliver(ctx, notifiers, events, func(e monitor.Event, retry bool, err error) {
+				line := monitorEventLine(e.Message())
+				if retry {
+					line = "重新发送: " + line
+				}
+				logMonitor(line)
+				if err != nil {
+					logMonitor(color.RedString("发送通知失败: %v", err))
+				}
+			})
+			if len(state.Pending) > 0 {
+				logMonitor(color.YellowString("%d 条通知未送达，下一轮重试", len(state.Pending)))
+			}
+			if err := state.Save(statePath); err != nil {
+				logMonitor(color.RedString("%v", err))
+			}
+		}
+
+		round()
+		if once {
+			return
+		}
+		logMonitor(fmt.Sprintf("每 %s 检查一次，按 Ctrl+C 退出", interval))
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				round()
+			}
+		}
+	},
+}
+
+// loadMonitorNotifiers 读取 monitor.json 中的通知渠道
+func loadMonitorNotifiers() ([]monitor.Notifier, error) {
+	p, err := config.MonitorPath()
+	if err != nil {
+		return nil, fmt.Errorf("无法确定通知配置路径: %w", err)
+	}
+	return monitor.LoadNotifiers(p, &http.Client{Timeout: 15 * time.Second})
+}
+
+// sendTestNotification 向每个渠道发送一条测试消息并报告结果
+func sendTestNotification(notifiers []monitor.Notifier) {
+	if len(notifiers) == 0 {
+		color.Yellow("未配置通知渠道（monitor.json，位于 config.json 旁）")
+		os.Exit(1)
+	}
+	host, _ := os.Hostname()
+	m := monitor.Message{
+		Title: "swag-cli 测试通知",
+		Body:  fmt.Sprintf("来自 %s 的测试消息，收到即表示通知渠道配置正确。", host),
+		Level: monitor.LevelInfo,
+		Time:  time.Now(),
+	}
+	failed := false
+	for _, n := range notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := n.Notify(ctx, m)
+		cancel()
+		if err != nil {
+			failed = true
+			color.Red("%s: 发送失败: %v", n.Name(), err)
+			continue
+		}
+		color.Green("%s: 已发送", n.Name())
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func monitorEventLine(m monitor.Message) string {
+	switch m.Level {
+	case monitor.LevelAlert:
+		return color.RedString("%s", m.Title)
+	case monitor.LevelWarning:
+		return color.YellowString("%s", m.Title)
+	default:
+		return color.GreenString("%s", m.Title)
+	}
+}
+
+func logMonitor(line string) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), line)
+}
+
+func init() {
+	addSiteCheckFlags(monitorCmd)
+	monitorCmd.Flags().Duration("interval", 5*time.Minute, "检查间隔")
+	monitorCmd.Flags().Bool("once", false, "只检查一轮后退出（用于 cron）")
+	monitorCmd.Flags().Int("fail-threshold", 2, "连续失败多少次后判定为不可用（避免偶发失败告警）")
+	monitorCmd.Flags().Duration("remind", 0, "不可用期间重复提醒的间隔，0 表示只在状态变化时通知")
+	monitorCmd.Flags().String("state", "", "状态文件路径，默认为 config.json 旁的 monitor-state.json")
+	monitorCmd.Flags().Bool("test-notify", false, "向所有通知渠道发送测试消息后退出")
+	rootCmd.AddCommand(monitorCmd)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"swag-cli/internal/apply"
+	"swag-cli/internal/compose"
+	"swag-cli/internal/config"
+	"swag-cli/internal/docker"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var recreateCmd = &cobra.Command{
+	Use:   "recreate",
+	Short: "重建 SWAG 容器以应用环境变量、挂载与网络变更",
+	Long: `reload 只会重启容器，无法应用 compose.yaml 中环境变量、挂载与网络的变更。
+recreate 通过 Docker API 停止并重建 SWAG 容器：默认从 <swag-dir>/compose.yaml 读取服务定义，
+compose 文件不存在或指定 --no-compose 时沿用容器当前的配置。
+新容器启动失败或未能在 --wait-timeout 内就绪时，会输出其启动日志并自动回滚到旧容器。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		swagContainer, _ := cmd.Flags().GetString("swag-container")
+		service, _ := cmd.Flags().GetString("service")
+		noCompose, _ := cmd.Flags().GetBool("no-compose")
+		pull, _ := cmd.Flags().GetBool("pull")
+
+		if swagContainer == "" {
+			color.Red("未指定 SWAG 容器名称，请使用 --swag-container 标志或检查配置文件")
+			os.Exit(1)
+		}
+
+		opts := docker.RecreateOptions{Pull: pull}
+		composePath := config.Config{SwagDir: swagDir}.ComposePath()
+		if _, err := os.Stat(composePath); noCompose || err != nil {
+			if !noCompose {
+				color.Yellow("未找到 %s，将沿用容器当前配置重建", composePath)
+			}
+		} else {
+			f, err := compose.Load(composePath)
+			if err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			if strings.TrimSpace(service) == "" {
+				service, err = f.FindService(swagContainer)
+				if err != nil {
+					color.Red("%v", err)
+					color.Yellow("可使用 --service 指定 SWAG 服务名，或使用 --no-compose 沿用容器当前配置")
+					os.Exit(1)
+				}
+			}
+			if err := apply.ComposeRuntime(&opts, f, strings.TrimSpace(service)); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			color.Cyan("使用 compose 服务定义: %s (服务 %s)", composePath, service)
+		}
+
+		if !recreateSwag(cmd, swagContainer, opts) {
+			os.Exit(1)
+		}
+	},
+}
+
+// recreateSwagService 按 compose 中的服务定义重建 SWAG 容器以应用 env 等变更
+func recreateSwagService(cmd *cobra.Command, composePath, service string) bool {
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+
+	f, err := compose.Load(composePath)
+	if err != nil {
+		color.Red("%v", err)
+		return false
+	}
+	var opts docker.RecreateOptions
+	if err := apply.ComposeRuntime(&opts, f, service); err != nil {
+		color.Red("%v", err)
+		return false
+	}
+	return recreateSwag(cmd, swagContainer, opts)
+}
+
+func recreateSwag(cmd *cobra.Command, containerName string, opts docker.RecreateOptions) bool {
+	client, err := docker.NewClient()
+	if err != nil {
+		color.Red("连接 Docker 失败: %v", err)
+		return false
+	}
+
+	color.Yellow("正在重建 SWAG 容器 (%s)...", containerName)
+	opts.HealthTimeout, _ = cmd.Flags().GetDuration("wait-timeout")
+	opts.Progress = func(msg string) { color.Cyan("  %s", msg) }
+	opts.LogWriter = os.Stderr
+	res, err := client.RecreateContainer(context.Background(), containerName, opts)
+	if err != nil {
+		color.Red("重建失败: %v", err)
+		if res.RolledBack {
+			color.Yellow("已回滚到旧容器，SWAG 仍以原配置运行")
+		}
+		return false
+	}
+	color.Green("SWAG 容器已重建")
+	return true
+}
+
+func init() {
+	recreateCmd.Flags().String("service", "", "compose 中 SWAG 服务名（默认自动识别）")
+	recreateCmd.Flags().Bool("no-compose", false, "不读取 compose.yaml，沿用容器当前配置重建")
+	recreateCmd.Flags().Bool("pull", false, "重建前拉取最新镜像")
+	rootCmd.AddCommand(recreateCmd)
+}
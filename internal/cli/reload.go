@@ -1,9 +1,7 @@
 package cli
 
 import (
-	"context"
 	"os"
-	"swag-cli/internal/docker"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -11,8 +9,12 @@ import (
 
 var reloadCmd = &cobra.Command{
 	Use:   "reload",
-	Short: "重启 SWAG 容器",
-	Long:  `重启指定的 SWAG 容器以应用新的配置。`,
+	Short: "重载/重启 SWAG 以应用配置",
+	Long: `按 reload 策略让 SWAG 应用新的配置：
+  auto    先执行 nginx -t 与 nginx -s reload，失败时回退为重启容器（默认）
+  reload  仅执行 nginx -t 与 nginx -s reload
+  restart 重启容器并等待就绪（超时时间见 --wait-timeout）
+策略可通过 --reload-strategy 或 swag-cli config set reload-strategy 设置。`,
 	Run: func(cmd *cobra.Command, args []string) {
 		swagContainer, _ := cmd.Flags().GetString("swag-container")
 
@@ -21,22 +23,9 @@ var reloadCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		color.Blue("正在重启 SWAG 容器: %s ...", swagContainer)
-
-		client, err := docker.NewClient()
-		if err != nil {
-			color.Red("连接 Docker 失败: %v", err)
+		if !applySwagChanges(cmd) {
 			os.Exit(1)
 		}
-
-		// 使用 RestartContainer 而不是 ReloadNginx，因为在某些情况下（如新增子域）需要重启容器才能生效
-		err = client.RestartContainer(context.Background(), swagContainer)
-		if err != nil {
-			color.Red("重启 SWAG 容器失败: %v", err)
-			os.Exit(1)
-		}
-
-		color.Green("SWAG 容器已成功重启")
 	},
 }
 
@@ -49,4 +49,7 @@ func init() {
 	rootCmd.PersistentFlags().StringP("swag-dir", "d", cfg.SwagDir, "SWAG 基础目录路径")
 	rootCmd.PersistentFlags().String("swag-container", cfg.SwagContainer, "SWAG 容器名称 (用于 reload)")
 	rootCmd.PersistentFlags().StringP("network", "n", cfg.Network, "Docker 网络名称 (用于容器发现)")
+	rootCmd.PersistentFlags().Duration("wait-timeout", cfg.WaitTimeoutDuration(), "重启/重建 SWAG 后等待其就绪的超时时间")
+	rootCmd.PersistentFlags().String("reload-strategy", cfg.ReloadStrategy, "配置变更后的生效方式 (auto/reload/restart/none)")
+	rootCmd.PersistentFlags().Bool("no-reload", false, "只修改配置，不 reload/重启 SWAG（批量修改时使用，完成后执行 swag-cli reload）")
 }
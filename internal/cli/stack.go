@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"swag-cli/internal/compose"
+	"swag-cli/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "查看和编辑 SWAG 的 compose.yaml",
+	Long:  "读取 <swag-dir>/compose.yaml 中的 SWAG 服务定义，并在保留注释与顺序的前提下修改环境变量。",
+}
+
+var stackInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "显示 compose.yaml 中的服务、环境变量、卷、网络与标签",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		f, service := loadSwagCompose(cmd)
+
+		project, err := f.Project()
+		if err != nil {
+			color.Red("解析 compose 文件失败: %v", err)
+			os.Exit(1)
+		}
+
+		color.Cyan("compose 文件: %s", f.Path)
+		fmt.Printf("SWAG 服务: %s\n", service)
+		for _, svc := range project.Services {
+			fmt.Println()
+			title := fmt.Sprintf("[%s]", svc.Name)
+			if svc.Name == service {
+				title += " (SWAG)"
+			}
+			color.Cyan(title)
+			printField("image", svc.Image)
+			printField("container_name", svc.ContainerName)
+			printField("restart", svc.Restart)
+
+			if len(svc.Environment) > 0 {
+				fmt.Println("  environment:")
+				for _, e := range svc.Environment {
+					if e.HasValue {
+						fmt.Printf("    %s=%s\n", e.Key, e.Value)
+					} else {
+						fmt.Printf("    %s (继承宿主机环境)\n", e.Key)
+					}
+				}
+			}
+			printList("volumes", svc.Volumes)
+			printList("ports", svc.Ports)
+			printList("networks", svc.Networks)
+			if len(svc.Labels) > 0 {
+				fmt.Println("  labels:")
+				for _, l := range svc.Labels {
+					fmt.Printf("    %s=%s\n", l.Key, l.Value)
+				}
+			}
+		}
+
+		if len(project.Networks) > 0 {
+			fmt.Println()
+			color.Cyan("networks:")
+			for _, n := range project.Networks {
+				line := "  " + n.Name
+				if n.ActualName != "" {
+					line += " (name: " + n.ActualName + ")"
+				}
+				if n.External {
+					line += " [external]"
+				}
+				fmt.Println(line)
+			}
+		}
+		if len(project.Volumes) > 0 {
+			fmt.Println()
+			color.Cyan("volumes:")
+			for _, v := range project.Volumes {
+				fmt.Println("  " + v)
+			}
+		}
+	},
+}
+
+var stackEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "编辑 SWAG 服务的环境变量",
+}
+
+var stackEnvSetCmd = &cobra.Command{
+	Use:   "set KEY=VAL [KEY=VAL...]",
+	Short: "设置 SWAG 服务的环境变量",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, service := loadSwagCompose(cmd)
+
+		changed := false
+		for _, arg := range args {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				color.Red("参数格式错误: %s (应为 KEY=VAL)", arg)
+				os.Exit(1)
+			}
+			c, err := f.SetEnv(service, key, value)
+			if err != nil {
+				color.Red("设置 %s 失败: %v", key, err)
+				os.Exit(1)
+			}
+			if c {
+				changed = true
+				color.Green("%s=%s", strings.TrimSpace(key), value)
+			} else {
+				color.Yellow("%s 未变化", strings.TrimSpace(key))
+			}
+		}
+
+		saveStackChanges(cmd, f, service, changed)
+	},
+}
+
+var stackEnvUnsetCmd = &cobra.Command{
+	Use:   "unset KEY [KEY...]",
+	Short: "删除 SWAG 服务的环境变量",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, service := loadSwagCompose(cmd)
+
+		changed := false
+		for _, key := range args {
+			removed, err := f.UnsetEnv(service, strings.TrimSpace(key))
+			if err != nil {
+				color.Red("删除 %s 失败: %v", key, err)
+				os.Exit(1)
+			}
+			if removed {
+				changed = true
+				color.Green("已删除: %s", key)
+			} else {
+				color.Yellow("%s 未设置", key)
+			}
+		}
+
+		saveStackChanges(cmd, f, service, changed)
+	},
+}
+
+// loadSwagCompose 读取 compose.yaml 并定位 SWAG 服务（可用 --service 显式指定）
+func loadSwagCompose(cmd *cobra.Command) (*compose.File, string) {
+	swagDir, _ := cmd.Flags().GetString("swag-dir")
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+	service, _ := cmd.Flags().GetString("service")
+
+	cfg := config.Config{SwagDir: swagDir}
+	f, err := compose.Load(cfg.ComposePath())
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+
+	if strings.TrimSpace(service) == "" {
+		service, err = f.FindService(swagContainer)
+		if err != nil {
+			color.Red("%v", err)
+			color.Yellow("可使用 --service 指定 SWAG 服务名")
+			os.Exit(1)
+		}
+	}
+	return f, strings.TrimSpace(service)
+}
+
+// saveStackChanges 写回 compose.yaml，并按 --recreate 决定是否重建 SWAG 服务
+func saveStackChanges(cmd *cobra.Command, f *compose.File, service string, changed bool) {
+	if !changed {
+		return
+	}
+
+	backup, err := f.Save()
+	if err != nil {
+		color.Red("保存 compose 文件失败: %v", err)
+		os.Exit(1)
+	}
+	if backup != "" {
+		color.Cyan("已创建备份: %s", backup)
+	}
+	color.Green("已更新: %s", f.Path)
+
+	recreate, _ := cmd.Flags().GetBool("recreate")
+	if !recreate {
+		color.Yellow("环境变量变更需要重建容器才能生效，可使用 --recreate 或执行: swag-cli recreate")
+		return
+	}
+
+	if !recreateSwagService(cmd, f.Path, service) {
+		os.Exit(1)
+	}
+}
+
+func printField(name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Printf("  %s: %s\n", name, value)
+}
+
+func printList(name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", name)
+	for _, v := range values {
+		fmt.Printf("    - %s\n", v)
+	}
+}
+
+func init() {
+	stackCmd.PersistentFlags().String("service", "", "compose 中 SWAG 服务名（默认自动识别）")
+	stackEnvSetCmd.Flags().Bool("recreate", false, "修改后立即重建 SWAG 容器")
+	stackEnvUnsetCmd.Flags().Bool("recreate", false, "修改后立即重建 SWAG 容器")
+
+	stackEnvCmd.AddCommand(stackEnvSetCmd)
+	stackEnvCmd.AddCommand(stackEnvUnsetCmd)
+	stackCmd.AddCommand(stackInfoCmd)
+	stackCmd.AddCommand(stackEnvCmd)
+	rootCmd.AddCommand(stackCmd)
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"swag-cli/internal/config"
+	"swag-cli/internal/nginx"
+	"swag-cli/internal/nginxlog"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "统计 nginx access log（请求数、状态码、流量、热门路径与客户端）",
+	Long: `解析 log/nginx 下的 access log（combined 格式，含轮转的 .gz 文件），按站点汇总。
+共享日志中的请求按主机名与站点的 server_name 归属（log_format 需包含 $host），无法确定站点的请求记为 "-"；
+log_format 中以 urt=$upstream_response_time 记录响应时间时，同时统计 upstream 响应时间的 p50/p95。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		site, _ := cmd.Flags().GetString("site")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		top, _ := cmd.Flags().GetInt("top")
+
+		since, err := nginxlog.ParseTimeBound(sinceStr, time.Now())
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+
+		cfg := config.Config{SwagDir: swagDir}
+		files, err := nginxlog.AccessLogFiles(cfg.LogDir())
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		rep, err := nginxlog.Stats(files, nginxlog.StatsOptions{
+			ServerNames: siteServerNames(cfg),
+			Site:        site,
+			Since:       since,
+			Top:         top,
+		})
+		if err != nil {
+			color.Red("统计失败: %v", err)
+			os.Exit(1)
+		}
+		if printJSONIfRequested(cmd, rep) {
+			return
+		}
+		printStatsReport(rep, site)
+	},
+}
+
+// siteServerNames 读取所有站点配置的 server_name
+func siteServerNames(cfg config.Config) map[string][]string {
+	out := map[string][]string{}
+	sites, err := nginx.NewManager(cfg.ProxyConfsDir()).ListSites()
+	if err != nil {
+		return out
+	}
+	for _, s := range sites {
+		if sc, err := nginxlog.ParseSiteConf(filepath.Join(cfg.ProxyConfsDir(), s.Filename)); err == nil {
+			out[s.Name] = sc.ServerNames
+		}
+	}
+	return out
+}
+
+func printStatsReport(rep nginxlog.Report, site string) {
+	if rep.Total.Requests == 0 {
+		color.Yellow("没有匹配的请求（已读取 %d 个日志文件）", len(rep.Files))
+		return
+	}
+	color.Cyan("已读取 %d 个日志文件，%s ~ %s，共 %d 个请求",
+		len(rep.Files), rep.First.Local().Format("2006-01-02 15:04"), rep.Last.Local().Format("2006-01-02 15:04"), rep.Total.Requests)
+	if rep.Unparsed > 0 {
+		color.Yellow("%d 行不是 combined 格式，已跳过", rep.Unparsed)
+	}
+	fmt.Println()
+
+	fmt.Printf("%-32s %9s %7s %7s %7s %7s %10s %9s %9s\n", "SITE", "REQUESTS", "2XX", "3XX", "4XX", "5XX", "BYTES", "P50", "P95")
+	rows := rep.Sites
+	if site == "" && len(rows) > 1 {
+		rows = append(rows, rep.Total)
+	}
+	for _, s := range rows {
+		fmt.Printf("%-32s %9d %7d %7d %7d %7d %10s %9s %9s\n", s.Site, s.Requests,
+			s.Classes["2xx"], s.Classes["3xx"], s.Classes["4xx"], s.Classes["5xx"], formatSize(s.Bytes),
+			upstreamPercentile(s, s.UpstreamP50), upstreamPercentile(s, s.UpstreamP95))
+	}
+
+	detail := rep.Total
+	fmt.Println()
+	color.Cyan("状态码:")
+	for _, c := range statusCounts(detail.Statuses) {
+		fmt.Printf("  %s %8d\n", c.Key, c.Count)
+	}
+	color.Cyan("热门路径:")
+	for _, c := range detail.TopPaths {
+		fmt.Printf("  %8d  %s\n", c.Count, c.Key)
+	}
+	color.Cyan("热门客户端 IP:")
+	for _, c := range detail.TopClients {
+		fmt.Printf("  %8d  %s\n", c.Count, c.Key)
+	}
+}
+
+func upstreamPercentile(s nginxlog.SiteStats, d time.Duration) string {
+	if s.UpstreamSamples == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func statusCounts(m map[int]int) []nginxlog.Count {
+	var out []nginxlog.Count
+	for code := 100; code < 600; code++ {
+		if n := m[code]; n > 0 {
+			out = append(out, nginxlog.Count{Key: fmt.Sprint(code), Count: n})
+		}
+	}
+	return out
+}
+
+func init() {
+	statsCmd.Flags().String("site", "", "只统计指定站点")
+	statsCmd.Flags().String("since", "24h", "统计该时间之后的请求：时长（24h、7d）或时间（2026-01-02 15:04）；为空时统计全部")
+	statsCmd.Flags().Int("top", 10, "热门路径与客户端 IP 的条数")
+	statsCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+
+	rootCmd.AddCommand(statsCmd)
+}
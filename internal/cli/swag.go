@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
+	"swag-cli/internal/config"
 	"swag-cli/internal/swagexport"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +23,7 @@ var swagCmd = &cobra.Command{
 
 var swagExportCmd = &cobra.Command{
 	Use:   "export",
-	Short: "导出 SWAG 容器配置到 zip 文件",
+	Short: "导出 SWAG 容器配置到归档文件（zip/tar.gz/tar.zst）",
 	Run: func(cmd *cobra.Command, args []string) {
 		swagDir, _ := cmd.Flags().GetString("swag-dir")
 		out, _ := cmd.Flags().GetString("out")
@@ -25,47 +31,635 @@ var swagExportCmd = &cobra.Command{
 		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
 		proxyConfOnly, _ := cmd.Flags().GetBool("proxy-conf-only")
 		excludeGlobs, _ := cmd.Flags().GetStringArray("exclude-glob")
+		includeGlobs, _ := cmd.Flags().GetStringArray("include-glob")
+		formatStr, _ := cmd.Flags().GetString("format")
+		incrementalFrom, _ := cmd.Flags().GetString("incremental-from")
+		planOnly, _ := cmd.Flags().GetBool("plan")
+		redact, _ := cmd.Flags().GetBool("redact")
+		redactMap, _ := cmd.Flags().GetString("redact-map")
 
-		profile := swagexport.Profile(strings.ToLower(strings.TrimSpace(profileStr)))
-		switch profile {
-		case swagexport.ProfileMinimal, swagexport.ProfileStandard, swagexport.ProfileFull:
-		default:
-			color.Red("无效 profile: %s (可选: minimal|standard|full)", profileStr)
+		// 输出到 stdout 时，提示信息改写到 stderr，避免混入归档数据
+		var stream *os.File
+		if strings.TrimSpace(out) == "-" && !planOnly {
+			stream = os.Stdout
+			color.Output = os.Stderr
+		}
+
+		format, err := swagexport.ParseArchiveFormat(formatStr)
+		if err != nil {
+			color.Red("%v", err)
 			os.Exit(1)
 		}
+		if stream != nil && format == "" {
+			format = swagexport.FormatZip
+		}
 
-		if profile != swagexport.ProfileFull && includeSecrets {
+		profile, custom := exportProfile(profileStr)
+		if profile != swagexport.ProfileFull && (custom == nil || custom.Base != swagexport.ProfileFull) && includeSecrets && len(includeGlobs) == 0 {
 			color.Yellow("提示: --include-secrets 只有在 --profile=full 时才会包含 dns-conf/keys/letsencrypt 等目录")
 		}
 
+		enc, err := exportEncryption(cmd)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		var baseDec swagexport.Decryption
+		if incrementalFrom = strings.TrimSpace(incrementalFrom); incrementalFrom != "" {
+			if cmd.Flags().Changed("profile") || cmd.Flags().Changed("include-secrets") || cmd.Flags().Changed("proxy-conf-only") || cmd.Flags().Changed("exclude-glob") || cmd.Flags().Changed("include-glob") {
+				color.Yellow("提示: 增量导出沿用基准归档的 profile 与过滤规则，已忽略 --profile/--include-secrets/--proxy-conf-only/--exclude-glob/--include-glob")
+			}
+			if baseDec, err = archiveDecryption(cmd, incrementalFrom); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+		}
+		if planOnly {
+			printExportPlan(swagexport.Options{
+				SwagDir:         swagDir,
+				Profile:         profile,
+				Custom:          custom,
+				IncludeSecrets:  includeSecrets,
+				ProxyConfOnly:   proxyConfOnly,
+				ExcludeGlobs:    excludeGlobs,
+				IncludeGlobs:    includeGlobs,
+				IncrementalFrom: incrementalFrom,
+				BaseDecryption:  baseDec,
+			})
+			return
+		}
+		if redact && incrementalFrom != "" {
+			color.Red("--redact 不能与 --incremental-from 同时使用")
+			os.Exit(1)
+		}
+		if profile == swagexport.ProfileFull && includeSecrets && !enc.Enabled() && !redact {
+			color.Yellow("警告: 归档将以明文包含 DNS API token、私钥等敏感内容，存放到其他主机前建议使用 --encrypt 或 --recipient 加密")
+		}
+
 		res, err := swagexport.Export(swagexport.Options{
-			SwagDir:        swagDir,
-			OutPath:        out,
-			Profile:        profile,
-			IncludeSecrets: includeSecrets,
-			ProxyConfOnly:  proxyConfOnly,
-			ExcludeGlobs:   excludeGlobs,
-			Version:        cmd.Root().Version,
+			SwagDir:         swagDir,
+			OutPath:         out,
+			Profile:         profile,
+			IncludeSecrets:  includeSecrets,
+			ProxyConfOnly:   proxyConfOnly,
+			ExcludeGlobs:    excludeGlobs,
+			IncludeGlobs:    includeGlobs,
+			Custom:          custom,
+			Version:         cmd.Root().Version,
+			Encryption:      enc,
+			Format:          format,
+			Out:             writerOrNil(stream),
+			IncrementalFrom: incrementalFrom,
+			BaseDecryption:  baseDec,
+			Container:       swagContainerMetadata(cmd),
+			Redact:          redact,
+			RedactMapPath:   redactMap,
 		})
 		if err != nil {
 			color.Red("导出失败: %v", err)
 			os.Exit(1)
 		}
 
-		color.Green("导出完成: %s", res.OutPath)
-		color.Cyan("已导出文件数: %d", res.FileCount)
+		if stream != nil {
+			color.Green("导出完成: 已写入 stdout (%s)", format)
+		} else {
+			color.Green("导出完成: %s", res.OutPath)
+		}
+		if enc.Enabled() {
+			color.Cyan("归档已使用 age 加密，import/verify 时需提供 passphrase 或 --identity 私钥")
+		}
+		if inc := res.Manifest.Incremental; inc != nil {
+			color.Cyan("增量导出: 新增/变化 %d，沿用基准 %d，删除 %d", res.FileCount, len(inc.Unchanged), len(inc.Deleted))
+		} else {
+			color.Cyan("已导出文件数: %d", res.FileCount)
+		}
 		color.Cyan("归档内包含 manifest: %s", res.ManifestPath)
+		if c := res.Manifest.Container; c != nil {
+			color.Cyan("已记录容器信息: %s (%s)，可用 swag container 生成 compose.yaml 或 docker run 命令", c.Name, c.Image)
+		}
+		if rs := res.Redaction; rs != nil {
+			color.Cyan("已脱敏: 新增占位符 %d 个，映射文件: %s", rs.Added, rs.MapPath)
+			for _, d := range rs.Dropped {
+				color.Yellow("  未导出（无法脱敏）: %s", d)
+			}
+			color.Yellow("映射文件包含原始域名、IP 与密钥，请只保存在本机，不要随归档一起分享")
+		}
+	},
+}
+
+var swagImportCmd = &cobra.Command{
+	Use:   "import <archive> [<increment>...]",
+	Short: "从 swag export 导出的归档恢复 SWAG 配置",
+	Long: `读取归档内的 manifest 并校验文件清单，列出将新增/覆盖的文件，确认后写入 swag-dir（保留原权限与修改时间）。
+覆盖前会将现有文件备份为 <swag-dir>/backups/swag-pre-import.<时间>.zip（可再次 import 回滚）。
+恢复增量归档时，按顺序指定完整归档与后续的增量归档，例如: swag import full.zip inc1.zip inc2.zip`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		only, _ := cmd.Flags().GetStringSlice("only")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		backupDir, _ := cmd.Flags().GetString("backup-dir")
+		noBackup, _ := cmd.Flags().GetBool("no-backup")
+
+		cfg := config.Config{SwagDir: swagDir}
+		opts := swagexport.ImportOptions{
+			ArchivePath: strings.TrimSpace(args[0]),
+			Increments:  args[1:],
+			SwagDir:     cfg.BaseDir(),
+			Only:        only,
+			BackupDir:   backupDir,
+			NoBackup:    noBackup,
+		}
+		decs, err := chainDecryptions(cmd, append([]string{opts.ArchivePath}, opts.Increments...))
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		opts.Decryption, opts.IncrementDecryptions = decs[0], decs[1:]
+
+		plan, err := swagexport.PlanImport(opts)
+		if err != nil {
+			color.Red("校验归档失败: %v", err)
+			os.Exit(1)
+		}
+
+		for i, m := range plan.Chain {
+			kind := "归档"
+			if m.Incremental != nil {
+				kind = "增量"
+			}
+			color.Cyan("%s: %s (profile=%s, 导出于 %s, swag-cli %s)", kind, args[i], m.Profile, m.CreatedAt.Local().Format("2006-01-02 15:04:05"), m.SwagCLIVersion)
+		}
+		var creates, overwrites, deletes, unchanged int
+		for _, e := range plan.Entries {
+			switch e.Action {
+			case swagexport.ActionCreate:
+				creates++
+				color.Green("  + %s", e.Path)
+			case swagexport.ActionOverwrite:
+				overwrites++
+				color.Yellow("  ~ %s (将覆盖)", e.Path)
+			case swagexport.ActionDelete:
+				deletes++
+				color.Red("  - %s (增量归档记录已删除，将删除)", e.Path)
+			default:
+				unchanged++
+			}
+		}
+		fmt.Printf("新增 %d，覆盖 %d，删除 %d，未变化 %d\n", creates, overwrites, deletes, unchanged)
+
+		if creates+overwrites+deletes == 0 {
+			color.Green("现有配置与归档一致，无需恢复")
+			return
+		}
+		if dryRun {
+			color.Green("Dry-run: 未写入任何文件。")
+			return
+		}
+		if !yes {
+			ok, err := confirm(fmt.Sprintf("确认恢复到 %s 吗？", opts.SwagDir))
+			if err != nil || !ok {
+				color.Yellow("已取消恢复")
+				return
+			}
+		}
+
+		res, err := swagexport.Import(opts)
+		if res.BackupPath != "" {
+			color.Cyan("已备份将被覆盖的文件: %s", res.BackupPath)
+		}
+		if err != nil {
+			color.Red("恢复失败: %v", err)
+			os.Exit(1)
+		}
+		if res.Deleted > 0 {
+			color.Green("恢复完成，已写入 %d 个文件，删除 %d 个文件", res.Written, res.Deleted)
+		} else {
+			color.Green("恢复完成，已写入 %d 个文件", res.Written)
+		}
+		if plan.Manifest.Container != nil {
+			color.Cyan("归档记录了容器信息，如需在新主机上创建容器: swag-cli swag container %s --format compose|run", args[len(args)-1])
+		}
+
+		var nginxChanged, composeChanged bool
+		for _, e := range res.Plan.Entries {
+			if e.Action == swagexport.ActionUnchanged {
+				continue
+			}
+			if e.Path == "compose.yaml" {
+				composeChanged = true
+			} else {
+				nginxChanged = true
+			}
+		}
+		if composeChanged {
+			color.Yellow("compose.yaml 已恢复，需重建容器才能生效: swag-cli recreate")
+		}
+		if nginxChanged {
+			applySwagChanges(cmd)
+		}
+	},
+}
+
+var swagVerifyCmd = &cobra.Command{
+	Use:   "verify <zip>",
+	Short: "校验导出归档的完整性（sha256），并可与现有 SWAG 目录比对",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		live, _ := cmd.Flags().GetBool("live")
+
+		opts := swagexport.VerifyOptions{ArchivePath: strings.TrimSpace(args[0])}
+		if live {
+			opts.SwagDir = config.Config{SwagDir: swagDir}.BaseDir()
+		}
+		dec, err := archiveDecryption(cmd, opts.ArchivePath)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		opts.Decryption = dec
+
+		report, err := swagexport.Verify(opts)
+		if err != nil {
+			color.Red("校验失败: %v", err)
+			os.Exit(1)
+		}
+
+		m := report.Manifest
+		color.Cyan("归档: %s (profile=%s, %d 个文件, formatVersion=%d)", opts.ArchivePath, m.Profile, len(m.Files), m.FormatVersion)
+		if inc := m.Incremental; inc != nil {
+			color.Cyan("增量归档: 基准导出于 %s，沿用 %d 个文件，删除 %d 个文件", inc.BaseCreatedAt.Local().Format("2006-01-02 15:04:05"), len(inc.Unchanged), len(inc.Deleted))
+		}
+		if c := m.Container; c != nil {
+			color.Cyan("容器: %s (%s)", c.Name, c.Image)
+		}
+		if m.Redacted {
+			color.Yellow("该归档已脱敏，只用于分享，不能用于 import 恢复")
+		}
+		if report.HasHashes {
+			fmt.Printf("digest: %s\n", m.Digest)
+		} else {
+			color.Yellow("该归档的 manifest 不含 sha256，只能校验文件大小")
+		}
+
+		ok := report.OK()
+		if ok {
+			color.Green("归档完整: 内容与 manifest 一致")
+		} else {
+			color.Red("归档与 manifest 不一致:")
+			if report.HasHashes && !report.DigestOK {
+				color.Red("  digest 与文件清单不符（manifest 可能被修改）")
+			}
+			printFileDiff(report.Archive, "归档中缺失", "内容被修改", "manifest 未记录")
+		}
+
+		if report.Live != nil {
+			fmt.Println()
+			if report.Live.Empty() {
+				color.Green("现有 SWAG 目录与归档一致")
+			} else {
+				color.Yellow("现有 SWAG 目录 (%s) 与归档的差异:", opts.SwagDir)
+				printFileDiff(*report.Live, "现有目录中缺失", "已修改", "归档中没有")
+			}
+		}
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+var swagDiffCmd = &cobra.Command{
+	Use:   "diff <zip> [<zip2>]",
+	Short: "比对归档与现有 SWAG 目录（或两个归档）的差异",
+	Long:  "只指定一个归档时，与现有 swag-dir 比对（按归档记录的导出档位选取文件）；指定两个归档时比对二者。\n敏感文件（dns-conf/keys/letsencrypt、.htpasswd、*.pem）与配置中的 token/password 等值默认隐藏，使用 --show-secrets 显示。",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		swagDir, _ := cmd.Flags().GetString("swag-dir")
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+		nameOnly, _ := cmd.Flags().GetBool("name-only")
+		context, _ := cmd.Flags().GetInt("context")
+
+		opts := swagexport.DiffOptions{
+			ArchivePath: strings.TrimSpace(args[0]),
+			ShowSecrets: showSecrets,
+			Context:     context,
+		}
+		if len(args) == 2 {
+			opts.OtherPath = strings.TrimSpace(args[1])
+		} else {
+			opts.SwagDir = config.Config{SwagDir: swagDir}.BaseDir()
+		}
+
+		var err error
+		if opts.Decryption, err = archiveDecryption(cmd, opts.ArchivePath); err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if opts.OtherPath != "" {
+			if opts.OtherDecryption, err = archiveDecryption(cmd, opts.OtherPath); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+		}
+
+		report, err := swagexport.Diff(opts)
+		if err != nil {
+			color.Red("比对失败: %v", err)
+			os.Exit(1)
+		}
+
+		if len(report.Changes) == 0 {
+			color.Green("无差异: %s 与 %s 一致", report.From, report.To)
+			return
+		}
+		color.Cyan("比对 %s -> %s:", report.From, report.To)
+		var added, removed, changed int
+		for _, c := range report.Changes {
+			switch c.Status {
+			case swagexport.DiffAdded:
+				added++
+				color.Green("  + %s", c.Path)
+			case swagexport.DiffRemoved:
+				removed++
+				color.Red("  - %s", c.Path)
+			default:
+				changed++
+				color.Yellow("  ~ %s", c.Path)
+			}
+		}
+		fmt.Printf("新增 %d，删除 %d，修改 %d\n", added, removed, changed)
+		if nameOnly {
+			return
+		}
+
+		redacted := false
+		for _, c := range report.Changes {
+			redacted = redacted || c.Redacted
+			switch {
+			case c.NoContent:
+				fmt.Printf("\n%s 有差异（增量归档未包含其内容，无法显示 diff）\n", c.Path)
+			case c.Binary:
+				fmt.Printf("\n二进制文件 %s 有差异\n", c.Path)
+			case c.Unified == "" && c.Redacted:
+				fmt.Printf("\n敏感文件 %s 有差异（内容已隐藏）\n", c.Path)
+			case c.Unified != "":
+				fmt.Println()
+				printUnifiedDiff(c.Unified)
+			}
+		}
+		if redacted && !showSecrets {
+			fmt.Println()
+			color.Yellow("提示: 敏感内容已隐藏，使用 --show-secrets 显示")
+		}
 	},
 }
 
+// printUnifiedDiff 按行着色输出统一 diff
+func printUnifiedDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			fmt.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			color.Cyan("%s", line)
+		case strings.HasPrefix(line, "+"):
+			color.Green("%s", line)
+		case strings.HasPrefix(line, "-"):
+			color.Red("%s", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// exportProfile 解析 --profile，支持配置目录下 export-profiles.json 中的自定义档位
+func exportProfile(name string) (swagexport.Profile, *swagexport.CustomProfile) {
+	custom := map[string]swagexport.CustomProfile{}
+	if p, err := config.ProfilesPath(); err == nil {
+		if custom, err = swagexport.LoadProfiles(p); err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+	}
+	profile, cp, err := swagexport.ResolveProfile(name, custom)
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+	return profile, cp
+}
+
+// printExportPlan 输出导出计划：按目录汇总的文件数与大小，不写入任何内容
+func printExportPlan(opts swagexport.Options) {
+	plan, err := swagexport.PlanExport(opts)
+	if err != nil {
+		color.Red("计算导出计划失败: %v", err)
+		os.Exit(1)
+	}
+
+	desc := string(plan.Profile)
+	if plan.Custom != nil {
+		desc = fmt.Sprintf("%s (自定义，基于 %s)", plan.Profile, plan.Custom.Base)
+	}
+	color.Cyan("导出计划: profile=%s", desc)
+	if inc := plan.Incremental; inc != nil {
+		color.Cyan("增量导出: 沿用基准 %d 个文件，删除 %d 个文件", len(inc.Unchanged), len(inc.Deleted))
+	}
+	if len(plan.Files) == 0 {
+		color.Yellow("没有需要导出的文件")
+		return
+	}
+	fmt.Printf("%-50s %6s %10s\n", "DIR", "FILES", "SIZE")
+	for _, d := range plan.Dirs {
+		fmt.Printf("%-50s %6d %10s\n", d.Dir, d.Files, formatSize(d.Bytes))
+	}
+	fmt.Printf("%-50s %6d %10s\n", "TOTAL", len(plan.Files), formatSize(plan.TotalBytes))
+	color.Green("Plan: 未写入任何文件。")
+}
+
+// writerOrNil 避免把 nil *os.File 作为非 nil 的 io.Writer 传入
+func writerOrNil(f *os.File) io.Writer {
+	if f == nil {
+		return nil
+	}
+	return f
+}
+
+// exportEncryption 根据 --encrypt/--recipient/--recipients-file 构造加密参数
+func exportEncryption(cmd *cobra.Command) (swagexport.Encryption, error) {
+	encrypt, _ := cmd.Flags().GetBool("encrypt")
+	recipients, _ := cmd.Flags().GetStringArray("recipient")
+	recipientsFiles, _ := cmd.Flags().GetStringArray("recipients-file")
+
+	for _, p := range recipientsFiles {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return swagexport.Encryption{}, fmt.Errorf("读取 recipients 文件失败 (%s): %w", p, err)
+		}
+		recipients = append(recipients, string(b))
+	}
+	parsed, err := swagexport.ParseRecipients(recipients)
+	if err != nil {
+		return swagexport.Encryption{}, err
+	}
+	if len(parsed) > 0 {
+		if encrypt {
+			return swagexport.Encryption{}, errors.New("--encrypt（passphrase）与 --recipient 不能同时使用")
+		}
+		return swagexport.Encryption{Recipients: parsed}, nil
+	}
+	if !encrypt {
+		return swagexport.Encryption{}, nil
+	}
+
+	pass, err := readPassphrase(cmd, true)
+	if err != nil {
+		return swagexport.Encryption{}, err
+	}
+	return swagexport.Encryption{Passphrase: pass}, nil
+}
+
+// archiveDecryption 对加密归档按 --identity 或 passphrase 构造解密凭据；未加密时返回零值
+func archiveDecryption(cmd *cobra.Command, archivePath string) (swagexport.Decryption, error) {
+	encrypted, err := swagexport.IsEncrypted(archivePath)
+	if err != nil || !encrypted {
+		return swagexport.Decryption{}, err
+	}
+
+	identityFiles, _ := cmd.Flags().GetStringArray("identity")
+	var dec swagexport.Decryption
+	for _, p := range identityFiles {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return dec, fmt.Errorf("读取 age 私钥失败 (%s): %w", p, err)
+		}
+		dec.Identities = append(dec.Identities, string(b))
+	}
+	if len(dec.Identities) > 0 {
+		return dec, nil
+	}
+
+	dec.Passphrase, err = readPassphrase(cmd, false)
+	return dec, err
+}
+
+// chainDecryptions 为增量链中的每个归档分别构造解密凭据：之前的凭据能解密时沿用，否则重新读取
+func chainDecryptions(cmd *cobra.Command, archivePaths []string) ([]swagexport.Decryption, error) {
+	out := make([]swagexport.Decryption, 0, len(archivePaths))
+	var known []swagexport.Decryption
+	for _, p := range archivePaths {
+		encrypted, err := swagexport.IsEncrypted(p)
+		if err != nil {
+			return nil, err
+		}
+		if !encrypted {
+			out = append(out, swagexport.Decryption{})
+			continue
+		}
+		i := slices.IndexFunc(known, func(d swagexport.Decryption) bool { return swagexport.CanDecrypt(p, d) })
+		if i >= 0 {
+			out = append(out, known[i])
+			continue
+		}
+		if len(known) > 0 {
+			color.Cyan("%s 需要其他解密凭据", p)
+		}
+		dec, err := archiveDecryption(cmd, p)
+		if err != nil {
+			return nil, err
+		}
+		known = append(known, dec)
+		out = append(out, dec)
+	}
+	return out, nil
+}
+
+// readPassphrase 依次从 --passphrase-file、环境变量 SWAG_CLI_PASSPHRASE、终端输入读取 passphrase
+func readPassphrase(cmd *cobra.Command, confirmInput bool) (string, error) {
+	if p, _ := cmd.Flags().GetString("passphrase-file"); strings.TrimSpace(p) != "" {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("读取 passphrase 文件失败 (%s): %w", p, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	if v := os.Getenv("SWAG_CLI_PASSPHRASE"); v != "" {
+		return v, nil
+	}
+
+	var pass string
+	// 提示输出到 stderr，以便 export --out - 时不污染 stdout
+	stdio := survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)
+	if err := survey.AskOne(&survey.Password{Message: "请输入归档 passphrase:"}, &pass, survey.WithValidator(survey.Required), stdio); err != nil {
+		return "", fmt.Errorf("读取 passphrase 失败: %w", err)
+	}
+	if confirmInput {
+		var again string
+		if err := survey.AskOne(&survey.Password{Message: "请再次输入 passphrase:"}, &again, stdio); err != nil {
+			return "", fmt.Errorf("读取 passphrase 失败: %w", err)
+		}
+		if again != pass {
+			return "", errors.New("两次输入的 passphrase 不一致")
+		}
+	}
+	return pass, nil
+}
+
+func printFileDiff(d swagexport.FileDiff, missing, modified, extra string) {
+	for _, p := range d.Missing {
+		color.Red("  - %s (%s)", p, missing)
+	}
+	for _, p := range d.Modified {
+		color.Yellow("  ~ %s (%s)", p, modified)
+	}
+	for _, p := range d.Extra {
+		color.Cyan("  + %s (%s)", p, extra)
+	}
+}
+
 func init() {
 	swagCmd.AddCommand(swagExportCmd)
+	swagCmd.AddCommand(swagImportCmd)
+	swagCmd.AddCommand(swagVerifyCmd)
+	swagCmd.AddCommand(swagDiffCmd)
 	rootCmd.AddCommand(swagCmd)
 
-	swagExportCmd.Flags().String("out", "", "导出的 zip 文件路径（默认当前目录带时间戳）")
-	swagExportCmd.Flags().String("profile", string(swagexport.ProfileStandard), "导出档位：minimal|standard|full")
+	swagExportCmd.Flags().String("out", "", "导出文件路径（默认当前目录带时间戳，加密时追加 .age）；- 表示输出到 stdout")
+	swagExportCmd.Flags().String("incremental-from", "", "增量导出：只包含相对该归档新增或变化的文件，并记录删除的文件")
+	swagExportCmd.Flags().String("format", "", "归档格式: zip|tar.gz|tar.zst（默认按 --out 扩展名推断，否则为 zip）")
+	swagExportCmd.Flags().String("profile", string(swagexport.ProfileStandard), "导出档位：minimal|standard|full，或配置目录下 export-profiles.json 中的自定义档位")
 	swagExportCmd.Flags().Bool("include-secrets", false, "允许导出敏感内容（如 dns-conf/keys/letsencrypt 等，仅 full 生效）")
 	swagExportCmd.Flags().Bool("proxy-conf-only", true, "proxy-confs 仅导出 .conf/.conf.disabled，排除 sample/example")
 	swagExportCmd.Flags().StringArray("exclude-glob", nil, "额外排除模式（支持 ** 通配）")
-}
+	swagExportCmd.Flags().StringArray("include-glob", nil, "额外导出的路径（相对 swag-dir，支持 ** 通配），可重复指定；敏感目录仍需 --include-secrets")
+	swagExportCmd.Flags().Bool("plan", false, "只显示将导出的文件（按目录汇总数量与大小），不写入归档")
+	swagExportCmd.Flags().Bool("no-container", false, "不在 manifest 中记录 SWAG 容器的运行参数")
+	swagExportCmd.Flags().Bool("redact", false, "脱敏导出（用于分享排障）：以稳定的占位符替换域名、公网 IP、邮箱与密钥，不导出私钥与证书；归档不能用于恢复")
+	swagExportCmd.Flags().String("redact-map", "", "脱敏映射文件路径（默认 <归档>.redact-map.json）；已存在时沿用其中的占位符")
+
+	swagExportCmd.Flags().Bool("encrypt", false, "使用 passphrase 加密整个归档（age/scrypt）")
+	swagExportCmd.Flags().StringArray("recipient", nil, "使用 age 公钥 (age1...) 加密整个归档，可重复指定")
+	swagExportCmd.Flags().StringArray("recipients-file", nil, "从文件读取 age 公钥（每行一个）")
 
+	for _, c := range []*cobra.Command{swagExportCmd, swagImportCmd, swagVerifyCmd, swagDiffCmd} {
+		c.Flags().String("passphrase-file", "", "从文件读取 passphrase（也可使用环境变量 SWAG_CLI_PASSPHRASE）")
+	}
+	for _, c := range []*cobra.Command{swagExportCmd, swagImportCmd, swagVerifyCmd, swagDiffCmd} {
+		c.Flags().StringArray("identity", nil, "解密用的 age 私钥文件，可重复指定")
+	}
+
+	swagImportCmd.Flags().StringSlice("only", nil, "只恢复部分内容，如 proxy-confs,site-confs,nginx,compose,www 或归档内路径前缀")
+	swagImportCmd.Flags().Bool("dry-run", false, "只显示将新增/覆盖的文件，不写入")
+	swagImportCmd.Flags().BoolP("yes", "y", false, "跳过确认，直接恢复")
+	swagImportCmd.Flags().String("backup-dir", "", "恢复前备份的输出目录（默认 <swag-dir>/backups）")
+	swagImportCmd.Flags().Bool("no-backup", false, "不备份将被覆盖的文件")
+
+	swagVerifyCmd.Flags().Bool("live", false, "同时与现有 swag-dir 比对，列出缺失/修改/新增的文件")
+
+	swagDiffCmd.Flags().Bool("show-secrets", false, "显示敏感文件内容与配置中的 token/password 等值")
+	swagDiffCmd.Flags().Bool("name-only", false, "只列出有差异的文件，不输出文本 diff")
+	swagDiffCmd.Flags().IntP("context", "U", 3, "文本 diff 的上下文行数")
+}
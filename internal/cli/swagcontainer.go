@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"swag-cli/internal/docker"
+	"swag-cli/internal/swagexport"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var swagContainerCmd = &cobra.Command{
+	Use:   "container <archive>",
+	Short: "根据归档中记录的容器信息生成 compose.yaml 或 docker run 命令",
+	Long: `swag export/backup 会在 manifest 中记录 SWAG 容器的镜像、环境变量、挂载、网络、端口与标签，
+在新主机恢复时可据此重新创建容器。token/密码等敏感环境变量在导出时已隐藏，生成结果中需自行提供。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+
+		archivePath := strings.TrimSpace(args[0])
+		dec, err := archiveDecryption(cmd, archivePath)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		m, err := swagexport.ReadManifest(archivePath, dec)
+		if err != nil {
+			color.Red("读取归档失败: %v", err)
+			os.Exit(1)
+		}
+		if m.Container == nil {
+			color.Red("归档中没有容器信息（导出时无法访问 Docker，或使用了 --no-container）")
+			os.Exit(1)
+		}
+
+		var data []byte
+		switch strings.ToLower(strings.TrimSpace(format)) {
+		case "compose":
+			if data, err = m.Container.ComposeYAML(); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+		case "run":
+			data = []byte(m.Container.DockerRun())
+		default:
+			color.Red("无效格式: %s (可选: compose|run)", format)
+			os.Exit(1)
+		}
+
+		if strings.TrimSpace(out) == "" {
+			fmt.Print(string(data))
+			return
+		}
+		if _, err := os.Stat(out); err == nil {
+			color.Red("文件已存在: %s", out)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(out, data, 0o644); err != nil {
+			color.Red("写入失败: %v", err)
+			os.Exit(1)
+		}
+		color.Green("已写入: %s", out)
+		if masked := m.Container.MaskedEnv(); len(masked) > 0 {
+			color.Yellow("以下环境变量的值在导出时已隐藏，需自行提供: %s", strings.Join(masked, ", "))
+		}
+	},
+}
+
+// swagContainerMetadata 读取 SWAG 容器的运行参数用于写入 manifest；--no-container 或无法访问 Docker 时返回 nil
+func swagContainerMetadata(cmd *cobra.Command) *swagexport.Container {
+	if noContainer, _ := cmd.Flags().GetBool("no-container"); noContainer {
+		return nil
+	}
+	name, _ := cmd.Flags().GetString("swag-container")
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+
+	client, err := docker.NewClient()
+	if err != nil {
+		color.Yellow("警告: 无法连接 Docker，归档中不包含容器信息: %v", err)
+		return nil
+	}
+	ctx := context.Background()
+	info, err := client.InspectContainer(ctx, name)
+	if err != nil {
+		color.Yellow("警告: 读取 SWAG 容器 (%s) 信息失败，归档中不包含容器信息: %v", name, err)
+		return nil
+	}
+	// 镜像信息只用于剔除镜像内置的 ENV 与记录 digest，读取失败时忽略
+	img, err := client.InspectImage(ctx, info.Image)
+	if err != nil {
+		img = nil
+	}
+	c, err := swagexport.ContainerFromInspect(*info, img)
+	if err != nil {
+		color.Yellow("警告: %v，归档中不包含容器信息", err)
+		return nil
+	}
+	return c
+}
+
+func init() {
+	swagContainerCmd.Flags().String("format", "compose", "输出格式: compose|run")
+	swagContainerCmd.Flags().StringP("out", "o", "", "写入文件（默认输出到 stdout）")
+	swagContainerCmd.Flags().String("passphrase-file", "", "从文件读取 passphrase（也可使用环境变量 SWAG_CLI_PASSPHRASE）")
+	swagContainerCmd.Flags().StringArray("identity", nil, "解密用的 age 私钥文件，可重复指定")
+	swagCmd.AddCommand(swagContainerCmd)
+}
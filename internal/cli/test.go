@@ -3,7 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"net"
 	"os"
 	"strings"
 	"time"
@@ -11,6 +11,7 @@ import (
 	"swag-cli/internal/config"
 	"swag-cli/internal/docker"
 	"swag-cli/internal/nginx"
+	"swag-cli/internal/sitecheck"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -19,111 +20,463 @@ import (
 var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Tests connectivity for configured sites",
-	Long:  `Tests both external accessibility (domain resolution) and internal connectivity (swag -> target container).`,
-	Run: func(cmd *cobra.Command, args []string) {
-		swagDir, _ := cmd.Flags().GetString("swag-dir")
-		swagContainer, _ := cmd.Flags().GetString("swag-container")
+	Long: `Tests both external accessibility (domain resolution) and internal connectivity (swag -> target container).
+
+Internal checks connect from this host to the container IP on the Docker network (--probe host),
+or run curl inside the SWAG container (--probe exec). The default (auto) probes from the host and
+falls back to exec when the container network isn't reachable from the host (e.g. Docker Desktop).
+
+External checks request https://<site>.<URL>, report the A/AAAA/CNAME records of each subdomain and
+verify the certificate (trusted chain, expiry, hostname). Inside a LAN without hairpin NAT, use
+--resolve to connect to the SWAG host directly while keeping the site's SNI and Host header.
 
-		cfg := config.Config{SwagDir: swagDir}
-		manager := nginx.NewManager(cfg.ProxyConfsDir())
+Checks run concurrently. A check passes when the response status is below 500, unless the site has
+expectations in site-checks.json (next to config.json), e.g.:
+
+  {"sites": {"my-app": {"path": "/health", "status": "200,3xx", "body": "ok"}, "mqtt": {"tcp": true}}}
+
+Exits with status 1 when any check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		asJSON, _ := cmd.Flags().GetBool("json")
 
-		sites, err := manager.ListSites()
+		checker, err := newSiteChecker(cmd, printWarning)
 		if err != nil {
-			color.Red("Failed to read configuration: %v", err)
+			color.Red("%v", err)
 			os.Exit(1)
 		}
-
-		if len(sites) == 0 {
-			color.Yellow("No sites configured (in %s)", cfg.ProxyConfsDir())
+		defer checker.Close()
+		plan, err := checker.plan()
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if len(plan.sites) == 0 {
+			color.Yellow("No sites configured (in %s)", checker.confDir)
 			return
 		}
+		sites, checks, workers := plan.sites, plan.checks, checker.workers
 
-		dockerClient, err := docker.NewClient()
-		var baseDomain string
-		if err != nil {
-			color.Yellow("Warning: Docker client check failed: %v. Internal checks may fail.", err)
-		} else {
-			// Try to get URL env from swag container
-			if info, err := dockerClient.InspectContainer(context.Background(), swagContainer); err == nil {
-				for _, env := range info.Config.Env {
-					if strings.HasPrefix(env, "URL=") {
-						baseDomain = strings.TrimPrefix(env, "URL=")
-						break
-					}
-				}
+		if !asJSON {
+			fmt.Printf("Testing %d sites (%d workers)...\n", len(sites), workers)
+			if checker.baseDomain != "" {
+				fmt.Printf("Base Domain: %s\n", checker.baseDomain)
+			}
+			if checker.resolve != "" {
+				fmt.Printf("Resolve: %s\n", checker.resolve)
 			}
+			fmt.Println("")
 		}
 
-		fmt.Printf("Testing %d sites...\n", len(sites))
-		if baseDomain != "" {
-			fmt.Printf("Base Domain: %s\n", baseDomain)
+		results := sitecheck.RunAll(context.Background(), checks, workers)
+		failed := 0
+		for _, r := range results {
+			if r.Failed() {
+				failed++
+			}
 		}
-		fmt.Println("")
 
-		fmt.Printf("%-20s | %-30s | %-20s | %-25s\n", "Name", "Target", "Internal (Swag->)", "External (Curl)")
-		fmt.Println(strings.Repeat("-", 105))
-
-		httpClient := &http.Client{
-			Timeout: 5 * time.Second,
+		if !printJSONIfRequested(cmd, results) {
+			printTestResults(sites, results, verbose)
+			if failed > 0 {
+				color.Red("\n%d of %d checks failed", failed, len(results))
+			}
 		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
 
-		for _, site := range sites {
-			if site.Status == nginx.StatusDisabled {
-				continue
-			}
+// siteCheckPlan holds the enabled sites and their internal/external checks
+type siteCheckPlan struct {
+	sites  []nginx.SiteConfig
+	checks []sitecheck.Check
+}
 
-			// Internal Check (Swag -> Target)
-			internalStatus := "-"
-			if dockerClient != nil && (site.TargetType == nginx.TargetContainer || site.TargetType == nginx.TargetIP) {
-				// site.TargetDest is the container name or IP
-				// site.ContainerPort is the port
-				targetURL := fmt.Sprintf("http://%s:%s", site.TargetDest, site.ContainerPort)
-
-				// Using curl -I to fetch headers only, -m 5 for timeout
-				cmd := []string{"curl", "-I", "-m", "5", targetURL}
-				_, err := dockerClient.Exec(context.Background(), swagContainer, cmd)
-				if err == nil {
-					internalStatus = color.GreenString("PASS")
-				} else {
-					internalStatus = color.RedString("FAIL")
-				}
-			} else if site.TargetType == nginx.TargetStatic {
-				internalStatus = color.CyanString("STATIC")
-			}
+// siteChecker builds the checks for test and monitor from the flags registered by addSiteCheckFlags.
+// The Docker client and HTTP client are created once; plan re-reads the site list on every call.
+type siteChecker struct {
+	confDir    string
+	network    string
+	only       []string
+	workers    int
+	resolve    string
+	baseDomain string
+	probe      internalProbe
+	external   sitecheck.ExternalChecker
+	// warn reports Docker problems; ipsErr avoids repeating the same listing warning every round
+	warn   func(format string, args ...any)
+	ipsErr string
+}
+
+func newSiteChecker(cmd *cobra.Command, warn func(format string, args ...any)) (*siteChecker, error) {
+	swagDir, _ := cmd.Flags().GetString("swag-dir")
+	swagContainer, _ := cmd.Flags().GetString("swag-container")
+	network, _ := cmd.Flags().GetString("network")
+	probeMode, _ := cmd.Flags().GetString("probe")
+	only, _ := cmd.Flags().GetStringSlice("site")
+	workers, _ := cmd.Flags().GetInt("workers")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	resolve, _ := cmd.Flags().GetString("resolve")
+	certWarnDays, _ := cmd.Flags().GetInt("cert-warn-days")
+
+	if probeMode != "auto" && probeMode != "host" && probeMode != "exec" {
+		return nil, fmt.Errorf("Invalid --probe: %s (auto, host or exec)", probeMode)
+	}
+	httpClient, err := sitecheck.NewHTTPClient(timeout, resolve)
+	if err != nil {
+		return nil, err
+	}
 
-			// External Check
-			externalStatus := "-"
-			if baseDomain != "" && site.Type == nginx.TypeSubdomain {
-				// Construct URL: https://<site_name>.<base_domain>
-				// Assuming HTTPS by default for SWAG
-				// Note: site.Name for subdomain conf is just the subdomain part.
-				fullURL := fmt.Sprintf("https://%s.%s", site.Name, baseDomain)
-
-				resp, err := httpClient.Get(fullURL)
-				if err == nil {
-					if resp.StatusCode >= 200 && resp.StatusCode < 500 {
-						externalStatus = color.GreenString("PASS (%d)", resp.StatusCode)
-					} else {
-						externalStatus = color.RedString("FAIL (%d)", resp.StatusCode)
-					}
-					resp.Body.Close()
-				} else {
-					externalStatus = color.RedString("FAIL (Unreachable)")
+	cfg := config.Config{SwagDir: swagDir}
+	c := &siteChecker{
+		confDir: cfg.ProxyConfsDir(),
+		network: network,
+		only:    only,
+		workers: workers,
+		resolve: resolve,
+		probe:   internalProbe{swagContainer: swagContainer, mode: probeMode, timeout: timeout},
+		external: sitecheck.ExternalChecker{
+			Client:   httpClient,
+			Resolver: net.DefaultResolver,
+			CertWarn: time.Duration(certWarnDays) * 24 * time.Hour,
+		},
+		warn: warn,
+	}
+
+	if dockerClient, err := docker.NewClient(); err != nil {
+		warn("Warning: Docker client check failed: %v. Internal checks for containers will be skipped.", err)
+	} else {
+		c.probe.client = dockerClient
+		// Try to get URL env from swag container
+		if info, err := dockerClient.InspectContainer(context.Background(), swagContainer); err == nil {
+			for _, env := range info.Config.Env {
+				if strings.HasPrefix(env, "URL=") {
+					c.baseDomain = strings.TrimPrefix(env, "URL=")
+					break
 				}
-			} else {
-				externalStatus = color.YellowString("? (No Domain)")
 			}
+		}
+	}
+	return c, nil
+}
+
+// Close releases the Docker client
+func (c *siteChecker) Close() {
+	if c.probe.client != nil {
+		c.probe.client.Close()
+	}
+}
 
-			fmt.Printf("%-20s | %-30s | %-20s | %-25s\n",
-				site.Name,
-				site.TargetDest+":"+site.ContainerPort,
-				internalStatus,
-				externalStatus,
-			)
+// plan reads the enabled sites and expectations and builds their checks.
+// Container IPs are refreshed each time since they change when containers are recreated.
+func (c *siteChecker) plan() (siteCheckPlan, error) {
+	var plan siteCheckPlan
+	sites, err := nginx.NewManager(c.confDir).ListSites()
+	if err != nil {
+		return plan, fmt.Errorf("Failed to read configuration: %w", err)
+	}
+	plan.sites = filterSites(sites, c.only)
+	if len(plan.sites) == 0 {
+		return plan, nil
+	}
+
+	expects := map[string]sitecheck.Expect{}
+	if p, err := config.ChecksPath(); err == nil {
+		if expects, err = sitecheck.LoadExpectations(p); err != nil {
+			return plan, err
 		}
-	},
+	}
+
+	if c.probe.client != nil && c.probe.mode != "exec" {
+		ips, err := containerIPs(c.probe.client, c.network)
+		switch {
+		case err != nil && err.Error() != c.ipsErr:
+			c.warn("Warning: cannot list containers in network '%s': %v. Internal checks will run inside SWAG.", c.network, err)
+			c.ipsErr = err.Error()
+		case err == nil:
+			c.ipsErr = ""
+		}
+		c.probe.ips = ips
+	}
+
+	for _, site := range plan.sites {
+		expect := expects[site.Name]
+		plan.checks = append(plan.checks,
+			c.probe.check(site, expect),
+			externalCheck(site, c.external, c.baseDomain, expect),
+		)
+	}
+	return plan, nil
+}
+
+// addSiteCheckFlags registers the flags read by newSiteChecker
+func addSiteCheckFlags(c *cobra.Command) {
+	c.Flags().StringSlice("site", nil, "Only test the given sites (repeatable or comma separated)")
+	c.Flags().String("probe", "auto", "How internal checks run: auto, host (connect to container IPs from this host) or exec (curl inside SWAG)")
+	c.Flags().Int("workers", 8, "Number of checks to run concurrently")
+	c.Flags().Duration("timeout", 5*time.Second, "Timeout for each check")
+	c.Flags().String("resolve", "", "Connect external checks to this IP[:port] (e.g. the SWAG host in the LAN) instead of resolving DNS")
+	c.Flags().Int("cert-warn-days", 14, "Warn when a site certificate expires within this many days")
+}
+
+// filterSites returns enabled sites, limited to the given names when not empty
+func filterSites(sites []nginx.SiteConfig, only []string) []nginx.SiteConfig {
+	var out []nginx.SiteConfig
+	for _, s := range sites {
+		if s.Status == nginx.StatusDisabled {
+			continue
+		}
+		if len(only) > 0 && !containsString(only, s.Name) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// internalProbe builds swag -> target checks according to --probe
+type internalProbe struct {
+	client        *docker.Client
+	swagContainer string
+	mode          string
+	timeout       time.Duration
+	// ips maps container names on the SWAG network to their IP
+	ips map[string]string
+}
+
+func (p internalProbe) check(site nginx.SiteConfig, expect sitecheck.Expect) sitecheck.Check {
+	switch {
+	case site.TargetType == nginx.TargetStatic:
+		return skippedCheck(site.Name, sitecheck.KindInternal, "STATIC")
+	case site.TargetType != nginx.TargetContainer && site.TargetType != nginx.TargetIP:
+		return skippedCheck(site.Name, sitecheck.KindInternal, "no upstream")
+	}
+
+	var exec sitecheck.Check
+	if p.client != nil {
+		exec = sitecheck.Internal(p.client, p.swagContainer, site.Name, upstreamURL(site), expect, p.timeout)
+	}
+	if p.mode == "exec" {
+		if exec == nil {
+			return skippedCheck(site.Name, sitecheck.KindInternal, "no docker")
+		}
+		return exec
+	}
+
+	host := site.TargetDest
+	if site.TargetType == nginx.TargetContainer {
+		host = p.ips[site.TargetDest]
+	}
+	if host == "" {
+		// Container IP unknown (not on the SWAG network or no docker): only SWAG can resolve the name
+		if p.mode == "host" || exec == nil {
+			return skippedCheck(site.Name, sitecheck.KindInternal, "no container IP")
+		}
+		return exec
+	}
+	addr := net.JoinHostPort(host, upstreamPort(site))
+	if p.mode == "host" {
+		exec = nil
+	}
+	return sitecheck.Native(site.Name, upstreamURL(site), addr, expect, p.timeout, exec)
+}
+
+// containerIPs returns container name -> IP for containers on the SWAG network
+func containerIPs(client *docker.Client, network string) (map[string]string, error) {
+	ips := map[string]string{}
+	containers, err := client.ListContainersByNetwork(context.Background(), network)
+	if err != nil {
+		return ips, err
+	}
+	for _, c := range containers {
+		if c.IP != "" {
+			ips[c.Name] = c.IP
+		}
+	}
+	return ips, nil
+}
+
+// externalCheck checks https://<site>.<URL> from this host
+func externalCheck(site nginx.SiteConfig, checker sitecheck.ExternalChecker, baseDomain string, expect sitecheck.Expect) sitecheck.Check {
+	switch {
+	case baseDomain == "":
+		return skippedCheck(site.Name, sitecheck.KindExternal, "no domain")
+	case site.Type != nginx.TypeSubdomain:
+		return skippedCheck(site.Name, sitecheck.KindExternal, "subfolder")
+	}
+	// Assuming HTTPS by default for SWAG; site.Name for subdomain conf is just the subdomain part.
+	return checker.Check(site.Name, fmt.Sprintf("https://%s.%s", site.Name, baseDomain), expect)
+}
+
+func skippedCheck(site string, kind sitecheck.Kind, reason string) sitecheck.Check {
+	return func(ctx context.Context) sitecheck.Result {
+		return sitecheck.Result{Site: site, Kind: kind, Skipped: reason}
+	}
+}
+
+// upstreamPort returns $upstream_port, defaulting to the port of $upstream_proto
+func upstreamPort(site nginx.SiteConfig) string {
+	switch {
+	case site.ContainerPort != "":
+		return site.ContainerPort
+	case site.UpstreamProto == "https":
+		return "443"
+	default:
+		return "80"
+	}
+}
+
+// upstreamURL builds $upstream_proto://$upstream_app:$upstream_port for a site
+func upstreamURL(site nginx.SiteConfig) string {
+	proto := site.UpstreamProto
+	if proto != "https" {
+		proto = "http"
+	}
+	if site.ContainerPort == "" {
+		return fmt.Sprintf("%s://%s", proto, site.TargetDest)
+	}
+	return fmt.Sprintf("%s://%s:%s", proto, site.TargetDest, site.ContainerPort)
+}
+
+func printTestResults(sites []nginx.SiteConfig, results []sitecheck.Result, verbose bool) {
+	fmt.Printf("%-20s | %-30s | %-24s | %-24s\n", "Name", "Target", "Internal (Swag->)", "External")
+	fmt.Println(strings.Repeat("-", 108))
+
+	byKey := map[string]sitecheck.Result{}
+	for _, r := range results {
+		byKey[r.Site+"/"+string(r.Kind)] = r
+	}
+	for _, site := range sites {
+		fmt.Printf("%-20s | %-30s | %-24s | %-24s\n",
+			site.Name,
+			site.TargetDest+":"+site.ContainerPort,
+			resultCell(byKey[site.Name+"/"+string(sitecheck.KindInternal)]),
+			resultCell(byKey[site.Name+"/"+string(sitecheck.KindExternal)]),
+		)
+	}
+
+	var details []sitecheck.Result
+	for _, r := range results {
+		if r.Failed() || len(r.Warnings) > 0 || (verbose && r.Skipped == "") {
+			details = append(details, r)
+		}
+	}
+	if len(details) == 0 {
+		return
+	}
+	fmt.Println("")
+	for _, r := range details {
+		printResultDetail(r)
+	}
+}
+
+// printResultDetail prints URL, status, timings, TLS, DNS and certificate details of a check
+func printResultDetail(r sitecheck.Result) {
+	line := fmt.Sprintf("%s [%s] %s", r.Site, r.Kind, r.URL)
+	if r.Status > 0 {
+		line += fmt.Sprintf(" -> %d", r.Status)
+	}
+	if r.Latency > 0 {
+		line += fmt.Sprintf(" in %s", r.Latency.Round(time.Millisecond))
+	}
+	if r.Via != "" {
+		line += " via " + r.Via
+	}
+	if t := r.Timing; t != nil && t.Connect > 0 {
+		line += fmt.Sprintf(" (connect %s", t.Connect.Round(time.Millisecond))
+		if t.TLS > 0 {
+			line += fmt.Sprintf(", tls %s", t.TLS.Round(time.Millisecond))
+		}
+		if t.FirstByte > 0 {
+			line += fmt.Sprintf(", first byte %s", t.FirstByte.Round(time.Millisecond))
+		}
+		line += ")"
+	}
+	if r.TLS != nil {
+		line += fmt.Sprintf(", %s %s", r.TLS.Version, r.TLS.Cipher)
+		if r.Cert == nil && !r.TLS.NotAfter.IsZero() {
+			line += fmt.Sprintf(", cert expires %s", r.TLS.NotAfter.Format("2006-01-02"))
+		}
+	}
+	if r.Error != "" {
+		color.Red("  %s: %s", line, r.Error)
+	} else {
+		fmt.Printf("  %s\n", line)
+	}
+
+	if d := r.DNS; d != nil {
+		var records []string
+		if d.CNAME != "" {
+			records = append(records, "CNAME "+d.CNAME)
+		}
+		for _, ip := range d.A {
+			records = append(records, "A "+ip)
+		}
+		for _, ip := range d.AAAA {
+			records = append(records, "AAAA "+ip)
+		}
+		if d.Error != "" {
+			records = append(records, d.Error)
+		}
+		fmt.Printf("      DNS: %s\n", strings.Join(records, ", "))
+	}
+	if c := r.Cert; c != nil {
+		hostname := "hostname ok"
+		if !c.HostnameMatch {
+			hostname = "hostname mismatch (" + strings.Join(c.DNSNames, ", ") + ")"
+		}
+		fmt.Printf("      Cert: %s, issued by %s, expires %s (%d days), %s\n",
+			c.Subject, c.Issuer, c.NotAfter.Format("2006-01-02"), c.DaysLeft, hostname)
+	}
+	for _, w := range r.Warnings {
+		color.Yellow("      Warning: %s", w)
+	}
+}
+
+// resultCell renders a result as PASS/FAIL with status and latency
+func resultCell(r sitecheck.Result) string {
+	// Pad before coloring so escape codes don't break column alignment
+	pad := func(s string) string { return fmt.Sprintf("%-24s", s) }
+	switch {
+	case r.Skipped == "STATIC":
+		return color.CyanString(pad("STATIC"))
+	case r.Skipped != "":
+		return color.YellowString(pad("? (" + r.Skipped + ")"))
+	}
+	text := "PASS"
+	if !r.Pass {
+		text = "FAIL"
+	}
+	if r.Status > 0 {
+		text += fmt.Sprintf(" (%d, %s)", r.Status, r.Latency.Round(time.Millisecond))
+	} else if r.Pass {
+		text += fmt.Sprintf(" (TCP, %s)", r.Latency.Round(time.Millisecond))
+	} else {
+		text += " (Unreachable)"
+	}
+	switch {
+	case r.Pass && len(r.Warnings) > 0:
+		return color.YellowString(pad(text + " !"))
+	case r.Pass:
+		return color.GreenString(pad(text))
+	}
+	return color.RedString(pad(text))
 }
 
 func init() {
+	addSiteCheckFlags(testCmd)
+	testCmd.Flags().BoolP("verbose", "v", false, "Show URL, status, timings, TLS, DNS and certificate details for every check")
+	testCmd.Flags().Bool("json", false, "Output results as JSON")
 	rootCmd.AddCommand(testCmd)
 }
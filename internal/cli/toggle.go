@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"context"
 	"os"
 	"swag-cli/internal/config"
-	"swag-cli/internal/docker"
 	"swag-cli/internal/nginx"
 
 	"github.com/fatih/color"
@@ -33,19 +31,7 @@ var toggleCmd = &cobra.Command{
 			color.Yellow("站点 '%s' 已禁用", subdomain)
 		}
 
-		// 触发 Nginx reload
-		swagContainer, _ := cmd.Flags().GetString("swag-container")
-		client, err := docker.NewClient()
-		if err == nil {
-			color.Yellow("正在重载 SWAG (%s) Nginx...", swagContainer)
-			if err := client.ReloadNginx(context.Background(), swagContainer); err != nil {
-				color.Red("Nginx 重载失败: %v", err)
-			} else {
-				color.Green("Nginx 重载成功！配置已生效。")
-			}
-		} else {
-			color.Yellow("无法连接 Docker，跳过 Nginx 重载: %v", err)
-		}
+		applySwagChanges(cmd)
 	},
 }
 
@@ -0,0 +1,430 @@
+// Package compose 解析并编辑 SWAG 所在的 compose.yaml。
+//
+// 读取基于 yaml.v3 的节点树；写入时只对受影响的行做最小化修改，
+// 以保留原文件中的注释、空行与键顺序。
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File 表示一个已加载的 compose 文件
+type File struct {
+	Path  string
+	lines []string
+	root  *yaml.Node
+}
+
+// EnvVar 表示 environment 中的一项，保持文件中的顺序
+type EnvVar struct {
+	Key   string
+	Value string
+	// HasValue 为 false 表示只写了变量名（值从宿主机环境继承）
+	HasValue bool
+}
+
+// Load 读取并解析 compose 文件
+func Load(path string) (*File, error) {
+	cleanPath := filepath.Clean(strings.TrimSpace(path))
+	if cleanPath == "" {
+		return nil, errors.New("compose 路径为空")
+	}
+	data, err := os.ReadFile(cleanPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 compose 文件失败 (%s): %w", cleanPath, err)
+	}
+	return Parse(cleanPath, data)
+}
+
+// Parse 从内存内容解析 compose 文件；path 仅用于 Save 与错误信息
+func Parse(path string, data []byte) (*File, error) {
+	f := &File{Path: path}
+	if err := f.reset(string(data)); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) reset(content string) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return fmt.Errorf("解析 compose 文件失败 (%s): %w", f.Path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("compose 文件格式无效 (%s): 顶层不是映射", f.Path)
+	}
+	f.root = doc.Content[0]
+	f.lines = strings.Split(content, "\n")
+	return nil
+}
+
+// Bytes 返回当前（可能已修改的）文件内容
+func (f *File) Bytes() []byte {
+	return []byte(strings.Join(f.lines, "\n"))
+}
+
+// Save 将当前内容写回 Path。写入前会在同目录下保存 .bak 备份，返回备份路径。
+func (f *File) Save() (string, error) {
+	original, err := os.ReadFile(f.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("读取 compose 文件失败 (%s): %w", f.Path, err)
+	}
+
+	// 保留原文件权限（compose 文件可能包含密钥而设为 0600）
+	mode := os.FileMode(0o644)
+	backup := ""
+	if err == nil {
+		if st, err := os.Stat(f.Path); err == nil {
+			mode = st.Mode().Perm()
+		}
+		backup = fmt.Sprintf("%s.bak-%s", f.Path, time.Now().Format("20060102-150405"))
+		if err := writeFileMode(backup, original, mode); err != nil {
+			return "", fmt.Errorf("备份 compose 文件失败 (%s): %w", backup, err)
+		}
+	}
+
+	tmp := f.Path + ".tmp"
+	if err := writeFileMode(tmp, f.Bytes(), mode); err != nil {
+		return backup, fmt.Errorf("写入临时 compose 文件失败 (%s): %w", tmp, err)
+	}
+	if err := os.Rename(tmp, f.Path); err != nil {
+		_ = os.Remove(tmp)
+		return backup, fmt.Errorf("保存 compose 文件失败 (%s): %w", f.Path, err)
+	}
+	return backup, nil
+}
+
+// writeFileMode 写入文件并设置权限（不受 umask 影响）
+func writeFileMode(path string, data []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// ServiceNames 按文件顺序返回所有服务名
+func (f *File) ServiceNames() []string {
+	services := mappingValue(f.root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil
+	}
+	var names []string
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		names = append(names, services.Content[i].Value)
+	}
+	return names
+}
+
+// FindService 根据 SWAG 容器名定位 compose 中对应的服务。
+//
+// 匹配顺序：container_name 相同 -> 服务名相同 -> 镜像包含 "swag" -> 文件中只有一个服务。
+func (f *File) FindService(containerName string) (string, error) {
+	names := f.ServiceNames()
+	if len(names) == 0 {
+		return "", fmt.Errorf("compose 文件中未找到任何服务 (%s)", f.Path)
+	}
+
+	containerName = strings.TrimSpace(containerName)
+	for _, n := range names {
+		if v := scalarValue(mappingValue(f.service(n), "container_name")); v != "" && v == containerName {
+			return n, nil
+		}
+	}
+	for _, n := range names {
+		if n == containerName {
+			return n, nil
+		}
+	}
+
+	var swagLike []string
+	for _, n := range names {
+		image := strings.ToLower(scalarValue(mappingValue(f.service(n), "image")))
+		if strings.Contains(image, "swag") {
+			swagLike = append(swagLike, n)
+		}
+	}
+	if len(swagLike) == 1 {
+		return swagLike[0], nil
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	return "", fmt.Errorf("无法在 compose 文件中确定 SWAG 服务 (容器名: %s, 服务: %s)", containerName, strings.Join(names, ", "))
+}
+
+// Env 返回服务的 environment 列表（支持列表与映射两种写法）
+func (f *File) Env(service string) ([]EnvVar, error) {
+	svc := f.service(service)
+	if svc == nil {
+		return nil, fmt.Errorf("服务不存在: %s", service)
+	}
+
+	envNode := mappingValue(svc, "environment")
+	if envNode == nil {
+		return nil, nil
+	}
+
+	var out []EnvVar
+	switch envNode.Kind {
+	case yaml.SequenceNode:
+		for _, item := range envNode.Content {
+			key, value, hasValue := strings.Cut(item.Value, "=")
+			out = append(out, EnvVar{Key: strings.TrimSpace(key), Value: value, HasValue: hasValue})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(envNode.Content); i += 2 {
+			v := envNode.Content[i+1]
+			hasValue := !(v.Tag == "!!null" && v.Value == "")
+			out = append(out, EnvVar{Key: envNode.Content[i].Value, Value: v.Value, HasValue: hasValue})
+		}
+	case yaml.ScalarNode:
+		if envNode.Tag != "!!null" {
+			return nil, fmt.Errorf("服务 %s 的 environment 格式无效", service)
+		}
+	default:
+		return nil, fmt.Errorf("服务 %s 的 environment 格式无效", service)
+	}
+	return out, nil
+}
+
+// EnvMap 以 map 形式返回服务的 environment（仅包含显式赋值的变量）
+func (f *File) EnvMap(service string) (map[string]string, error) {
+	vars, err := f.Env(service)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if v.HasValue {
+			m[v.Key] = v.Value
+		}
+	}
+	return m, nil
+}
+
+// SetEnv 设置服务的环境变量：已存在则原地替换该行，不存在则追加到 environment 末尾。
+// 返回值 changed 表示内容是否发生变化。
+func (f *File) SetEnv(service, key, value string) (bool, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, "= \t") {
+		return false, fmt.Errorf("无效的环境变量名: %q", key)
+	}
+
+	svc := f.service(service)
+	if svc == nil {
+		return false, fmt.Errorf("服务不存在: %s", service)
+	}
+
+	envNode := mappingValue(svc, "environment")
+	if envNode == nil || (envNode.Kind == yaml.ScalarNode && envNode.Tag == "!!null") {
+		return true, f.insertEnvironment(svc, envNode, key, value)
+	}
+	if envNode.Style&yaml.FlowStyle != 0 {
+		return false, fmt.Errorf("服务 %s 的 environment 使用了 flow 写法，请手动编辑", service)
+	}
+
+	switch envNode.Kind {
+	case yaml.SequenceNode:
+		for _, item := range envNode.Content {
+			k, v, hasValue := strings.Cut(item.Value, "=")
+			if strings.TrimSpace(k) != key {
+				continue
+			}
+			if hasValue && v == value {
+				return false, nil
+			}
+			return true, f.replaceScalarLine(item, formatScalar(key+"="+value))
+		}
+		last := envNode.Content[len(envNode.Content)-1]
+		prefix := f.lines[last.Line-1][:last.Column-1]
+		return true, f.insertLines(f.lastLine(envNode), prefix+formatScalar(key+"="+value))
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(envNode.Content); i += 2 {
+			k, v := envNode.Content[i], envNode.Content[i+1]
+			if k.Value != key {
+				continue
+			}
+			if v.Value == value && v.Tag != "!!null" {
+				return false, nil
+			}
+			return true, f.replaceMappingValue(k, v, formatScalar(value))
+		}
+		firstKey := envNode.Content[0]
+		indent := strings.Repeat(" ", firstKey.Column-1)
+		return true, f.insertLines(f.lastLine(envNode), indent+key+": "+formatScalar(value))
+	default:
+		return false, fmt.Errorf("服务 %s 的 environment 格式无效", service)
+	}
+}
+
+func (f *File) service(name string) *yaml.Node {
+	services := mappingValue(f.root, "services")
+	if services == nil {
+		return nil
+	}
+	svc := mappingValue(services, name)
+	if svc == nil || svc.Kind != yaml.MappingNode {
+		return nil
+	}
+	return svc
+}
+
+// insertEnvironment 在服务中新建 environment 段（或替换空的 environment:）
+func (f *File) insertEnvironment(svc *yaml.Node, envNode *yaml.Node, key, value string) error {
+	if len(svc.Content) == 0 {
+		return errors.New("服务定义为空，无法追加 environment")
+	}
+	indent := strings.Repeat(" ", svc.Content[0].Column-1)
+	item := indent + "  - " + formatScalar(key+"="+value)
+
+	if envNode != nil {
+		// "environment:" 后没有内容：直接在其下方追加
+		var keyNode *yaml.Node
+		for i := 0; i+1 < len(svc.Content); i += 2 {
+			if svc.Content[i+1] == envNode {
+				keyNode = svc.Content[i]
+			}
+		}
+		if keyNode == nil {
+			return errors.New("无法定位 environment 键")
+		}
+		return f.insertLines(keyNode.Line, item)
+	}
+
+	return f.insertLines(f.lastLine(svc), indent+"environment:", item)
+}
+
+// replaceScalarLine 替换单行标量（列表项）所在行，保留前缀与行尾注释
+func (f *File) replaceScalarLine(n *yaml.Node, text string) error {
+	if n.Kind != yaml.ScalarNode || n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return fmt.Errorf("第 %d 行不是单行标量，请手动编辑", n.Line)
+	}
+	line := f.lines[n.Line-1]
+	newLine := line[:n.Column-1] + text + trailingComment(line, n)
+	return f.replaceLine(n.Line, newLine)
+}
+
+// replaceMappingValue 替换 "KEY: VALUE" 行中的值，保留缩进、键写法与行尾注释
+func (f *File) replaceMappingValue(k *yaml.Node, v *yaml.Node, text string) error {
+	if v.Kind != yaml.ScalarNode || v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || v.Line != k.Line {
+		return fmt.Errorf("第 %d 行的值不是单行标量，请手动编辑", k.Line)
+	}
+	line := f.lines[k.Line-1]
+	colon := strings.Index(line[k.Column-1:], ":")
+	if colon < 0 {
+		return fmt.Errorf("第 %d 行格式无法识别", k.Line)
+	}
+	prefix := line[:k.Column-1+colon+1]
+	return f.replaceLine(k.Line, prefix+" "+text+trailingComment(line, v))
+}
+
+func (f *File) replaceLine(lineNo int, text string) error {
+	lines := append([]string(nil), f.lines...)
+	lines[lineNo-1] = text
+	return f.reset(strings.Join(lines, "\n"))
+}
+
+// insertLines 在第 after 行（1 起始）之后插入若干行
+func (f *File) insertLines(after int, text ...string) error {
+	lines := make([]string, 0, len(f.lines)+len(text))
+	lines = append(lines, f.lines[:after]...)
+	lines = append(lines, text...)
+	lines = append(lines, f.lines[after:]...)
+	return f.reset(strings.Join(lines, "\n"))
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return strings.TrimSpace(n.Value)
+}
+
+// lastLine 返回节点（含所有子节点）占用的最后一行。
+// yaml.Node 只记录起始行，块标量（| 或 >）与跨行标量按其后缩进更深的行计算结束位置，避免在其内容中间插入。
+func (f *File) lastLine(n *yaml.Node) int {
+	last := n.Line
+	if n.Kind == yaml.ScalarNode && (n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(n.Value, "\n")) {
+		last = f.scalarEnd(n)
+	}
+	for _, c := range n.Content {
+		if l := f.lastLine(c); l > last {
+			last = l
+		}
+	}
+	return last
+}
+
+// scalarEnd 返回跨行标量的最后一行：起始行之后缩进更深的行（忽略空行）都属于该标量
+func (f *File) scalarEnd(n *yaml.Node) int {
+	if n.Line < 1 || n.Line > len(f.lines) {
+		return n.Line
+	}
+	indent := indentWidth(f.lines[n.Line-1])
+	end := n.Line
+	for i := n.Line; i < len(f.lines); i++ {
+		line := f.lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if indentWidth(line) <= indent {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// trailingComment 返回原行中的行尾注释（连同其前面的空白），用于替换值时原样保留
+func trailingComment(line string, n *yaml.Node) string {
+	if n.LineComment == "" {
+		return ""
+	}
+	idx := strings.LastIndex(line, n.LineComment)
+	if idx < 0 {
+		return " " + n.LineComment
+	}
+	start := idx
+	for start > 0 && (line[start-1] == ' ' || line[start-1] == '\t') {
+		start--
+	}
+	if start == idx {
+		return " " + n.LineComment
+	}
+	return line[start:]
+}
+
+// formatScalar 将字符串格式化为单行 YAML 标量，必要时加引号
+func formatScalar(s string) string {
+	b, err := yaml.Marshal(s)
+	out := strings.TrimSuffix(string(b), "\n")
+	if err != nil || strings.Contains(out, "\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return out
+}
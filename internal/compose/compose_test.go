@@ -0,0 +1,373 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleListCompose = `# SWAG stack
+services:
+  swag:
+    image: lscr.io/linuxserver/swag:latest
+    container_name: swag
+    environment:
+      - PUID=1000 # user id
+      - URL=example.com
+      - SUBDOMAINS=www,ftp
+
+      - VALIDATION=http
+    volumes:
+      - ./config:/config
+  other:
+    image: nginx
+`
+
+const sampleMapCompose = `services:
+  proxy:
+    image: lscr.io/linuxserver/swag
+    environment:
+      URL: example.com   # base domain
+      SUBDOMAINS:
+    restart: unless-stopped
+`
+
+func TestFindServicePrefersContainerName(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, sampleListCompose)
+	got, err := f.FindService("swag")
+	if err != nil {
+		t.Fatalf("FindService() error = %v", err)
+	}
+	if got != "swag" {
+		t.Fatalf("FindService() = %s, want swag", got)
+	}
+
+	f = mustParse(t, sampleMapCompose)
+	got, err = f.FindService("something-else")
+	if err != nil {
+		t.Fatalf("FindService() error = %v", err)
+	}
+	if got != "proxy" {
+		t.Fatalf("FindService() = %s, want proxy (matched by image)", got)
+	}
+}
+
+func TestEnvParsesListAndMap(t *testing.T) {
+	t.Parallel()
+
+	env, err := mustParse(t, sampleListCompose).EnvMap("swag")
+	if err != nil {
+		t.Fatalf("EnvMap() error = %v", err)
+	}
+	if env["URL"] != "example.com" || env["SUBDOMAINS"] != "www,ftp" || env["PUID"] != "1000" {
+		t.Fatalf("unexpected env: %v", env)
+	}
+
+	vars, err := mustParse(t, sampleMapCompose).Env("proxy")
+	if err != nil {
+		t.Fatalf("Env() error = %v", err)
+	}
+	if len(vars) != 2 || vars[0].Key != "URL" || vars[1].Key != "SUBDOMAINS" || vars[1].HasValue {
+		t.Fatalf("unexpected env vars: %+v", vars)
+	}
+}
+
+func TestSetEnvListReplacesInPlace(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, sampleListCompose)
+	changed, err := f.SetEnv("swag", "SUBDOMAINS", "www,ftp,app")
+	if err != nil || !changed {
+		t.Fatalf("SetEnv() changed=%v err=%v", changed, err)
+	}
+	want := strings.Replace(sampleListCompose, "SUBDOMAINS=www,ftp", "SUBDOMAINS=www,ftp,app", 1)
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	changed, err = f.SetEnv("swag", "PUID", "1001")
+	if err != nil || !changed {
+		t.Fatalf("SetEnv() changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(string(f.Bytes()), "      - PUID=1001 # user id\n") {
+		t.Fatalf("line comment should be preserved:\n%s", f.Bytes())
+	}
+
+	changed, err = f.SetEnv("swag", "PUID", "1001")
+	if err != nil || changed {
+		t.Fatalf("SetEnv() with same value should be no-op, changed=%v err=%v", changed, err)
+	}
+}
+
+func TestSetEnvListAppends(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, sampleListCompose)
+	if _, err := f.SetEnv("swag", "DOCKER_MODS", "linuxserver/mods:swag-dashboard|linuxserver/mods:swag-maxmind"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	want := strings.Replace(sampleListCompose,
+		"      - VALIDATION=http\n",
+		"      - VALIDATION=http\n      - DOCKER_MODS=linuxserver/mods:swag-dashboard|linuxserver/mods:swag-maxmind\n", 1)
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestSetEnvMap(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, sampleMapCompose)
+	if _, err := f.SetEnv("proxy", "SUBDOMAINS", "wildcard"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	if _, err := f.SetEnv("proxy", "URL", "example.org"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	if _, err := f.SetEnv("proxy", "ONLY_SUBDOMAINS", "true"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+
+	want := `services:
+  proxy:
+    image: lscr.io/linuxserver/swag
+    environment:
+      URL: example.org   # base domain
+      SUBDOMAINS: wildcard
+      ONLY_SUBDOMAINS: "true"
+    restart: unless-stopped
+`
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestSetEnvCreatesEnvironment(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n\n# trailing comment\n")
+	if _, err := f.SetEnv("swag", "URL", "example.com"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	want := "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    environment:\n      - URL=example.com\n\n# trailing comment\n"
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%q", got)
+	}
+}
+
+func TestSetEnvAfterBlockScalar(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, "services:\n  swag:\n    environment:\n      URL: example.com\n      NOTE: |\n        line one\n\n        line two\n    command: >\n      sh -c\n      'run'\nvolumes: {}\n")
+	if _, err := f.SetEnv("swag", "SUBDOMAINS", "wildcard"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	want := "services:\n  swag:\n    environment:\n      URL: example.com\n      NOTE: |\n        line one\n\n        line two\n      SUBDOMAINS: wildcard\n    command: >\n      sh -c\n      'run'\nvolumes: {}\n"
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// 服务的最后一个键为块标量时，新建的 environment 段应在其内容之后
+	f = mustParse(t, "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    command: |\n      echo hi\n      echo bye\n  other:\n    image: nginx\n")
+	if _, err := f.SetEnv("swag", "URL", "example.com"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	want = "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    command: |\n      echo hi\n      echo bye\n    environment:\n      - URL=example.com\n  other:\n    image: nginx\n"
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestSaveWritesBackup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "compose.yaml")
+	if err := os.WriteFile(p, []byte(sampleListCompose), 0o600); err != nil {
+		t.Fatalf("write compose error = %v", err)
+	}
+
+	f, err := Load(p)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := f.SetEnv("swag", "URL", "example.org"); err != nil {
+		t.Fatalf("SetEnv() error = %v", err)
+	}
+	backup, err := f.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	b, _ := os.ReadFile(backup)
+	if string(b) != sampleListCompose {
+		t.Fatalf("backup content mismatch")
+	}
+	b, _ = os.ReadFile(p)
+	if !strings.Contains(string(b), "URL=example.org") {
+		t.Fatalf("saved content not updated:\n%s", b)
+	}
+	for _, path := range []string{p, backup} {
+		if st, err := os.Stat(path); err != nil || st.Mode().Perm() != 0o600 {
+			t.Fatalf("%s mode = %v, %v; want 0600 preserved", path, st.Mode().Perm(), err)
+		}
+	}
+}
+
+func TestProjectParsesServicesNetworksAndVolumes(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, `services:
+  swag:
+    image: lscr.io/linuxserver/swag:latest
+    container_name: swag
+    restart: unless-stopped
+    environment:
+      URL: example.com
+    volumes:
+      - ./config:/config
+      - type: bind
+        source: /etc/localtime
+        target: /etc/localtime
+        read_only: true
+    ports:
+      - 443:443
+      - target: 80
+        published: 80
+        protocol: tcp
+    networks:
+      swag:
+        aliases: [proxy]
+    labels:
+      - com.example.role=proxy
+networks:
+  swag:
+    external: true
+    name: swag-net
+volumes:
+  data: {}
+`)
+
+	p, err := f.Project()
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	if len(p.Services) != 1 {
+		t.Fatalf("unexpected services: %+v", p.Services)
+	}
+	svc := p.Services[0]
+	if svc.ContainerName != "swag" || svc.Restart != "unless-stopped" {
+		t.Fatalf("unexpected service: %+v", svc)
+	}
+	if strings.Join(svc.Volumes, ",") != "./config:/config,/etc/localtime:/etc/localtime:ro" {
+		t.Fatalf("unexpected volumes: %v", svc.Volumes)
+	}
+	if strings.Join(svc.Ports, ",") != "443:443,80:80/tcp" {
+		t.Fatalf("unexpected ports: %v", svc.Ports)
+	}
+	if len(svc.Networks) != 1 || svc.Networks[0] != "swag" {
+		t.Fatalf("unexpected networks: %v", svc.Networks)
+	}
+	if len(svc.Labels) != 1 || svc.Labels[0].Key != "com.example.role" || svc.Labels[0].Value != "proxy" {
+		t.Fatalf("unexpected labels: %+v", svc.Labels)
+	}
+	if len(p.Networks) != 1 || !p.Networks[0].External || p.Networks[0].ActualName != "swag-net" {
+		t.Fatalf("unexpected networks: %+v", p.Networks)
+	}
+	if len(p.Volumes) != 1 || p.Volumes[0] != "data" {
+		t.Fatalf("unexpected volumes: %v", p.Volumes)
+	}
+}
+
+func TestUnsetEnv(t *testing.T) {
+	t.Parallel()
+
+	f := mustParse(t, sampleListCompose)
+	removed, err := f.UnsetEnv("swag", "SUBDOMAINS")
+	if err != nil || !removed {
+		t.Fatalf("UnsetEnv() removed=%v err=%v", removed, err)
+	}
+	want := strings.Replace(sampleListCompose, "      - SUBDOMAINS=www,ftp\n", "", 1)
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	removed, err = f.UnsetEnv("swag", "NOT_SET")
+	if err != nil || removed {
+		t.Fatalf("UnsetEnv() on missing key removed=%v err=%v", removed, err)
+	}
+}
+
+func TestRuntimeResolvesEnvBindsAndNetworks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("DOMAIN=example.com\nTAG=1.2.3\n"), 0o644); err != nil {
+		t.Fatalf("write .env error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "swag.env"), []byte("# secrets\nCF_TOKEN=\"abc\"\nURL=ignored.example\n"), 0o644); err != nil {
+		t.Fatalf("write env_file error = %v", err)
+	}
+
+	f, err := Parse(filepath.Join(dir, "compose.yaml"), []byte(`name: mystack
+services:
+  swag:
+    image: lscr.io/linuxserver/swag:${TAG}
+    env_file: swag.env
+    environment:
+      - URL=${DOMAIN}
+      - SUBDOMAINS=${SUBS:-wildcard}
+      - PRICE=$$5
+    volumes:
+      - ./config:/config
+      - /etc/localtime:/etc/localtime:ro
+      - data:/data
+      - /cache
+    networks:
+      - proxy
+      - ext
+networks:
+  proxy: {}
+  ext:
+    external: true
+volumes:
+  data: {}
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	rt, err := f.Runtime("swag")
+	if err != nil {
+		t.Fatalf("Runtime() error = %v", err)
+	}
+	if rt.Image != "lscr.io/linuxserver/swag:1.2.3" {
+		t.Fatalf("unexpected image: %s", rt.Image)
+	}
+	if got := strings.Join(rt.Env, ","); got != "CF_TOKEN=abc,URL=example.com,SUBDOMAINS=wildcard,PRICE=$5" {
+		t.Fatalf("unexpected env: %s", got)
+	}
+	wantBinds := []string{filepath.Join(dir, "config") + ":/config", "/etc/localtime:/etc/localtime:ro", "mystack_data:/data"}
+	if strings.Join(rt.Binds, ",") != strings.Join(wantBinds, ",") {
+		t.Fatalf("unexpected binds: %v", rt.Binds)
+	}
+	if strings.Join(rt.AnonymousVolumes, ",") != "/cache" {
+		t.Fatalf("unexpected anonymous volumes: %v", rt.AnonymousVolumes)
+	}
+	if strings.Join(rt.Networks, ",") != "mystack_proxy,ext" {
+		t.Fatalf("unexpected networks: %v", rt.Networks)
+	}
+}
+
+func mustParse(t *testing.T, content string) *File {
+	t.Helper()
+
+	f, err := Parse("compose.yaml", []byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return f
+}
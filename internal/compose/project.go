@@ -0,0 +1,213 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyValue 表示 labels 等键值对，保持文件中的顺序
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Service 是 compose 服务定义中 swag-cli 关心的部分
+type Service struct {
+	Name          string
+	Image         string
+	ContainerName string
+	Restart       string
+	Environment   []EnvVar
+	Volumes       []string // 统一为 "source:target[:mode]" 形式
+	Networks      []string
+	Ports         []string // 统一为 "[ip:]published:target[/protocol]" 形式
+	Labels        []KeyValue
+}
+
+// Network 表示顶层 networks 中的一项
+type Network struct {
+	Name     string
+	External bool
+	// ActualName 为 name: 指定的真实网络名（未指定时为空）
+	ActualName string
+}
+
+// Project 表示整个 compose 文件的结构化视图
+type Project struct {
+	Services []Service
+	Networks []Network
+	Volumes  []string
+}
+
+// Project 解析出整个 compose 文件的服务、网络与卷定义
+func (f *File) Project() (Project, error) {
+	var p Project
+	for _, name := range f.ServiceNames() {
+		svc, err := f.Service(name)
+		if err != nil {
+			return Project{}, err
+		}
+		p.Services = append(p.Services, svc)
+	}
+
+	if networks := mappingValue(f.root, "networks"); networks != nil && networks.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(networks.Content); i += 2 {
+			def := networks.Content[i+1]
+			n := Network{
+				Name:       networks.Content[i].Value,
+				ActualName: scalarValue(mappingValue(def, "name")),
+			}
+			if ext := mappingValue(def, "external"); ext != nil {
+				n.External = ext.Kind == yaml.MappingNode || strings.EqualFold(scalarValue(ext), "true")
+				if name := scalarValue(mappingValue(ext, "name")); name != "" && n.ActualName == "" {
+					n.ActualName = name
+				}
+			}
+			p.Networks = append(p.Networks, n)
+		}
+	}
+
+	if volumes := mappingValue(f.root, "volumes"); volumes != nil && volumes.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(volumes.Content); i += 2 {
+			p.Volumes = append(p.Volumes, volumes.Content[i].Value)
+		}
+	}
+	return p, nil
+}
+
+// Service 返回指定服务的结构化定义
+func (f *File) Service(name string) (Service, error) {
+	node := f.service(name)
+	if node == nil {
+		return Service{}, fmt.Errorf("服务不存在: %s", name)
+	}
+
+	env, err := f.Env(name)
+	if err != nil {
+		return Service{}, err
+	}
+
+	svc := Service{
+		Name:          name,
+		Image:         scalarValue(mappingValue(node, "image")),
+		ContainerName: scalarValue(mappingValue(node, "container_name")),
+		Restart:       scalarValue(mappingValue(node, "restart")),
+		Environment:   env,
+	}
+
+	if volumes := mappingValue(node, "volumes"); volumes != nil && volumes.Kind == yaml.SequenceNode {
+		for _, v := range volumes.Content {
+			svc.Volumes = append(svc.Volumes, volumeString(v))
+		}
+	}
+
+	if networks := mappingValue(node, "networks"); networks != nil {
+		switch networks.Kind {
+		case yaml.SequenceNode:
+			for _, n := range networks.Content {
+				svc.Networks = append(svc.Networks, n.Value)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(networks.Content); i += 2 {
+				svc.Networks = append(svc.Networks, networks.Content[i].Value)
+			}
+		}
+	}
+
+	if ports := mappingValue(node, "ports"); ports != nil && ports.Kind == yaml.SequenceNode {
+		for _, p := range ports.Content {
+			svc.Ports = append(svc.Ports, portString(p))
+		}
+	}
+
+	if labels := mappingValue(node, "labels"); labels != nil {
+		switch labels.Kind {
+		case yaml.SequenceNode:
+			for _, l := range labels.Content {
+				k, v, _ := strings.Cut(l.Value, "=")
+				svc.Labels = append(svc.Labels, KeyValue{Key: k, Value: v})
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(labels.Content); i += 2 {
+				svc.Labels = append(svc.Labels, KeyValue{Key: labels.Content[i].Value, Value: labels.Content[i+1].Value})
+			}
+		}
+	}
+
+	return svc, nil
+}
+
+// UnsetEnv 删除服务中的环境变量所在行，返回是否删除
+func (f *File) UnsetEnv(service, key string) (bool, error) {
+	svc := f.service(service)
+	if svc == nil {
+		return false, fmt.Errorf("服务不存在: %s", service)
+	}
+	envNode := mappingValue(svc, "environment")
+	if envNode == nil || len(envNode.Content) == 0 {
+		return false, nil
+	}
+	if envNode.Style&yaml.FlowStyle != 0 {
+		return false, fmt.Errorf("服务 %s 的 environment 使用了 flow 写法，请手动编辑", service)
+	}
+
+	switch envNode.Kind {
+	case yaml.SequenceNode:
+		for _, item := range envNode.Content {
+			k, _, _ := strings.Cut(item.Value, "=")
+			if strings.TrimSpace(k) == key {
+				return true, f.deleteLines(item.Line, f.lastLine(item))
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(envNode.Content); i += 2 {
+			if envNode.Content[i].Value == key {
+				return true, f.deleteLines(envNode.Content[i].Line, f.lastLine(envNode.Content[i+1]))
+			}
+		}
+	}
+	return false, nil
+}
+
+// deleteLines 删除 [from, to] 行（1 起始，闭区间）
+func (f *File) deleteLines(from, to int) error {
+	lines := make([]string, 0, len(f.lines))
+	lines = append(lines, f.lines[:from-1]...)
+	lines = append(lines, f.lines[to:]...)
+	return f.reset(strings.Join(lines, "\n"))
+}
+
+func volumeString(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	source := scalarValue(mappingValue(n, "source"))
+	target := scalarValue(mappingValue(n, "target"))
+	s := target
+	if source != "" {
+		s = source + ":" + target
+	}
+	if strings.EqualFold(scalarValue(mappingValue(n, "read_only")), "true") {
+		s += ":ro"
+	}
+	return s
+}
+
+func portString(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	s := scalarValue(mappingValue(n, "target"))
+	if published := scalarValue(mappingValue(n, "published")); published != "" {
+		s = published + ":" + s
+	}
+	if ip := scalarValue(mappingValue(n, "host_ip")); ip != "" {
+		s = ip + ":" + s
+	}
+	if proto := scalarValue(mappingValue(n, "protocol")); proto != "" {
+		s += "/" + proto
+	}
+	return s
+}
@@ -0,0 +1,230 @@
+package compose
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Runtime 是从 compose 服务定义解析出的、可直接用于创建容器的运行参数
+type Runtime struct {
+	Image string
+	// Env 为 "KEY=VALUE" 列表（已合并 env_file 并完成变量插值），未定义时为空切片而非 nil
+	Env []string
+	// Binds 为 "source:target[:mode]" 列表，相对路径已解析为绝对路径，未定义时为空切片而非 nil
+	Binds []string
+	// AnonymousVolumes 为没有来源的卷定义（如 "/data"）的容器内路径，不能作为 bind 传给 Docker
+	AnonymousVolumes []string
+	// Networks 为 Docker 中实际的网络名（已加上 compose 项目前缀）
+	Networks []string
+}
+
+// ProjectName 返回 compose 项目名：顶层 name > COMPOSE_PROJECT_NAME > 所在目录名
+func (f *File) ProjectName() string {
+	if name := scalarValue(mappingValue(f.root, "name")); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(os.Getenv("COMPOSE_PROJECT_NAME")); name != "" {
+		return name
+	}
+	abs, err := filepath.Abs(f.Path)
+	if err != nil {
+		abs = f.Path
+	}
+	base := strings.ToLower(filepath.Base(filepath.Dir(abs)))
+	return regexp.MustCompile(`[^a-z0-9_-]`).ReplaceAllString(base, "")
+}
+
+// Runtime 解析服务的镜像、环境变量、挂载与网络。
+//
+// 变量插值使用 compose 目录下的 .env 与当前进程环境变量，支持 ${VAR}、${VAR:-default} 与 $VAR。
+func (f *File) Runtime(service string) (Runtime, error) {
+	svc, err := f.Service(service)
+	if err != nil {
+		return Runtime{}, err
+	}
+
+	dir := filepath.Dir(f.Path)
+	vars := dotEnv(filepath.Join(dir, ".env"))
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			vars[k] = v
+		}
+	}
+
+	rt := Runtime{Image: Interpolate(svc.Image, vars), Env: []string{}, Binds: []string{}}
+
+	var env []EnvVar
+	node := f.service(service)
+	if envFiles := mappingValue(node, "env_file"); envFiles != nil {
+		var files []string
+		switch envFiles.Kind {
+		case yaml.ScalarNode:
+			files = append(files, envFiles.Value)
+		case yaml.SequenceNode:
+			for _, n := range envFiles.Content {
+				if n.Kind == yaml.ScalarNode {
+					files = append(files, n.Value)
+				} else {
+					files = append(files, scalarValue(mappingValue(n, "path")))
+				}
+			}
+		}
+		for _, p := range files {
+			if p == "" {
+				continue
+			}
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(dir, p)
+			}
+			if _, err := os.Stat(p); err != nil {
+				return Runtime{}, fmt.Errorf("env_file 不可用 (%s): %w", p, err)
+			}
+			fileVars := dotEnv(p)
+			keys := make([]string, 0, len(fileVars))
+			for k := range fileVars {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				env = append(env, EnvVar{Key: k, Value: fileVars[k], HasValue: true})
+			}
+		}
+	}
+	env = append(env, svc.Environment...)
+
+	seen := make(map[string]int)
+	for _, e := range env {
+		value := Interpolate(e.Value, vars)
+		if !e.HasValue {
+			v, ok := os.LookupEnv(e.Key)
+			if !ok {
+				continue
+			}
+			value = v
+		}
+		kv := e.Key + "=" + value
+		if i, ok := seen[e.Key]; ok {
+			rt.Env[i] = kv
+			continue
+		}
+		seen[e.Key] = len(rt.Env)
+		rt.Env = append(rt.Env, kv)
+	}
+
+	for _, v := range svc.Volumes {
+		v = Interpolate(v, vars)
+		if !strings.Contains(v, ":") {
+			rt.AnonymousVolumes = append(rt.AnonymousVolumes, v)
+			continue
+		}
+		rt.Binds = append(rt.Binds, f.resolveBind(dir, v))
+	}
+
+	project, err := f.Project()
+	if err != nil {
+		return Runtime{}, err
+	}
+	defs := make(map[string]Network)
+	for _, n := range project.Networks {
+		defs[n.Name] = n
+	}
+	networks := svc.Networks
+	if len(networks) == 0 && scalarValue(mappingValue(node, "network_mode")) == "" {
+		networks = []string{"default"}
+	}
+	for _, n := range networks {
+		def, ok := defs[n]
+		switch {
+		case ok && def.ActualName != "":
+			rt.Networks = append(rt.Networks, def.ActualName)
+		case ok && def.External:
+			rt.Networks = append(rt.Networks, n)
+		default:
+			rt.Networks = append(rt.Networks, f.ProjectName()+"_"+n)
+		}
+	}
+	return rt, nil
+}
+
+var interpolateRe = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Interpolate 按 compose 规则替换字符串中的变量引用（$$ 转义为 $）
+func Interpolate(s string, vars map[string]string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	return interpolateRe.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		sub := interpolateRe.FindStringSubmatch(m)
+		name := sub[1]
+		if name == "" {
+			name = sub[3]
+		}
+		if v, ok := vars[name]; ok && (v != "" || !strings.Contains(m, ":-")) {
+			return v
+		}
+		return sub[2]
+	})
+}
+
+// resolveBind 将短语法卷定义中的相对宿主机路径解析为绝对路径，命名卷加上项目前缀（external 或指定 name 的除外）
+func (f *File) resolveBind(dir, spec string) string {
+	source, rest, _ := strings.Cut(spec, ":")
+	switch {
+	case !strings.Contains(source, "/") && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "~"):
+		def := mappingValue(mappingValue(f.root, "volumes"), source)
+		if name := scalarValue(mappingValue(def, "name")); name != "" {
+			source = name
+		} else if scalarValue(mappingValue(def, "external")) != "true" {
+			source = f.ProjectName() + "_" + source
+		}
+	case strings.HasPrefix(source, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			source = filepath.Join(home, source[2:])
+		}
+	case strings.HasPrefix(source, "."):
+		source = filepath.Join(dir, source)
+		if abs, err := filepath.Abs(source); err == nil {
+			source = abs
+		}
+	}
+	return source + ":" + rest
+}
+
+// dotEnv 读取 KEY=VALUE 格式的文件；文件不存在时返回空 map
+func dotEnv(path string) map[string]string {
+	out := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return out
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		out[strings.TrimSpace(k)] = v
+	}
+	return out
+}
@@ -8,21 +8,60 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	SwagDir       string `json:"swagDir"`       // Base SWAG directory path
-	SwagContainer string `json:"swagContainer"` // SWAG container name
-	Network       string `json:"network"`       // Docker network name
+	SwagDir        string `json:"swagDir"`        // Base SWAG directory path
+	SwagContainer  string `json:"swagContainer"`  // SWAG container name
+	Network        string `json:"network"`        // Docker network name
+	WaitTimeout    string `json:"waitTimeout"`    // Timeout waiting for SWAG to become ready, e.g. "90s"
+	ReloadStrategy string `json:"reloadStrategy"` // How changes are applied: auto, reload, restart or none
+
+	BackupDir         string `json:"backupDir"`         // Directory for swag backup archives, empty means <swag-dir>/backups
+	BackupKeepLast    int    `json:"backupKeepLast"`    // Retention: always keep the newest N backups
+	BackupKeepDaily   int    `json:"backupKeepDaily"`   // Retention: keep the newest backup of each of the last N days
+	BackupKeepWeekly  int    `json:"backupKeepWeekly"`  // Retention: keep the newest backup of each of the last N weeks
+	BackupKeepMonthly int    `json:"backupKeepMonthly"` // Retention: keep the newest backup of each of the last N months
 }
 
 func Default() Config {
 	return Config{
-		SwagDir:       "~/apps-docker/swag",
-		SwagContainer: "swag",
-		Network:       "swag",
+		SwagDir:        "~/apps-docker/swag",
+		SwagContainer:  "swag",
+		Network:        "swag",
+		WaitTimeout:    "90s",
+		ReloadStrategy: "auto",
+
+		BackupKeepLast:    7,
+		BackupKeepDaily:   7,
+		BackupKeepWeekly:  4,
+		BackupKeepMonthly: 6,
+	}
+}
+
+// WaitTimeoutDuration returns the parsed wait timeout, falling back to the default on invalid values.
+func (c Config) WaitTimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(c.WaitTimeout)); err == nil && d > 0 {
+		return d
+	}
+	d, _ := time.ParseDuration(Default().WaitTimeout)
+	return d
+}
+
+// BaseDir returns the SWAG base directory with "~" expanded
+func (c Config) BaseDir() string {
+	return expandPath(c.SwagDir)
+}
+
+// BackupDirPath returns the backup directory with "~" expanded, defaulting to <swag-dir>/backups
+func (c Config) BackupDirPath() string {
+	if strings.TrimSpace(c.BackupDir) == "" {
+		return filepath.Join(expandPath(c.SwagDir), "backups")
 	}
+	return expandPath(c.BackupDir)
 }
 
 // ProxyConfsDir returns the path to nginx proxy-confs directory
@@ -141,6 +180,42 @@ func Path() (string, error) {
 	return filepath.Join(baseDir, "swag-cli", "config.json"), nil
 }
 
+// ProfilesPath returns the path of the custom export profiles file, next to config.json
+func ProfilesPath() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "export-profiles.json"), nil
+}
+
+// ChecksPath returns the path of the per-site check expectations file used by `swag-cli test`, next to config.json
+func ChecksPath() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "site-checks.json"), nil
+}
+
+// MonitorPath returns the path of the notifier configuration used by `swag-cli monitor`, next to config.json
+func MonitorPath() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "monitor.json"), nil
+}
+
+// MonitorStatePath returns the path where `swag-cli monitor` keeps site states between runs, next to config.json
+func MonitorStatePath() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "monitor-state.json"), nil
+}
+
 func Load() (Config, error) {
 	p, err := Path()
 	if err != nil {
@@ -301,6 +376,13 @@ func Keys() []string {
 		"swag-dir",
 		"swag-container",
 		"network",
+		"wait-timeout",
+		"reload-strategy",
+		"backup-dir",
+		"backup-keep-last",
+		"backup-keep-daily",
+		"backup-keep-weekly",
+		"backup-keep-monthly",
 	}
 	sort.Strings(keys)
 	return keys
@@ -314,6 +396,20 @@ func Get(cfg Config, key string) (string, bool) {
 		return cfg.SwagContainer, true
 	case "network":
 		return cfg.Network, true
+	case "wait-timeout":
+		return cfg.WaitTimeout, true
+	case "reload-strategy":
+		return cfg.ReloadStrategy, true
+	case "backup-dir":
+		return cfg.BackupDir, true
+	case "backup-keep-last":
+		return strconv.Itoa(cfg.BackupKeepLast), true
+	case "backup-keep-daily":
+		return strconv.Itoa(cfg.BackupKeepDaily), true
+	case "backup-keep-weekly":
+		return strconv.Itoa(cfg.BackupKeepWeekly), true
+	case "backup-keep-monthly":
+		return strconv.Itoa(cfg.BackupKeepMonthly), true
 	default:
 		return "", false
 	}
@@ -334,11 +430,46 @@ func Set(cfg *Config, key, value string) error {
 	case "network":
 		cfg.Network = strings.TrimSpace(value)
 		return nil
+	case "wait-timeout":
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return fmt.Errorf("wait-timeout 格式错误: %s (示例: 90s, 2m)", value)
+		}
+		cfg.WaitTimeout = strings.TrimSpace(value)
+		return nil
+	case "reload-strategy":
+		v := strings.ToLower(strings.TrimSpace(value))
+		switch v {
+		case "auto", "reload", "restart", "none":
+			cfg.ReloadStrategy = v
+			return nil
+		}
+		return fmt.Errorf("reload-strategy 取值错误: %s (可选: auto, reload, restart, none)", value)
+	case "backup-dir":
+		cfg.BackupDir = strings.TrimSpace(value)
+		return nil
+	case "backup-keep-last":
+		return setKeep(&cfg.BackupKeepLast, key, value)
+	case "backup-keep-daily":
+		return setKeep(&cfg.BackupKeepDaily, key, value)
+	case "backup-keep-weekly":
+		return setKeep(&cfg.BackupKeepWeekly, key, value)
+	case "backup-keep-monthly":
+		return setKeep(&cfg.BackupKeepMonthly, key, value)
 	default:
 		return fmt.Errorf("未知配置项: %s", key)
 	}
 }
 
+func setKeep(field *int, key, value string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return fmt.Errorf("%s 需要非负整数: %s", normalizeKey(key), value)
+	}
+	*field = n
+	return nil
+}
+
 func normalizeKey(key string) string {
 	return strings.ToLower(strings.TrimSpace(key))
 }
@@ -347,6 +478,13 @@ func normalize(cfg Config) Config {
 	cfg.SwagDir = strings.TrimSpace(cfg.SwagDir)
 	cfg.SwagContainer = strings.TrimSpace(cfg.SwagContainer)
 	cfg.Network = strings.TrimSpace(cfg.Network)
+	cfg.WaitTimeout = strings.TrimSpace(cfg.WaitTimeout)
+	cfg.ReloadStrategy = strings.ToLower(strings.TrimSpace(cfg.ReloadStrategy))
+	cfg.BackupDir = strings.TrimSpace(cfg.BackupDir)
+	cfg.BackupKeepLast = max(cfg.BackupKeepLast, 0)
+	cfg.BackupKeepDaily = max(cfg.BackupKeepDaily, 0)
+	cfg.BackupKeepWeekly = max(cfg.BackupKeepWeekly, 0)
+	cfg.BackupKeepMonthly = max(cfg.BackupKeepMonthly, 0)
 
 	if cfg.SwagDir == "" {
 		cfg.SwagDir = Default().SwagDir
@@ -357,6 +495,12 @@ func normalize(cfg Config) Config {
 	if cfg.Network == "" {
 		cfg.Network = Default().Network
 	}
+	if cfg.WaitTimeout == "" {
+		cfg.WaitTimeout = Default().WaitTimeout
+	}
+	if cfg.ReloadStrategy == "" {
+		cfg.ReloadStrategy = Default().ReloadStrategy
+	}
 
 	return cfg
 }
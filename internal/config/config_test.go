@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSaveToLoadFromRoundTrip(t *testing.T) {
@@ -88,3 +89,52 @@ func TestExportToImportFromRoundTrip(t *testing.T) {
 	}
 }
 
+func TestSetWaitTimeoutValidatesDuration(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	if err := Set(&cfg, "wait-timeout", "2m"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := cfg.WaitTimeoutDuration(); got != 2*time.Minute {
+		t.Fatalf("WaitTimeoutDuration() = %v, want 2m", got)
+	}
+
+	if err := Set(&cfg, "wait-timeout", "soon"); err == nil {
+		t.Fatalf("Set() should reject invalid duration")
+	}
+	if cfg.WaitTimeout != "2m" {
+		t.Fatalf("invalid value should not be stored: %q", cfg.WaitTimeout)
+	}
+
+	cfg.WaitTimeout = "bogus"
+	if got := cfg.WaitTimeoutDuration(); got != 90*time.Second {
+		t.Fatalf("WaitTimeoutDuration() fallback = %v, want 90s", got)
+	}
+}
+
+func TestSetBackupKeepValidatesCount(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	if err := Set(&cfg, "backup-keep-daily", "14"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if v, _ := Get(cfg, "backup-keep-daily"); v != "14" {
+		t.Fatalf("Get() = %q, want 14", v)
+	}
+
+	for _, bad := range []string{"-1", "many"} {
+		if err := Set(&cfg, "backup-keep-daily", bad); err == nil {
+			t.Fatalf("Set(%q) should be rejected", bad)
+		}
+	}
+	if cfg.BackupKeepDaily != 14 {
+		t.Fatalf("invalid value should not be stored: %d", cfg.BackupKeepDaily)
+	}
+
+	cfg.SwagDir = "/data/swag"
+	if got := cfg.BackupDirPath(); got != filepath.Join("/data/swag", "backups") {
+		t.Fatalf("BackupDirPath() = %q", got)
+	}
+}
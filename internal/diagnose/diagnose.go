@@ -0,0 +1,367 @@
+// Package diagnose 分析 nginx error log 中站点上游（$upstream_app）的错误，结合上游容器状态给出排查建议。
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"swag-cli/internal/nginxlog"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+// Kind 为上游错误类型
+type Kind string
+
+const (
+	KindRefused Kind = "refused"
+	KindResolve Kind = "resolve"
+	KindTimeout Kind = "timeout"
+	KindSSL     Kind = "ssl"
+	KindReset   Kind = "reset"
+	KindOther   Kind = "other"
+)
+
+// Description 返回错误类型的中文说明
+func (k Kind) Description() string {
+	switch k {
+	case KindRefused:
+		return "连接被拒绝"
+	case KindResolve:
+		return "无法解析上游主机名"
+	case KindTimeout:
+		return "上游超时"
+	case KindSSL:
+		return "与上游的 SSL 握手失败"
+	case KindReset:
+		return "上游中断连接"
+	default:
+		return "其他上游错误"
+	}
+}
+
+// Classify 按 error log 中的错误信息判断上游错误类型；与上游无关的错误返回 ""
+func Classify(msg string) Kind {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "could not be resolved"), strings.Contains(lower, "host not found"),
+		strings.Contains(lower, "no resolver defined"):
+		return KindResolve
+	case strings.Contains(lower, "connection refused"):
+		return KindRefused
+	case strings.Contains(lower, "timed out"):
+		return KindTimeout
+	case strings.Contains(lower, "ssl_do_handshake"), strings.Contains(lower, "ssl handshak"),
+		strings.Contains(lower, "upstream ssl certificate"), strings.Contains(lower, "wrong version number"):
+		return KindSSL
+	case strings.Contains(lower, "connection reset by peer"), strings.Contains(lower, "prematurely closed"):
+		return KindReset
+	case strings.Contains(lower, `upstream: "`), strings.Contains(lower, "no live upstreams"):
+		return KindOther
+	}
+	return ""
+}
+
+// Docker 为诊断所需的 Docker 操作，由 *docker.Client 实现
+type Docker interface {
+	InspectContainer(ctx context.Context, name string) (*types.ContainerJSON, error)
+}
+
+// Target 为要诊断的站点
+type Target struct {
+	Site        string
+	ServerNames []string
+	// App 与 Port 为站点配置中的 $upstream_app 与 $upstream_port
+	App  string
+	Port string
+	// Container 为上游容器名，上游不是容器（IP、静态站点）时为空
+	Container string
+}
+
+// Options 为诊断参数
+type Options struct {
+	Target Target
+	// Files 为要读取的 error log（主机路径）；Site 非空表示站点单独的日志，其中的上游错误都属于该站点
+	Files []nginxlog.LogFile
+	Since time.Time
+	// Docker 为 nil 时不检查容器状态
+	Docker        Docker
+	SwagContainer string
+}
+
+// Issue 为一类上游错误的汇总
+type Issue struct {
+	Kind   Kind      `json:"kind"`
+	Count  int       `json:"count"`
+	First  time.Time `json:"first"`
+	Last   time.Time `json:"last"`
+	Sample string    `json:"sample"`
+}
+
+// Container 为上游容器的状态
+type Container struct {
+	Name         string   `json:"name"`
+	Found        bool     `json:"found"`
+	Status       string   `json:"status,omitempty"`
+	Running      bool     `json:"running"`
+	Restarting   bool     `json:"restarting,omitempty"`
+	RestartCount int      `json:"restartCount,omitempty"`
+	ExitCode     int      `json:"exitCode,omitempty"`
+	OOMKilled    bool     `json:"oomKilled,omitempty"`
+	Health       string   `json:"health,omitempty"`
+	NetworkMode  string   `json:"networkMode,omitempty"`
+	Networks     []string `json:"networks,omitempty"`
+	IPs          []string `json:"ips,omitempty"`
+	ExposedPorts []string `json:"exposedPorts,omitempty"`
+}
+
+// Report 为诊断结果
+type Report struct {
+	Site     string   `json:"site"`
+	Upstream string   `json:"upstream"`
+	Files    []string `json:"files"`
+	Issues   []Issue  `json:"issues"`
+	// Container 为 nil 表示未检查（上游不是容器或未连接 Docker）
+	Container    *Container `json:"container,omitempty"`
+	SwagNetworks []string   `json:"swagNetworks,omitempty"`
+	Hints        []string   `json:"hints"`
+}
+
+// Run 检查上游容器状态、汇总 error log 中的上游错误并生成排查建议
+func Run(ctx context.Context, opts Options) (Report, error) {
+	t := opts.Target
+	rep := Report{Site: t.Site, Upstream: upstreamString(t)}
+
+	if opts.Docker != nil && t.Container != "" {
+		c, err := inspect(ctx, opts.Docker, t.Container)
+		if err != nil {
+			return rep, err
+		}
+		rep.Container = c
+		if opts.SwagContainer != "" {
+			swag, err := inspect(ctx, opts.Docker, opts.SwagContainer)
+			if err != nil {
+				return rep, err
+			}
+			rep.SwagNetworks = swag.Networks
+		}
+	}
+
+	var ips []string
+	if rep.Container != nil {
+		ips = rep.Container.IPs
+	}
+	if err := scan(&rep, opts, ips); err != nil {
+		return rep, err
+	}
+	rep.Hints = Hints(rep, t)
+	return rep, nil
+}
+
+func upstreamString(t Target) string {
+	if t.App == "" {
+		return ""
+	}
+	if t.Port == "" {
+		return t.App
+	}
+	return net.JoinHostPort(t.App, t.Port)
+}
+
+func inspect(ctx context.Context, d Docker, name string) (*Container, error) {
+	info, err := d.InspectContainer(ctx, name)
+	if errdefs.IsNotFound(err) {
+		return &Container{Name: name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取容器 %s 信息失败: %w", name, err)
+	}
+	c := &Container{Name: name, Found: true}
+	if info.ContainerJSONBase != nil {
+		c.RestartCount = info.RestartCount
+		if s := info.State; s != nil {
+			c.Status = s.Status
+			c.Running = s.Running
+			c.Restarting = s.Restarting
+			c.ExitCode = s.ExitCode
+			c.OOMKilled = s.OOMKilled
+			if s.Health != nil {
+				c.Health = s.Health.Status
+			}
+		}
+		if info.HostConfig != nil {
+			c.NetworkMode = string(info.HostConfig.NetworkMode)
+		}
+	}
+	if info.NetworkSettings != nil {
+		for name, ep := range info.NetworkSettings.Networks {
+			c.Networks = append(c.Networks, name)
+			if ep != nil && ep.IPAddress != "" {
+				c.IPs = append(c.IPs, ep.IPAddress)
+			}
+		}
+	}
+	if info.Config != nil {
+		for p := range info.Config.ExposedPorts {
+			c.ExposedPorts = append(c.ExposedPorts, p.Port())
+		}
+	}
+	sort.Strings(c.Networks)
+	sort.Strings(c.IPs)
+	sort.Strings(c.ExposedPorts)
+	return c, nil
+}
+
+var upstreamFieldRe = regexp.MustCompile(`upstream: "[a-z]+://([^/"]+)`)
+
+// matchEntry 判断 error log 行是否属于站点：上游地址为 $upstream_app（或容器 IP）、解析失败的主机名为 $upstream_app，或主机名匹配 server_name
+func matchEntry(e nginxlog.Entry, t Target, ips []string) bool {
+	if m := upstreamFieldRe.FindStringSubmatch(e.Message); m != nil {
+		host := m[1]
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host == t.App || contains(ips, host) {
+			return true
+		}
+	}
+	if t.App != "" && strings.Contains(e.Message, t.App+" could not be resolved") {
+		return true
+	}
+	return nginxlog.MatchServerNames(t.ServerNames, e.Host)
+}
+
+func scan(rep *Report, opts Options, ips []string) error {
+	issues := map[Kind]*Issue{}
+	for _, lf := range opts.Files {
+		st, err := os.Stat(lf.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && !opts.Since.IsZero() && st.ModTime().Before(opts.Since) {
+			continue
+		}
+		rep.Files = append(rep.Files, lf.Path)
+
+		err = nginxlog.ScanLog(lf.Path, func(line string) {
+			e, ok := nginxlog.ParseError(line)
+			if !ok {
+				return
+			}
+			if !opts.Since.IsZero() && e.Time.Before(opts.Since) {
+				return
+			}
+			kind := Classify(e.Message)
+			if kind == "" {
+				return
+			}
+			if lf.Site == "" && !matchEntry(e, opts.Target, ips) {
+				return
+			}
+			is, ok := issues[kind]
+			if !ok {
+				is = &Issue{Kind: kind, First: e.Time}
+				issues[kind] = is
+			}
+			is.Count++
+			if e.Time.Before(is.First) {
+				is.First = e.Time
+			}
+			if !e.Time.Before(is.Last) {
+				is.Last = e.Time
+				is.Sample = line
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, is := range issues {
+		rep.Issues = append(rep.Issues, *is)
+	}
+	sort.Slice(rep.Issues, func(i, j int) bool {
+		if rep.Issues[i].Count != rep.Issues[j].Count {
+			return rep.Issues[i].Count > rep.Issues[j].Count
+		}
+		return rep.Issues[i].Kind < rep.Issues[j].Kind
+	})
+	return nil
+}
+
+// Hints 按容器状态与错误类型生成排查建议
+func Hints(rep Report, t Target) []string {
+	var hints []string
+	c := rep.Container
+	if c != nil {
+		switch {
+		case !c.Found:
+			hints = append(hints, fmt.Sprintf("未找到容器 %s：确认 $upstream_app 与容器名一致，或先启动该容器", c.Name))
+		case c.Restarting:
+			hints = append(hints, fmt.Sprintf("容器 %s 正在反复重启（已重启 %d 次）：查看 docker logs %s", c.Name, c.RestartCount, c.Name))
+		case !c.Running:
+			h := fmt.Sprintf("容器 %s 未运行（%s，退出码 %d）：docker start %s，并用 docker logs %s 查看退出原因", c.Name, c.Status, c.ExitCode, c.Name, c.Name)
+			if c.OOMKilled {
+				h += "；容器曾因内存不足被终止"
+			}
+			hints = append(hints, h)
+		case c.Health == "unhealthy":
+			hints = append(hints, fmt.Sprintf("容器 %s 健康检查失败 (unhealthy)：docker inspect --format '{{json .State.Health}}' %s 查看检查结果", c.Name, c.Name))
+		}
+		if c.Found && len(rep.SwagNetworks) > 0 && c.NetworkMode != "host" && !shareNetwork(c.Networks, rep.SwagNetworks) {
+			hints = append(hints, fmt.Sprintf("容器 %s 与 SWAG 不在同一网络（%s vs %s）：docker network connect %s %s",
+				c.Name, strings.Join(c.Networks, ","), strings.Join(rep.SwagNetworks, ","), rep.SwagNetworks[0], c.Name))
+		}
+	}
+
+	for _, is := range rep.Issues {
+		switch is.Kind {
+		case KindResolve:
+			hints = append(hints, fmt.Sprintf("nginx 无法解析 %s：确认容器名拼写与 $upstream_app 一致，且两者在同一自定义网络（默认 bridge 网络不提供容器名解析）", t.App))
+		case KindRefused:
+			h := fmt.Sprintf("上游拒绝连接：确认服务在端口 %s 上监听，且监听 0.0.0.0 而不是 127.0.0.1", orDash(t.Port))
+			if c != nil && len(c.ExposedPorts) > 0 && t.Port != "" && !contains(c.ExposedPorts, t.Port) {
+				h += fmt.Sprintf("；容器暴露的端口为 %s，检查 $upstream_port", strings.Join(c.ExposedPorts, ","))
+			}
+			hints = append(hints, h)
+		case KindTimeout:
+			hints = append(hints, "上游响应超时：检查服务负载与容器日志；确有长请求时可在站点配置中调大 proxy_read_timeout")
+		case KindSSL:
+			hints = append(hints, "与上游的 SSL 握手失败：上游只提供 http 时 $upstream_proto 应为 http（wrong version number 通常是此原因）；上游为 https 时确认端口正确")
+		case KindReset:
+			hints = append(hints, "上游中断连接：通常是服务崩溃或正在重启，查看上游容器日志")
+		}
+	}
+	return hints
+}
+
+func shareNetwork(a, b []string) bool {
+	for _, n := range a {
+		if contains(b, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
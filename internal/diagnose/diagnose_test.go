@@ -0,0 +1,198 @@
+package diagnose
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"swag-cli/internal/nginxlog"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+)
+
+const errorLog = `2026/10/18 09:00:00 [error] 31#31: *1 connect() failed (111: Connection refused) while connecting to upstream, client: 203.0.113.7, server: app.example.com, request: "GET / HTTP/1.1", upstream: "http://172.20.0.5:8080/", host: "app.example.com"
+2026/10/18 09:05:00 [error] 31#31: *2 app could not be resolved (3: Host not found), client: 203.0.113.7, server: app.example.com, request: "GET / HTTP/1.1", host: "app.example.com"
+2026/10/18 09:10:00 [error] 31#31: *3 upstream timed out (110: Operation timed out) while reading response header from upstream, client: 203.0.113.7, server: _, request: "GET /api HTTP/1.1", upstream: "http://app:8080/api", host: "10.0.0.2"
+2026/10/18 09:15:00 [error] 31#31: *4 connect() failed (111: Connection refused) while connecting to upstream, client: 203.0.113.7, server: other.example.com, request: "GET / HTTP/1.1", upstream: "http://172.20.0.9:3000/", host: "other.example.com"
+2026/10/18 09:20:00 [error] 31#31: *5 open() "/config/www/favicon.ico" failed (2: No such file or directory), client: 203.0.113.7, server: app.example.com, request: "GET /favicon.ico HTTP/1.1", host: "app.example.com"
+2026/10/18 09:25:00 [error] 31#31: *6 connect() failed (111: Connection refused) while connecting to upstream, client: 203.0.113.8, server: app.example.com, request: "GET / HTTP/1.1", upstream: "http://172.20.0.5:8080/", host: "app.example.com"
+`
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Kind{
+		`*1 connect() failed (111: Connection refused) while connecting to upstream`:                                  KindRefused,
+		`*1 app could not be resolved (3: Host not found)`:                                                            KindResolve,
+		`*1 no resolver defined to resolve app`:                                                                       KindResolve,
+		`*1 upstream timed out (110: Connection timed out) while reading response header from upstream`:               KindTimeout,
+		`*1 SSL_do_handshake() failed (SSL: error:0A00010B:SSL routines::wrong version number) while SSL handshaking`: KindSSL,
+		`*1 recv() failed (104: Connection reset by peer) while reading response header from upstream`:                KindReset,
+		`*1 upstream sent too big header while reading response header from upstream, upstream: "http://app:80/"`:     KindOther,
+		`*1 open() "/config/www/favicon.ico" failed (2: No such file or directory)`:                                   "",
+	}
+	for msg, want := range tests {
+		if got := Classify(msg); got != want {
+			t.Errorf("Classify(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestRunSharedLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "error.log")
+	mustWriteLog(t, path, errorLog)
+
+	d := fakeDocker{
+		"app":  runningContainer("other_net", "172.20.0.5", "8080/tcp"),
+		"swag": runningContainer("proxy", "172.30.0.2", "443/tcp"),
+	}
+	rep, err := Run(context.Background(), Options{
+		Target:        Target{Site: "app", ServerNames: []string{"app.*"}, App: "app", Port: "8080", Container: "app"},
+		Files:         []nginxlog.LogFile{{Path: path}, {Path: filepath.Join(dir, "error.log.1")}},
+		Docker:        d,
+		SwagContainer: "swag",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	counts := map[Kind]int{}
+	for _, is := range rep.Issues {
+		counts[is.Kind] = is.Count
+	}
+	want := map[Kind]int{KindRefused: 2, KindResolve: 1, KindTimeout: 1}
+	if len(counts) != len(want) {
+		t.Fatalf("issues = %+v, want %v", rep.Issues, want)
+	}
+	for k, n := range want {
+		if counts[k] != n {
+			t.Fatalf("issues[%s] = %d, want %d", k, counts[k], n)
+		}
+	}
+	if rep.Issues[0].Kind != KindRefused || !strings.Contains(rep.Issues[0].Sample, "203.0.113.8") {
+		t.Fatalf("first issue = %+v, want latest refused sample", rep.Issues[0])
+	}
+	if len(rep.Files) != 1 {
+		t.Fatalf("Files = %v, want only existing file", rep.Files)
+	}
+	if !hasHint(rep.Hints, "docker network connect proxy app") {
+		t.Fatalf("Hints = %v, want network connect hint", rep.Hints)
+	}
+}
+
+func TestRunSiteLogAndSince(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.error.log")
+	mustWriteLog(t, path, errorLog)
+
+	since := time.Date(2026, 10, 18, 9, 12, 0, 0, time.Local)
+	rep, err := Run(context.Background(), Options{
+		Target: Target{Site: "app", App: "app", Port: "8080"},
+		Files:  []nginxlog.LogFile{{Path: path, Site: "app"}},
+		Since:  since,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// 站点单独日志中的上游错误都属于该站点，不按上游地址过滤
+	if len(rep.Issues) != 1 || rep.Issues[0].Kind != KindRefused || rep.Issues[0].Count != 2 {
+		t.Fatalf("issues = %+v, want 2 refused", rep.Issues)
+	}
+	if rep.Container != nil {
+		t.Fatalf("Container = %+v, want nil without docker", rep.Container)
+	}
+}
+
+func TestRunContainerState(t *testing.T) {
+	t.Parallel()
+
+	exited := runningContainer("proxy", "", "3000/tcp")
+	exited.State.Running = false
+	exited.State.Status = "exited"
+	exited.State.ExitCode = 137
+	exited.State.OOMKilled = true
+
+	tests := []struct {
+		name   string
+		docker fakeDocker
+		want   string
+	}{
+		{"missing", fakeDocker{}, "未找到容器 app"},
+		{"exited", fakeDocker{"app": exited}, "docker start app"},
+	}
+	for _, tt := range tests {
+		rep, err := Run(context.Background(), Options{
+			Target: Target{Site: "app", App: "app", Port: "8080", Container: "app"},
+			Docker: tt.docker,
+		})
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", tt.name, err)
+		}
+		if !hasHint(rep.Hints, tt.want) {
+			t.Fatalf("%s: Hints = %v, want %q", tt.name, rep.Hints, tt.want)
+		}
+	}
+}
+
+func TestHintsExposedPort(t *testing.T) {
+	t.Parallel()
+
+	rep := Report{
+		Container: &Container{Name: "app", Found: true, Running: true, ExposedPorts: []string{"3000"}},
+		Issues:    []Issue{{Kind: KindRefused, Count: 1}},
+	}
+	hints := Hints(rep, Target{App: "app", Port: "8080"})
+	if !hasHint(hints, "容器暴露的端口为 3000") {
+		t.Fatalf("Hints = %v, want exposed port hint", hints)
+	}
+}
+
+type fakeDocker map[string]*types.ContainerJSON
+
+func (f fakeDocker) InspectContainer(ctx context.Context, name string) (*types.ContainerJSON, error) {
+	if c, ok := f[name]; ok {
+		return c, nil
+	}
+	return nil, errdefs.NotFound(errors.New("No such container: " + name))
+}
+
+func runningContainer(net, ip, port string) *types.ContainerJSON {
+	return &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State:      &types.ContainerState{Status: "running", Running: true},
+			HostConfig: &container.HostConfig{NetworkMode: container.NetworkMode(net)},
+		},
+		Config: &container.Config{ExposedPorts: nat.PortSet{nat.Port(port): {}}},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{net: {IPAddress: ip}},
+		},
+	}
+}
+
+func hasHint(hints []string, s string) bool {
+	for _, h := range hints {
+		if strings.Contains(h, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustWriteLog(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
@@ -72,6 +72,11 @@ func NewClient() (*Client, error) {
 	return nil, fmt.Errorf("failed to connect to docker daemon: %w", errPing)
 }
 
+// Close 关闭 Docker API 客户端及其连接
+func (c *Client) Close() error {
+	return c.cli.Close()
+}
+
 // ListContainersByNetwork 列出指定网络中的所有容器
 // networkName: 目标网络名称，通常是 "swag" 或用户自定义的名称
 func (c *Client) ListContainersByNetwork(ctx context.Context, networkName string) ([]ContainerInfo, error) {
@@ -164,6 +169,15 @@ func (c *Client) InspectContainer(ctx context.Context, name string) (*types.Cont
 	return &resp, nil
 }
 
+// InspectImage 读取本地镜像信息（RepoDigests、镜像内置的 ENV 等）
+func (c *Client) InspectImage(ctx context.Context, ref string) (*types.ImageInspect, error) {
+	resp, _, err := c.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // Exec executes a command inside a running container and returns the output
 func (c *Client) Exec(ctx context.Context, containerName string, cmd []string) (string, error) {
 	execConfig := types.ExecConfig{
@@ -194,12 +208,23 @@ func (c *Client) Exec(ctx context.Context, containerName string, cmd []string) (
 	}
 
 	if execInspect.ExitCode != 0 {
-		return "", fmt.Errorf("command failed (exit code %d): %s | %s", execInspect.ExitCode, outBuf.String(), errBuf.String())
+		return "", &ExecError{ExitCode: execInspect.ExitCode, Stdout: outBuf.String(), Stderr: errBuf.String()}
 	}
 
 	return outBuf.String(), nil
 }
 
+// ExecError 表示容器内命令执行成功但以非 0 状态退出
+type ExecError struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("command failed (exit code %d): %s | %s", e.ExitCode, e.Stdout, e.Stderr)
+}
+
 // RestartContainer 重启指定容器
 func (c *Client) RestartContainer(ctx context.Context, containerName string) error {
 	return c.cli.ContainerRestart(ctx, containerName, container.StopOptions{})
@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DefaultReadyTimeout 为等待 SWAG 就绪的默认超时时间
+const DefaultReadyTimeout = 90 * time.Second
+
+// WaitReady 等待 SWAG 容器真正可用：
+//  1. 容器处于运行状态（定义了 healthcheck 时需为 healthy）；
+//  2. 容器内 nginx -t 通过；
+//  3. /run/nginx.pid 指向的 nginx master 进程存在。
+//
+// SWAG 启动时会先执行 init 脚本（申请证书等），nginx 可能要数十秒后才启动，因此各项检查会重试直到超时。
+func (c *Client) WaitReady(ctx context.Context, name string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		err := c.checkReady(ctx, name)
+		if err == nil {
+			return nil
+		}
+		var fatal *fatalError
+		if errors.As(err, &fatal) {
+			return fatal.err
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待 SWAG 就绪超时 (%s): %w", timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// fatalError 表示无需再重试的失败（容器已退出等）
+type fatalError struct{ err error }
+
+func (e *fatalError) Error() string { return e.err.Error() }
+
+func (c *Client) checkReady(ctx context.Context, name string) error {
+	info, err := c.cli.ContainerInspect(ctx, name)
+	if err != nil {
+		return &fatalError{fmt.Errorf("读取容器状态失败: %w", err)}
+	}
+	state := info.State
+	if state == nil {
+		return errors.New("容器状态未知")
+	}
+	if !state.Running {
+		if state.Status == "exited" || state.Status == "dead" {
+			return &fatalError{fmt.Errorf("容器已退出 (exit code %d) %s", state.ExitCode, state.Error)}
+		}
+		return fmt.Errorf("容器状态: %s", state.Status)
+	}
+	if state.Health != nil {
+		switch state.Health.Status {
+		case types.Unhealthy:
+			return &fatalError{errors.New("容器 healthcheck 状态为 unhealthy")}
+		case types.Starting:
+			return errors.New("healthcheck 尚未通过")
+		}
+	}
+
+	if err := c.TestNginx(ctx, name); err != nil {
+		return err
+	}
+	if _, err := c.Exec(ctx, name, []string{"sh", "-c", `pid=$(cat /run/nginx.pid 2>/dev/null) && [ -n "$pid" ] && [ -d "/proc/$pid" ]`}); err != nil {
+		return errors.New("nginx master 进程尚未运行")
+	}
+	return nil
+}
+
+// ErrNginxConfig 表示 nginx -t 报告配置有误（而非无法执行检查）
+var ErrNginxConfig = errors.New("nginx 配置检查未通过")
+
+// TestNginx 在容器内执行 nginx -t 检查配置；配置有误时返回的错误可用 errors.Is(err, ErrNginxConfig) 判断
+func (c *Client) TestNginx(ctx context.Context, name string) error {
+	_, err := c.Exec(ctx, name, []string{"nginx", "-t"})
+	var execErr *ExecError
+	if errors.As(err, &execErr) {
+		return fmt.Errorf("%w: %s", ErrNginxConfig, strings.TrimSpace(execErr.Stderr+execErr.Stdout))
+	}
+	if err != nil {
+		return fmt.Errorf("无法执行 nginx -t: %w", err)
+	}
+	return nil
+}
+
+// StartupLogs 将容器自本次启动以来的日志写入 w（stdout 与 stderr 合并输出）
+func (c *Client) StartupLogs(ctx context.Context, name string, w io.Writer) error {
+	opts := container.LogsOptions{ShowStdout: true, ShowStderr: true}
+	tty := false
+	if info, err := c.cli.ContainerInspect(ctx, name); err == nil {
+		if info.State != nil && !strings.HasPrefix(info.State.StartedAt, "0001-") {
+			opts.Since = info.State.StartedAt
+		}
+		tty = info.Config != nil && info.Config.Tty
+	}
+
+	rc, err := c.cli.ContainerLogs(ctx, name, opts)
+	if err != nil {
+		return fmt.Errorf("读取容器日志失败: %w", err)
+	}
+	defer rc.Close()
+
+	// 未分配 TTY 时日志为多路复用格式，需要拆分
+	if tty {
+		_, err = io.Copy(w, rc)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, rc)
+	}
+	if err != nil {
+		return fmt.Errorf("读取容器日志失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+// RecreateOptions 描述重建容器时对原配置的覆盖，nil/空值表示沿用容器当前配置
+type RecreateOptions struct {
+	Image    string
+	Env      []string // 非 nil 时替换容器环境变量（镜像内置的 ENV 由 Docker 自动合并）
+	Binds    []string // 非 nil 时替换 bind/volume 挂载（tmpfs 等其他挂载保留）
+	Networks []string // 非 nil 时替换所连接的网络
+	Pull     bool     // 创建前拉取镜像（镜像不存在时总会拉取）
+
+	// AnonymousVolumes 为匿名卷的容器内路径（与 Binds 一起生效），沿用旧容器在同一路径上的卷以保留数据
+	AnonymousVolumes []string
+
+	// HealthTimeout 为等待新容器就绪的超时时间，<=0 时使用默认值
+	HealthTimeout time.Duration
+	// Progress 用于输出进度信息，可为 nil
+	Progress func(msg string)
+	// LogWriter 非 nil 时，新容器未能就绪会先把其启动日志写入该 Writer 再回滚
+	LogWriter io.Writer
+}
+
+// RecreateResult 描述重建结果
+type RecreateResult struct {
+	OldID      string
+	NewID      string
+	RolledBack bool
+}
+
+// RecreateContainer 以容器当前 inspect 数据为基础（叠加 opts 中的覆盖项）重建容器：
+// 停止旧容器并重命名保留 -> 用同名创建新容器并启动 -> 等待就绪 -> 删除旧容器。
+// 新容器启动失败或未能就绪时，删除新容器并恢复启动旧容器。
+func (c *Client) RecreateContainer(ctx context.Context, name string, opts RecreateOptions) (RecreateResult, error) {
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(string) {}
+	}
+	timeout := opts.HealthTimeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+
+	old, err := c.cli.ContainerInspect(ctx, name)
+	if err != nil {
+		return RecreateResult{}, fmt.Errorf("读取容器信息失败 (%s): %w", name, err)
+	}
+	res := RecreateResult{OldID: old.ID}
+	containerName := strings.TrimPrefix(old.Name, "/")
+
+	cfg, hostCfg, networks := recreateSpec(old, opts)
+
+	if err := c.ensureImage(ctx, cfg.Image, opts.Pull, progress); err != nil {
+		return res, err
+	}
+
+	progress(fmt.Sprintf("停止容器 %s", containerName))
+	if err := c.cli.ContainerStop(ctx, old.ID, container.StopOptions{}); err != nil {
+		return res, fmt.Errorf("停止容器失败: %w", err)
+	}
+
+	backupName := fmt.Sprintf("%s-swag-cli-old-%s", containerName, time.Now().Format("20060102150405"))
+	if err := c.cli.ContainerRename(ctx, old.ID, backupName); err != nil {
+		_ = c.cli.ContainerStart(ctx, old.ID, container.StartOptions{})
+		return res, fmt.Errorf("重命名旧容器失败: %w", err)
+	}
+
+	rollback := func(cause error) (RecreateResult, error) {
+		progress("新容器未能就绪，正在回滚到旧容器")
+		if res.NewID != "" {
+			_ = c.cli.ContainerRemove(context.Background(), res.NewID, container.RemoveOptions{Force: true})
+		}
+		if err := c.cli.ContainerRename(context.Background(), old.ID, containerName); err != nil {
+			return res, fmt.Errorf("%w；回滚失败（旧容器保留为 %s）: %v", cause, backupName, err)
+		}
+		if err := c.cli.ContainerStart(context.Background(), old.ID, container.StartOptions{}); err != nil {
+			return res, fmt.Errorf("%w；回滚后启动旧容器失败: %v", cause, err)
+		}
+		res.RolledBack = true
+		return res, cause
+	}
+
+	progress(fmt.Sprintf("创建新容器 %s", containerName))
+	var netCfg *network.NetworkingConfig
+	if len(networks) > 0 {
+		netCfg = &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+			networks[0].name: networks[0].settings,
+		}}
+	}
+	created, err := c.cli.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, containerName)
+	if err != nil {
+		return rollback(fmt.Errorf("创建容器失败: %w", err))
+	}
+	res.NewID = created.ID
+
+	for _, n := range networks[min(1, len(networks)):] {
+		if err := c.cli.NetworkConnect(ctx, n.name, created.ID, n.settings); err != nil {
+			return rollback(fmt.Errorf("连接网络 %s 失败: %w", n.name, err))
+		}
+	}
+
+	progress(fmt.Sprintf("启动新容器 %s", containerName))
+	if err := c.cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return rollback(fmt.Errorf("启动容器失败: %w", err))
+	}
+
+	progress("等待容器就绪")
+	if err := c.WaitReady(ctx, created.ID, timeout); err != nil {
+		if opts.LogWriter != nil {
+			progress("新容器启动日志:")
+			_ = c.StartupLogs(context.Background(), created.ID, opts.LogWriter)
+		}
+		return rollback(err)
+	}
+
+	if err := c.cli.ContainerRemove(ctx, old.ID, container.RemoveOptions{}); err != nil {
+		progress(fmt.Sprintf("警告: 删除旧容器 %s 失败: %v", backupName, err))
+	}
+	return res, nil
+}
+
+type endpoint struct {
+	name     string
+	settings *network.EndpointSettings
+}
+
+// recreateSpec 基于 inspect 数据构造新容器的创建参数
+func recreateSpec(old types.ContainerJSON, opts RecreateOptions) (*container.Config, *container.HostConfig, []endpoint) {
+	cfg := *old.Config
+	if cfg.Hostname == shortID(old.ID) {
+		cfg.Hostname = ""
+	}
+	if opts.Image != "" {
+		cfg.Image = opts.Image
+	}
+	if opts.Env != nil {
+		cfg.Env = append([]string(nil), opts.Env...)
+	}
+
+	hostCfg := *old.HostConfig
+	if opts.Binds != nil {
+		hostCfg.Binds = append([]string(nil), opts.Binds...)
+		var kept []mount.Mount
+		for _, m := range hostCfg.Mounts {
+			if m.Type != mount.TypeBind && m.Type != mount.TypeVolume {
+				kept = append(kept, m)
+			}
+		}
+		for _, target := range opts.AnonymousVolumes {
+			m := mount.Mount{Type: mount.TypeVolume, Target: target}
+			for _, mp := range old.Mounts {
+				if mp.Type == mount.TypeVolume && mp.Destination == target {
+					m.Source = mp.Name
+					break
+				}
+			}
+			kept = append(kept, m)
+		}
+		hostCfg.Mounts = kept
+	}
+
+	var endpoints []endpoint
+	if old.NetworkSettings != nil {
+		for name, s := range old.NetworkSettings.Networks {
+			if s == nil {
+				continue
+			}
+			endpoints = append(endpoints, endpoint{name: name, settings: &network.EndpointSettings{
+				IPAMConfig: s.IPAMConfig,
+				Links:      s.Links,
+				Aliases:    withoutID(s.Aliases, old.ID),
+				MacAddress: s.MacAddress,
+			}})
+		}
+		// map 遍历顺序不固定，排序以保证每次选出相同的主网络
+		sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].name < endpoints[j].name })
+	}
+	if opts.Networks != nil {
+		existing := make(map[string]endpoint, len(endpoints))
+		for _, e := range endpoints {
+			existing[e.name] = e
+		}
+		endpoints = nil
+		for _, name := range opts.Networks {
+			if e, ok := existing[name]; ok {
+				endpoints = append(endpoints, e)
+				continue
+			}
+			endpoints = append(endpoints, endpoint{name: name, settings: &network.EndpointSettings{}})
+		}
+	}
+
+	// 原 NetworkMode 仍在连接的网络中时保留它并作为创建时的网络，否则以首个网络作为 NetworkMode
+	mode := hostCfg.NetworkMode
+	if len(endpoints) > 0 && !mode.IsHost() && !mode.IsNone() && !mode.IsContainer() {
+		primary := string(mode)
+		if mode.IsDefault() {
+			primary = network.NetworkBridge
+		}
+		if i := endpointIndex(endpoints, primary); i >= 0 {
+			endpoints = append([]endpoint{endpoints[i]}, append(endpoints[:i:i], endpoints[i+1:]...)...)
+		} else {
+			hostCfg.NetworkMode = container.NetworkMode(endpoints[0].name)
+		}
+	}
+	return &cfg, &hostCfg, endpoints
+}
+
+func endpointIndex(endpoints []endpoint, name string) int {
+	for i, e := range endpoints {
+		if e.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Client) ensureImage(ctx context.Context, ref string, pull bool, progress func(string)) error {
+	if !pull {
+		if _, _, err := c.cli.ImageInspectWithRaw(ctx, ref); err == nil {
+			return nil
+		}
+	}
+	progress(fmt.Sprintf("拉取镜像 %s", ref))
+	rc, err := c.cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("拉取镜像失败 (%s): %w", ref, err)
+	}
+	defer rc.Close()
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		return fmt.Errorf("拉取镜像失败 (%s): %w", ref, err)
+	}
+	return nil
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func withoutID(aliases []string, id string) []string {
+	var out []string
+	for _, a := range aliases {
+		if a == shortID(id) || a == id {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
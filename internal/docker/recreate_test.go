@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+func TestRecreateSpecKeepsPrimaryNetwork(t *testing.T) {
+	t.Parallel()
+
+	inspect := func(mode string, networks ...string) types.ContainerJSON {
+		settings := map[string]*network.EndpointSettings{}
+		for _, n := range networks {
+			settings[n] = &network.EndpointSettings{}
+		}
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:         "abc",
+				HostConfig: &container.HostConfig{NetworkMode: container.NetworkMode(mode)},
+			},
+			Config:          &container.Config{Image: "lscr.io/linuxserver/swag"},
+			NetworkSettings: &types.NetworkSettings{Networks: settings},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		old       types.ContainerJSON
+		wantMode  string
+		wantFirst string
+	}{
+		{"custom primary kept", inspect("swag", "zeta", "swag", "alpha"), "swag", "swag"},
+		{"default mode kept", inspect("default", "swag", "bridge"), "default", "bridge"},
+		{"primary not attached", inspect("gone", "zeta", "alpha", "swag"), "alpha", "alpha"},
+	}
+	for _, tt := range tests {
+		// 多次运行结果应一致（map 遍历顺序不固定）
+		for i := 0; i < 10; i++ {
+			_, hostCfg, endpoints := recreateSpec(tt.old, RecreateOptions{})
+			if string(hostCfg.NetworkMode) != tt.wantMode || endpoints[0].name != tt.wantFirst || len(endpoints) != len(tt.old.NetworkSettings.Networks) {
+				t.Fatalf("%s: NetworkMode = %q, endpoints[0] = %q (%d), want %q, %q", tt.name, hostCfg.NetworkMode, endpoints[0].name, len(endpoints), tt.wantMode, tt.wantFirst)
+			}
+		}
+	}
+}
+
+func TestRecreateSpecReusesAnonymousVolumes(t *testing.T) {
+	t.Parallel()
+
+	old := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         "abc",
+			HostConfig: &container.HostConfig{Binds: []string{"/old:/config"}},
+		},
+		Mounts: []types.MountPoint{
+			{Type: mount.TypeBind, Source: "/old", Destination: "/config"},
+			{Type: mount.TypeVolume, Name: "3f2a9c", Destination: "/cache"},
+		},
+		Config: &container.Config{Image: "lscr.io/linuxserver/swag"},
+	}
+	_, hostCfg, _ := recreateSpec(old, RecreateOptions{
+		Binds:            []string{"/srv/swag:/config"},
+		AnonymousVolumes: []string{"/cache", "/tmp/new"},
+	})
+	if len(hostCfg.Binds) != 1 || hostCfg.Binds[0] != "/srv/swag:/config" {
+		t.Fatalf("Binds = %v", hostCfg.Binds)
+	}
+	want := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "3f2a9c", Target: "/cache"},
+		{Type: mount.TypeVolume, Target: "/tmp/new"},
+	}
+	if !reflect.DeepEqual(hostCfg.Mounts, want) {
+		t.Fatalf("Mounts = %+v, want %+v", hostCfg.Mounts, want)
+	}
+}
@@ -0,0 +1,237 @@
+// Package fail2ban 通过在 SWAG 容器内执行 fail2ban-client 查询 jail 状态、封禁与解封 IP。
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"swag-cli/internal/docker"
+)
+
+// Docker 是 Client 依赖的容器操作，由 *docker.Client 实现
+type Docker interface {
+	Exec(ctx context.Context, containerName string, cmd []string) (string, error)
+}
+
+// Client 在指定容器内执行 fail2ban-client
+type Client struct {
+	Docker    Docker
+	Container string
+}
+
+// Status 为 fail2ban-client status 的结果
+type Status struct {
+	Jails []string `json:"jails"`
+}
+
+// JailStatus 为 fail2ban-client status <jail> 的结果
+type JailStatus struct {
+	Name            string   `json:"name"`
+	CurrentlyFailed int      `json:"currentlyFailed"`
+	TotalFailed     int      `json:"totalFailed"`
+	Files           []string `json:"files,omitempty"`
+	CurrentlyBanned int      `json:"currentlyBanned"`
+	TotalBanned     int      `json:"totalBanned"`
+	BannedIPs       []string `json:"bannedIPs"`
+}
+
+// ErrNoJail 表示指定的 jail 不存在
+var ErrNoJail = errors.New("jail 不存在")
+
+// Status 返回已启用的 jail 列表
+func (c Client) Status(ctx context.Context) (Status, error) {
+	out, err := c.run(ctx, "status")
+	if err != nil {
+		return Status{}, err
+	}
+	return ParseStatus(out)
+}
+
+// Jail 返回单个 jail 的统计与封禁列表
+func (c Client) Jail(ctx context.Context, name string) (JailStatus, error) {
+	out, err := c.run(ctx, "status", name)
+	if err != nil {
+		var ee *docker.ExecError
+		if errors.As(err, &ee) && strings.Contains(ee.Stdout+ee.Stderr, "does not exist") {
+			return JailStatus{}, fmt.Errorf("%w: %s", ErrNoJail, name)
+		}
+		return JailStatus{}, err
+	}
+	return ParseJailStatus(out)
+}
+
+// Jails 返回所有 jail 的状态，按名称排序
+func (c Client) Jails(ctx context.Context) ([]JailStatus, error) {
+	st, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]JailStatus, 0, len(st.Jails))
+	for _, name := range st.Jails {
+		js, err := c.Jail(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, js)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Ban 在指定 jail 中封禁 IP
+func (c Client) Ban(ctx context.Context, jail, ip string) error {
+	if err := checkIP(ip); err != nil {
+		return err
+	}
+	if strings.TrimSpace(jail) == "" {
+		return errors.New("封禁 IP 需要指定 jail")
+	}
+	if _, err := c.Jail(ctx, jail); err != nil {
+		return err
+	}
+	_, err := c.run(ctx, "set", jail, "banip", ip)
+	return err
+}
+
+// Unban 解封 IP：jail 为空时从所有 jail 解封，返回实际解封的 jail
+func (c Client) Unban(ctx context.Context, jail, ip string) ([]string, error) {
+	if err := checkIP(ip); err != nil {
+		return nil, err
+	}
+
+	var jails []JailStatus
+	if strings.TrimSpace(jail) != "" {
+		js, err := c.Jail(ctx, jail)
+		if err != nil {
+			return nil, err
+		}
+		jails = []JailStatus{js}
+	} else {
+		all, err := c.Jails(ctx)
+		if err != nil {
+			return nil, err
+		}
+		jails = all
+	}
+
+	var unbanned []string
+	for _, js := range jails {
+		if !js.Banned(ip) {
+			continue
+		}
+		if _, err := c.run(ctx, "set", js.Name, "unbanip", ip); err != nil {
+			return unbanned, err
+		}
+		unbanned = append(unbanned, js.Name)
+	}
+	return unbanned, nil
+}
+
+// Banned 判断 IP 是否在该 jail 的封禁列表中
+func (j JailStatus) Banned(ip string) bool {
+	for _, b := range j.BannedIPs {
+		if b == ip {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Client) run(ctx context.Context, args ...string) (string, error) {
+	out, err := c.exec(ctx, append([]string{"fail2ban-client"}, args...))
+	if err != nil {
+		var ee *docker.ExecError
+		if errors.As(err, &ee) {
+			msg := strings.TrimSpace(ee.Stderr)
+			if msg == "" {
+				msg = strings.TrimSpace(ee.Stdout)
+			}
+			if strings.Contains(msg, "Failed to access socket") || strings.Contains(msg, "Is fail2ban running") {
+				return "", fmt.Errorf("fail2ban 未运行 (%s): %w", c.Container, err)
+			}
+		}
+		return "", fmt.Errorf("执行 fail2ban-client %s 失败: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+func checkIP(ip string) error {
+	if _, err := netip.ParseAddr(strings.TrimSpace(ip)); err != nil {
+		if _, err := netip.ParsePrefix(strings.TrimSpace(ip)); err != nil {
+			return fmt.Errorf("无效 IP: %s", ip)
+		}
+	}
+	return nil
+}
+
+// ParseStatus 解析 fail2ban-client status 的输出
+func ParseStatus(out string) (Status, error) {
+	fields := parseFields(out)
+	list, ok := fields["jail list"]
+	if !ok {
+		return Status{}, fmt.Errorf("无法解析 fail2ban-client status 输出: %q", strings.TrimSpace(out))
+	}
+	st := Status{Jails: splitList(list)}
+	sort.Strings(st.Jails)
+	return st, nil
+}
+
+// ParseJailStatus 解析 fail2ban-client status <jail> 的输出
+func ParseJailStatus(out string) (JailStatus, error) {
+	var js JailStatus
+	for _, line := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "Status for the jail:"); ok {
+			js.Name = strings.TrimSpace(name)
+		}
+	}
+	if js.Name == "" {
+		return JailStatus{}, fmt.Errorf("无法解析 fail2ban-client status <jail> 输出: %q", strings.TrimSpace(out))
+	}
+
+	fields := parseFields(out)
+	var err error
+	for key, dst := range map[string]*int{
+		"currently failed": &js.CurrentlyFailed,
+		"total failed":     &js.TotalFailed,
+		"currently banned": &js.CurrentlyBanned,
+		"total banned":     &js.TotalBanned,
+	} {
+		v, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if *dst, err = strconv.Atoi(v); err != nil {
+			return JailStatus{}, fmt.Errorf("解析 %s 失败 (%s): %w", key, js.Name, err)
+		}
+	}
+	js.Files = splitList(fields["file list"])
+	js.BannedIPs = splitList(fields["banned ip list"])
+	if js.BannedIPs == nil {
+		js.BannedIPs = []string{}
+	}
+	return js, nil
+}
+
+// parseFields 解析 "|- Key:\tvalue" 形式的行，key 转为小写
+func parseFields(out string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimLeft(line, " |`-")
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return fields
+}
+
+// splitList 拆分以逗号或空白分隔的列表
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+}
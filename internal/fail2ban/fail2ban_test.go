@@ -0,0 +1,132 @@
+package fail2ban
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"swag-cli/internal/docker"
+)
+
+const statusOut = `Status
+|- Number of jail:	3
+` + "`" + `- Jail list:	nginx-http-auth, nginx-badbots, nginx-botsearch
+`
+
+const jailOut = `Status for the jail: nginx-http-auth
+|- Filter
+|  |- Currently failed:	2
+|  |- Total failed:	17
+|  ` + "`" + `- File list:	/config/log/nginx/error.log
+` + "`" + `- Actions
+   |- Currently banned:	2
+   |- Total banned:	5
+   ` + "`" + `- Banned IP list:	203.0.113.7 2001:db8::1
+`
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	st, err := ParseStatus(statusOut)
+	if err != nil {
+		t.Fatalf("ParseStatus() error = %v", err)
+	}
+	want := []string{"nginx-badbots", "nginx-botsearch", "nginx-http-auth"}
+	if !reflect.DeepEqual(st.Jails, want) {
+		t.Fatalf("Jails = %v, want %v", st.Jails, want)
+	}
+
+	if _, err := ParseStatus("garbage"); err == nil {
+		t.Fatalf("ParseStatus(garbage) should fail")
+	}
+}
+
+func TestParseJailStatus(t *testing.T) {
+	t.Parallel()
+
+	js, err := ParseJailStatus(jailOut)
+	if err != nil {
+		t.Fatalf("ParseJailStatus() error = %v", err)
+	}
+	want := JailStatus{
+		Name:            "nginx-http-auth",
+		CurrentlyFailed: 2,
+		TotalFailed:     17,
+		Files:           []string{"/config/log/nginx/error.log"},
+		CurrentlyBanned: 2,
+		TotalBanned:     5,
+		BannedIPs:       []string{"203.0.113.7", "2001:db8::1"},
+	}
+	if !reflect.DeepEqual(js, want) {
+		t.Fatalf("ParseJailStatus() = %+v, want %+v", js, want)
+	}
+
+	empty, err := ParseJailStatus(strings.Replace(jailOut, "203.0.113.7 2001:db8::1", "", 1))
+	if err != nil || empty.BannedIPs == nil || len(empty.BannedIPs) != 0 {
+		t.Fatalf("ParseJailStatus(empty) = %+v, %v", empty, err)
+	}
+}
+
+func TestUnbanFromAllJails(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeDocker{outputs: map[string]string{
+		"status":                 "Status\n`- Jail list:\tnginx-http-auth, sshd\n",
+		"status nginx-http-auth": jailOut,
+		"status sshd":            "Status for the jail: sshd\n`- Actions\n   `- Banned IP list:\t\n",
+		"set nginx-http-auth unbanip 203.0.113.7": "1",
+	}}
+	c := Client{Docker: fake, Container: "swag"}
+
+	jails, err := c.Unban(context.Background(), "", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("Unban() error = %v", err)
+	}
+	if !reflect.DeepEqual(jails, []string{"nginx-http-auth"}) {
+		t.Fatalf("Unban() = %v", jails)
+	}
+	if got := fake.calls[len(fake.calls)-1]; got != "set nginx-http-auth unbanip 203.0.113.7" {
+		t.Fatalf("last call = %q", got)
+	}
+
+	if _, err := c.Unban(context.Background(), "", "not-an-ip"); err == nil {
+		t.Fatalf("Unban(not-an-ip) should fail")
+	}
+}
+
+func TestBanRequiresExistingJail(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeDocker{outputs: map[string]string{}}
+	c := Client{Docker: fake, Container: "swag"}
+
+	if err := c.Ban(context.Background(), "", "203.0.113.7"); err == nil {
+		t.Fatalf("Ban() without jail should fail")
+	}
+	err := c.Ban(context.Background(), "nope", "203.0.113.7")
+	if !errors.Is(err, ErrNoJail) {
+		t.Fatalf("Ban() error = %v, want ErrNoJail", err)
+	}
+	for _, call := range fake.calls {
+		if strings.Contains(call, "banip") {
+			t.Fatalf("unexpected call %q", call)
+		}
+	}
+}
+
+type fakeDocker struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (f *fakeDocker) Exec(ctx context.Context, containerName string, cmd []string) (string, error) {
+	key := strings.Join(cmd[1:], " ")
+	f.calls = append(f.calls, key)
+	out, ok := f.outputs[key]
+	if !ok {
+		return "", &docker.ExecError{ExitCode: 255, Stderr: "Sorry but the jail '" + cmd[len(cmd)-1] + "' does not exist"}
+	}
+	return out, nil
+}
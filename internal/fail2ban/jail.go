@@ -0,0 +1,333 @@
+package fail2ban
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"swag-cli/internal/nginxlog"
+)
+
+// DefaultLogPath 为 SWAG 默认的 nginx access log（容器内路径）
+const DefaultLogPath = nginxlog.DefaultAccessLog
+
+// containerDir 为 Fail2banDir() 在 SWAG 容器内的路径
+const containerDir = "/config/fail2ban"
+
+// Preset 为常见应用的登录失败过滤规则，匹配 nginx access log 中的请求
+type Preset struct {
+	Name        string
+	Description string
+	FailRegex   []string
+	IgnoreRegex []string
+}
+
+// Presets 为内置的过滤规则
+var Presets = []Preset{
+	{
+		Name:        "vaultwarden",
+		Description: "Vaultwarden/Bitwarden 登录失败（POST /identity/connect/token 返回 400）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "POST /identity/connect/token HTTP/[0-9.]+" 400 `},
+	},
+	{
+		Name:        "nextcloud",
+		Description: "Nextcloud WebDAV/OCS 认证失败（401）与登录表单失败",
+		FailRegex: []string{
+			`^<HOST> - \S+ \[[^\]]+\] "[A-Z]+ /(?:remote\.php|ocs)/\S* HTTP/[0-9.]+" 401 `,
+			`^<HOST> - \S+ \[[^\]]+\] "POST /(?:index\.php/)?login HTTP/[0-9.]+" 200 `,
+		},
+	},
+	{
+		Name:        "jellyfin",
+		Description: "Jellyfin/Emby 登录失败（POST /Users/AuthenticateByName 返回 401）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "POST /(?:emby/)?Users/AuthenticateByName\S* HTTP/[0-9.]+" 401 `},
+	},
+	{
+		Name:        "gitea",
+		Description: "Gitea/Forgejo 登录失败（POST /user/login 返回 200，成功时为 303）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "POST /user/login\S* HTTP/[0-9.]+" 200 `},
+	},
+	{
+		Name:        "grafana",
+		Description: "Grafana 登录失败（POST /login 返回 401）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "POST /login HTTP/[0-9.]+" 401 `},
+	},
+	{
+		Name:        "immich",
+		Description: "Immich 登录失败（POST /api/auth/login 返回 401）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "POST /api/auth/login HTTP/[0-9.]+" 401 `},
+	},
+	{
+		Name:        "http-401",
+		Description: "任意请求返回 401（适用于 basic auth 或其他返回 401 的登录接口）",
+		FailRegex:   []string{`^<HOST> - \S+ \[[^\]]+\] "[A-Z]+ [^"]*" 401 `},
+	},
+}
+
+// LookupPreset 按名称查找内置过滤规则
+func LookupPreset(name string) (Preset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	names := make([]string, 0, len(Presets))
+	for _, p := range Presets {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Preset{}, fmt.Errorf("未知的过滤规则: %s (可选: %s)", name, strings.Join(names, ", "))
+}
+
+// JailSpec 描述要添加的 jail
+type JailSpec struct {
+	// Name 为 jail 名，同时用作过滤规则文件名
+	Name     string
+	Preset   Preset
+	LogPaths []string
+	MaxRetry int
+	FindTime time.Duration
+	BanTime  time.Duration
+}
+
+// JailFiles 为写入的文件路径
+type JailFiles struct {
+	FilterPath string
+	JailPath   string
+	// Replaced 表示 jail.local 中已有 swag-cli 写入的同名 jail，已被替换
+	Replaced bool
+}
+
+var jailNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// JailName 由站点名生成 jail 名
+func JailName(site string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(site))
+	n = strings.NewReplacer(".", "-", " ", "-").Replace(n)
+	if !jailNameRe.MatchString(n) {
+		return "", fmt.Errorf("无效的站点名: %q", site)
+	}
+	return n, nil
+}
+
+// FilterContent 生成 filter.d/<jail>.local 的内容
+func FilterContent(spec JailSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# swag-cli: %s (preset %s)\n", spec.Preset.Description, spec.Preset.Name)
+	b.WriteString("[Definition]\n")
+	writeList(&b, "failregex", spec.Preset.FailRegex)
+	writeList(&b, "ignoreregex", spec.Preset.IgnoreRegex)
+	return b.String()
+}
+
+// JailContent 生成 jail.local 中 swag-cli 管理的 jail 段落（含起止标记）
+func JailContent(spec JailSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", beginMarker, spec.Name)
+	fmt.Fprintf(&b, "[%s]\n", spec.Name)
+	b.WriteString("enabled  = true\n")
+	b.WriteString("port     = http,https\n")
+	fmt.Fprintf(&b, "filter   = %s\n", spec.Name)
+	writeList(&b, "logpath ", spec.LogPaths)
+	fmt.Fprintf(&b, "maxretry = %d\n", spec.MaxRetry)
+	fmt.Fprintf(&b, "findtime = %d\n", int(spec.FindTime.Seconds()))
+	fmt.Fprintf(&b, "bantime  = %d\n", int(spec.BanTime.Seconds()))
+	fmt.Fprintf(&b, "%s %s\n", endMarker, spec.Name)
+	return b.String()
+}
+
+const (
+	beginMarker = "# BEGIN swag-cli jail"
+	endMarker   = "# END swag-cli jail"
+)
+
+func writeList(b *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		fmt.Fprintf(b, "%s =\n", key)
+		return
+	}
+	fmt.Fprintf(b, "%s = %s\n", key, values[0])
+	for _, v := range values[1:] {
+		fmt.Fprintf(b, "%s   %s\n", strings.Repeat(" ", len(key)), v)
+	}
+}
+
+func (s JailSpec) validate() error {
+	if !jailNameRe.MatchString(s.Name) {
+		return fmt.Errorf("无效的 jail 名: %q", s.Name)
+	}
+	if len(s.Preset.FailRegex) == 0 {
+		return errors.New("过滤规则为空")
+	}
+	if len(s.LogPaths) == 0 {
+		return errors.New("未指定日志文件")
+	}
+	if s.MaxRetry <= 0 || s.FindTime < time.Second || s.BanTime < time.Second {
+		return errors.New("maxretry/findtime/bantime 必须大于 0")
+	}
+	return nil
+}
+
+// WriteJail 写入 filter.d/<jail>.local，并在 jail.local 中添加（或替换 swag-cli 写入的）同名 jail
+func WriteJail(fail2banDir string, spec JailSpec) (JailFiles, error) {
+	if err := spec.validate(); err != nil {
+		return JailFiles{}, err
+	}
+	files := JailFiles{
+		FilterPath: filepath.Join(fail2banDir, "filter.d", spec.Name+".local"),
+		JailPath:   filepath.Join(fail2banDir, "jail.local"),
+	}
+
+	var current string
+	if b, err := os.ReadFile(files.JailPath); err == nil {
+		current = string(b)
+	} else if !os.IsNotExist(err) {
+		return files, fmt.Errorf("读取 jail.local 失败: %w", err)
+	}
+	updated, replaced, err := upsertJail(current, spec)
+	if err != nil {
+		return files, err
+	}
+	files.Replaced = replaced
+
+	if err := os.MkdirAll(filepath.Dir(files.FilterPath), 0o755); err != nil {
+		return files, fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := writeFileAtomic(files.FilterPath, []byte(FilterContent(spec))); err != nil {
+		return files, err
+	}
+	if err := writeFileAtomic(files.JailPath, []byte(updated)); err != nil {
+		return files, err
+	}
+	return files, nil
+}
+
+// upsertJail 在 jail.local 内容中添加或替换 swag-cli 管理的 jail；同名 jail 由用户手动定义时返回错误
+func upsertJail(content string, spec JailSpec) (string, bool, error) {
+	begin := beginMarker + " " + spec.Name + "\n"
+	end := endMarker + " " + spec.Name + "\n"
+	block := JailContent(spec)
+
+	if i := strings.Index(content, begin); i >= 0 {
+		j := strings.Index(content[i:], end)
+		if j < 0 {
+			return "", false, fmt.Errorf("jail.local 中 %s 的标记不完整，请手动检查", spec.Name)
+		}
+		return content[:i] + block + content[i+j+len(end):], true, nil
+	}
+
+	for _, name := range sectionNames(content) {
+		if strings.EqualFold(name, spec.Name) {
+			return "", false, fmt.Errorf("jail.local 中已存在 jail [%s]（非 swag-cli 添加），请换用其他名称", spec.Name)
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block, false, nil
+}
+
+func sectionNames(content string) []string {
+	var out []string
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			out = append(out, strings.TrimSpace(line[1:len(line)-1]))
+		}
+	}
+	return out
+}
+
+// SiteLogPaths 返回站点配置中 access_log 指定的日志文件（容器内路径），未指定时为 SWAG 默认的 access.log
+func SiteLogPaths(confPath string) ([]string, error) {
+	sc, err := nginxlog.ParseSiteConf(confPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(sc.AccessLogs) == 0 {
+		return []string{DefaultLogPath}, nil
+	}
+	return sc.AccessLogs, nil
+}
+
+// RegexResult 为 fail2ban-regex 对单个日志文件的测试结果
+type RegexResult struct {
+	LogPath string `json:"logPath"`
+	Lines   int    `json:"lines"`
+	Matched int    `json:"matched"`
+}
+
+var regexLinesRe = regexp.MustCompile(`Lines:\s*(\d+) lines,\s*(\d+) ignored,\s*(\d+) matched`)
+
+// TestFilter 在容器内用 fail2ban-regex 测试过滤规则对日志文件的匹配情况（不写入任何文件）
+func (c Client) TestFilter(ctx context.Context, logPath string, p Preset) (RegexResult, error) {
+	res := RegexResult{LogPath: logPath}
+	for _, re := range p.FailRegex {
+		args := []string{"fail2ban-regex", logPath, re}
+		if len(p.IgnoreRegex) > 0 {
+			args = append(args, strings.Join(p.IgnoreRegex, "|"))
+		}
+		out, err := c.exec(ctx, args)
+		if err != nil {
+			return res, fmt.Errorf("执行 fail2ban-regex 失败 (%s): %w", logPath, err)
+		}
+		lines, matched, err := ParseRegexOutput(out)
+		if err != nil {
+			return res, err
+		}
+		res.Lines = lines
+		res.Matched += matched
+	}
+	return res, nil
+}
+
+// ParseRegexOutput 解析 fail2ban-regex 输出中的总行数与匹配行数
+func ParseRegexOutput(out string) (lines int, matched int, err error) {
+	m := regexLinesRe.FindStringSubmatch(out)
+	if m == nil {
+		return 0, 0, fmt.Errorf("无法解析 fail2ban-regex 输出: %q", strings.TrimSpace(out))
+	}
+	lines, _ = strconv.Atoi(m[1])
+	matched, _ = strconv.Atoi(m[3])
+	return lines, matched, nil
+}
+
+// ApplyJail 将 swag-dir 中的过滤规则与 jail.local 复制到容器的 /etc/fail2ban 并重新加载 fail2ban
+// （SWAG 只在容器启动时复制 /config/fail2ban 中的配置）
+func (c Client) ApplyJail(ctx context.Context, name string) error {
+	script := fmt.Sprintf("cp %s/filter.d/%s.local /etc/fail2ban/filter.d/ && cp %s/jail.local /etc/fail2ban/jail.local",
+		containerDir, name, containerDir)
+	if _, err := c.exec(ctx, []string{"sh", "-c", script}); err != nil {
+		return fmt.Errorf("复制 fail2ban 配置到容器失败: %w", err)
+	}
+	_, err := c.run(ctx, "reload")
+	return err
+}
+
+func (c Client) exec(ctx context.Context, cmd []string) (string, error) {
+	if strings.TrimSpace(c.Container) == "" {
+		return "", errors.New("未指定 SWAG 容器名称")
+	}
+	return c.Docker.Exec(ctx, c.Container, cmd)
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("写入失败 (%s): %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入失败 (%s): %w", path, err)
+	}
+	return nil
+}
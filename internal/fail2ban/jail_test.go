@@ -0,0 +1,165 @@
+package fail2ban
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJailAddsAndReplacesManagedBlock(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	userJails := "[DEFAULT]\nbantime = 600\n\n[nginx-http-auth]\nenabled = true\n"
+	mustWrite(t, filepath.Join(dir, "jail.local"), userJails)
+
+	spec := testSpec(t, "vaultwarden")
+	files, err := WriteJail(dir, spec)
+	if err != nil {
+		t.Fatalf("WriteJail() error = %v", err)
+	}
+	if files.Replaced {
+		t.Fatalf("Replaced = true for a new jail")
+	}
+
+	filter := mustRead(t, files.FilterPath)
+	if !strings.Contains(filter, "[Definition]") || !strings.Contains(filter, "failregex = ^<HOST>") {
+		t.Fatalf("filter = %q", filter)
+	}
+	jail := mustRead(t, files.JailPath)
+	if !strings.HasPrefix(jail, userJails) {
+		t.Fatalf("user jails changed:\n%s", jail)
+	}
+	for _, want := range []string{"[vaultwarden]", "filter   = vaultwarden", "logpath  = /config/log/nginx/vault.access.log", "findtime = 600", "bantime  = 3600"} {
+		if !strings.Contains(jail, want) {
+			t.Fatalf("jail.local missing %q:\n%s", want, jail)
+		}
+	}
+
+	spec.MaxRetry = 3
+	files, err = WriteJail(dir, spec)
+	if err != nil || !files.Replaced {
+		t.Fatalf("WriteJail() again = %+v, %v", files, err)
+	}
+	jail = mustRead(t, files.JailPath)
+	if strings.Count(jail, "[vaultwarden]") != 1 || !strings.Contains(jail, "maxretry = 3") {
+		t.Fatalf("jail.local after replace:\n%s", jail)
+	}
+
+	if _, err := WriteJail(dir, testSpec(t, "nginx-http-auth")); err == nil {
+		t.Fatalf("WriteJail() should refuse to override a user-defined jail")
+	}
+}
+
+func TestPresetsCompileAndMatch(t *testing.T) {
+	t.Parallel()
+
+	samples := map[string]string{
+		"vaultwarden": `203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "POST /identity/connect/token HTTP/2.0" 400 123 "-" "Bitwarden"`,
+		"nextcloud":   `203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "PROPFIND /remote.php/dav/files/u HTTP/1.1" 401 0 "-" "curl"`,
+		"jellyfin":    `203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "POST /Users/AuthenticateByName HTTP/1.1" 401 0 "-" "-"`,
+		"http-401":    `203.0.113.7 - alice [01/Jan/2026:10:00:00 +0000] "GET /admin HTTP/1.1" 401 0 "-" "-"`,
+	}
+	host := strings.NewReplacer("<HOST>", `(?P<host>\S+)`)
+	for _, p := range Presets {
+		matched := false
+		for _, fr := range p.FailRegex {
+			re, err := regexp.Compile(host.Replace(fr))
+			if err != nil {
+				t.Fatalf("preset %s: %v", p.Name, err)
+			}
+			if s, ok := samples[p.Name]; ok && re.MatchString(s) {
+				matched = true
+			}
+		}
+		if _, ok := samples[p.Name]; ok && !matched {
+			t.Fatalf("preset %s does not match sample %q", p.Name, samples[p.Name])
+		}
+	}
+
+	if _, err := LookupPreset("nope"); err == nil || !strings.Contains(err.Error(), "vaultwarden") {
+		t.Fatalf("LookupPreset(nope) error = %v", err)
+	}
+}
+
+func TestSiteLogPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "vault.subdomain.conf")
+	mustWrite(t, conf, "server {\n    access_log /config/log/nginx/vault.access.log;\n    # access_log /x.log;\n    access_log off;\n}\n")
+	got, err := SiteLogPaths(conf)
+	if err != nil || len(got) != 1 || got[0] != "/config/log/nginx/vault.access.log" {
+		t.Fatalf("SiteLogPaths() = %v, %v", got, err)
+	}
+
+	mustWrite(t, conf, "server {\n}\n")
+	got, err = SiteLogPaths(conf)
+	if err != nil || len(got) != 1 || got[0] != DefaultLogPath {
+		t.Fatalf("SiteLogPaths(default) = %v, %v", got, err)
+	}
+}
+
+func TestFilterTestParsesFail2banRegex(t *testing.T) {
+	t.Parallel()
+
+	preset, err := LookupPreset("nextcloud")
+	if err != nil {
+		t.Fatalf("LookupPreset() error = %v", err)
+	}
+	out := "Results\n=======\n\nFailregex: 2 total\n\nLines: 120 lines, 0 ignored, 2 matched, 118 missed\n[processed in 0.01 sec]\n"
+	fake := &fakeDocker{outputs: map[string]string{}}
+	for _, fr := range preset.FailRegex {
+		fake.outputs["/config/log/nginx/access.log "+fr] = out
+	}
+
+	res, err := Client{Docker: fake, Container: "swag"}.TestFilter(context.Background(), DefaultLogPath, preset)
+	if err != nil {
+		t.Fatalf("TestFilter() error = %v", err)
+	}
+	if res.Lines != 120 || res.Matched != 4 {
+		t.Fatalf("TestFilter() = %+v", res)
+	}
+}
+
+func testSpec(t *testing.T, name string) JailSpec {
+	t.Helper()
+
+	p, err := LookupPreset("vaultwarden")
+	if err != nil {
+		t.Fatalf("LookupPreset() error = %v", err)
+	}
+	return JailSpec{
+		Name:     name,
+		Preset:   p,
+		LogPaths: []string{"/config/log/nginx/vault.access.log"},
+		MaxRetry: 5,
+		FindTime: 10 * time.Minute,
+		BanTime:  time.Hour,
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read error = %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,124 @@
+// Package mods 管理 SWAG 的 DOCKER_MODS 环境变量（以 "|" 分隔的 mod 镜像列表）。
+package mods
+
+import (
+	"strings"
+)
+
+// Mod 描述一个已知的 SWAG docker mod
+type Mod struct {
+	Name        string // 简称，如 dashboard
+	Image       string // 完整镜像，如 linuxserver/mods:swag-dashboard
+	Description string
+}
+
+// Known 为常用的 SWAG mods
+var Known = []Mod{
+	{Name: "auto-proxy", Image: "linuxserver/mods:swag-auto-proxy", Description: "根据容器 label 自动生成反向代理配置"},
+	{Name: "auto-reload", Image: "linuxserver/mods:swag-auto-reload", Description: "检测到 nginx 配置变更后自动 reload"},
+	{Name: "auto-uptime-kuma", Image: "linuxserver/mods:swag-auto-uptime-kuma", Description: "根据代理配置自动创建 Uptime Kuma 监控"},
+	{Name: "cloudflare-real-ip", Image: "linuxserver/mods:swag-cloudflare-real-ip", Description: "经 Cloudflare 代理时还原真实客户端 IP"},
+	{Name: "crowdsec", Image: "linuxserver/mods:swag-crowdsec", Description: "接入 CrowdSec bouncer 拦截恶意 IP"},
+	{Name: "dashboard", Image: "linuxserver/mods:swag-dashboard", Description: "SWAG 仪表盘（流量、证书、fail2ban 概览）"},
+	{Name: "dbip", Image: "linuxserver/mods:swag-dbip", Description: "DB-IP 地理位置数据库，可用于按国家限制访问"},
+	{Name: "maxmind", Image: "linuxserver/mods:swag-maxmind", Description: "MaxMind GeoIP2 数据库（需 MAXMINDDB_LICENSE_KEY）"},
+	{Name: "ondemand", Image: "linuxserver/mods:swag-ondemand", Description: "按需启动/停止被代理的容器"},
+	{Name: "universal-docker", Image: "linuxserver/mods:universal-docker", Description: "在容器内提供 docker CLI（auto-proxy 等 mod 依赖）"},
+}
+
+// Parse 解析 DOCKER_MODS 的值
+func Parse(value string) []string {
+	var out []string
+	for _, p := range strings.Split(value, "|") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Format 将 mod 列表格式化为 DOCKER_MODS 的值
+func Format(list []string) string {
+	return strings.Join(list, "|")
+}
+
+// Resolve 将用户输入解析为完整镜像名：
+// "dashboard" / "swag-dashboard" -> "linuxserver/mods:swag-dashboard"；包含 "/" 或 ":" 的输入原样返回。
+func Resolve(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.ContainsAny(name, "/:") {
+		return name
+	}
+	for _, m := range Known {
+		if strings.EqualFold(name, m.Name) || strings.EqualFold(name, tag(m.Image)) {
+			return m.Image
+		}
+	}
+	return "linuxserver/mods:swag-" + strings.TrimPrefix(strings.ToLower(name), "swag-")
+}
+
+// Lookup 根据镜像名查找已知 mod（忽略 lscr.io/ghcr.io 等 registry 前缀）
+func Lookup(image string) (Mod, bool) {
+	for _, m := range Known {
+		if Same(m.Image, image) {
+			return m, true
+		}
+	}
+	return Mod{}, false
+}
+
+// Same 判断两个 mod 镜像是否等价（忽略 registry 前缀与大小写）
+func Same(a, b string) bool {
+	return normalize(a) == normalize(b)
+}
+
+// Contains 判断列表中是否已包含指定 mod
+func Contains(list []string, mod string) bool {
+	for _, m := range list {
+		if Same(m, mod) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 向 DOCKER_MODS 追加 mod，返回新值；已存在时 changed=false
+func Add(value, mod string) (string, bool) {
+	list := Parse(value)
+	if Contains(list, mod) {
+		return value, false
+	}
+	return Format(append(list, mod)), true
+}
+
+// Remove 从 DOCKER_MODS 删除 mod，返回新值；不存在时 changed=false
+func Remove(value, mod string) (string, bool) {
+	list := Parse(value)
+	var out []string
+	for _, m := range list {
+		if Same(m, mod) {
+			continue
+		}
+		out = append(out, m)
+	}
+	if len(out) == len(list) {
+		return value, false
+	}
+	return Format(out), true
+}
+
+func normalize(image string) string {
+	image = strings.ToLower(strings.TrimSpace(image))
+	for _, prefix := range []string{"lscr.io/", "ghcr.io/", "docker.io/"} {
+		image = strings.TrimPrefix(image, prefix)
+	}
+	return image
+}
+
+func tag(image string) string {
+	if i := strings.LastIndex(image, ":"); i >= 0 {
+		return image[i+1:]
+	}
+	return image
+}
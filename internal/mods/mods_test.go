@@ -0,0 +1,59 @@
+package mods
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"dashboard":                              "linuxserver/mods:swag-dashboard",
+		"swag-maxmind":                           "linuxserver/mods:swag-maxmind",
+		"universal-docker":                       "linuxserver/mods:universal-docker",
+		"something-new":                          "linuxserver/mods:swag-something-new",
+		"lscr.io/linuxserver/mods:swag-crowdsec": "lscr.io/linuxserver/mods:swag-crowdsec",
+	}
+	for in, want := range cases {
+		if got := Resolve(in); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	t.Parallel()
+
+	v, changed := Add("", "linuxserver/mods:swag-dashboard")
+	if !changed || v != "linuxserver/mods:swag-dashboard" {
+		t.Fatalf("Add() = %q, %v", v, changed)
+	}
+
+	v, changed = Add(v, "linuxserver/mods:swag-maxmind")
+	if !changed || v != "linuxserver/mods:swag-dashboard|linuxserver/mods:swag-maxmind" {
+		t.Fatalf("Add() = %q, %v", v, changed)
+	}
+
+	if _, changed := Add("lscr.io/linuxserver/mods:swag-dashboard", "linuxserver/mods:swag-dashboard"); changed {
+		t.Fatalf("Add() should treat registry-prefixed images as the same mod")
+	}
+
+	v, changed = Remove(v, "lscr.io/linuxserver/mods:swag-dashboard")
+	if !changed || v != "linuxserver/mods:swag-maxmind" {
+		t.Fatalf("Remove() = %q, %v", v, changed)
+	}
+
+	if _, changed := Remove(v, "linuxserver/mods:swag-crowdsec"); changed {
+		t.Fatalf("Remove() of missing mod should be no-op")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	m, ok := Lookup("ghcr.io/linuxserver/mods:swag-auto-proxy")
+	if !ok || m.Name != "auto-proxy" {
+		t.Fatalf("Lookup() = %+v, %v", m, ok)
+	}
+	if _, ok := Lookup("example/mods:custom"); ok {
+		t.Fatalf("Lookup() should not match unknown mods")
+	}
+}
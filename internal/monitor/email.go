@@ -0,0 +1,115 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email 通过 SMTP 发送邮件：默认在服务器支持时使用 STARTTLS，TLS 为 true 时使用隐式 TLS（端口 465）
+type Email struct {
+	name     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      bool
+}
+
+func (e *Email) Name() string { return e.name }
+
+func (e *Email) Notify(ctx context.Context, m Message) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	var conn net.Conn
+	var err error
+	if e.TLS {
+		d := &tls.Dialer{Config: &tls.Config{ServerName: e.Host}}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if !e.TLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if e.Username != "" {
+		// PlainAuth 拒绝在未加密的连接上发送密码（localhost 除外）
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("收件人 %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(mailMessage(e.From, e.To, m)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// mailMessage 构造纯文本邮件，标题与正文按 UTF-8 编码
+func mailMessage(from string, to []string, m Message) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.BEncoding.Encode("UTF-8", m.Title))
+	date := m.Time
+	if date.IsZero() {
+		date = time.Now()
+	}
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(m.Body))
+	for len(body) > 76 {
+		b.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	b.WriteString(body + "\r\n")
+	return b.Bytes()
+}
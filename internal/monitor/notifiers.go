@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Webhook 以 JSON 形式 POST 消息（Message 的字段）到任意地址
+type Webhook struct {
+	name    string
+	Client  *http.Client
+	URL     string
+	Headers map[string]string
+}
+
+func (w *Webhook) Name() string { return w.name }
+
+func (w *Webhook) Notify(ctx context.Context, m Message) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, m)
+}
+
+// Ntfy 发布消息到 ntfy 主题（JSON 发布接口，支持 UTF-8 标题）
+type Ntfy struct {
+	name   string
+	Client *http.Client
+	Server string
+	Topic  string
+	// Token 为访问令牌，用于受保护的主题
+	Token string
+}
+
+func (n *Ntfy) Name() string { return n.name }
+
+func (n *Ntfy) Notify(ctx context.Context, m Message) error {
+	priority, tag := 3, "information_source"
+	switch m.Level {
+	case LevelAlert:
+		priority, tag = 5, "rotating_light"
+	case LevelWarning:
+		priority, tag = 4, "warning"
+	case LevelRecovery:
+		tag = "white_check_mark"
+	}
+	payload := map[string]any{
+		"topic":    n.Topic,
+		"title":    m.Title,
+		"message":  m.Body,
+		"priority": priority,
+		"tags":     []string{tag},
+	}
+	var headers map[string]string
+	if n.Token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.Token}
+	}
+	return postJSON(ctx, n.Client, n.Server, headers, payload)
+}
+
+// Gotify 发送消息到 Gotify 服务器的 /message 接口
+type Gotify struct {
+	name   string
+	Client *http.Client
+	Server string
+	// Token 为应用 token
+	Token string
+}
+
+func (g *Gotify) Name() string { return g.name }
+
+func (g *Gotify) Notify(ctx context.Context, m Message) error {
+	priority := 4
+	switch m.Level {
+	case LevelAlert:
+		priority = 8
+	case LevelWarning:
+		priority = 6
+	}
+	payload := map[string]any{"title": m.Title, "message": m.Body, "priority": priority}
+	return postJSON(ctx, g.Client, g.Server+"/message", map[string]string{"X-Gotify-Key": g.Token}, payload)
+}
+
+// Telegram 通过 bot 的 sendMessage 接口发送消息
+type Telegram struct {
+	name   string
+	Client *http.Client
+	API    string
+	Token  string
+	ChatID string
+}
+
+func (t *Telegram) Name() string { return t.name }
+
+func (t *Telegram) Notify(ctx context.Context, m Message) error {
+	payload := map[string]any{
+		"chat_id":                  t.ChatID,
+		"text":                     m.Title + "\n\n" + m.Body,
+		"disable_web_page_preview": true,
+	}
+	return postJSON(ctx, t.Client, fmt.Sprintf("%s/bot%s/sendMessage", t.API, t.Token), nil, payload)
+}
+
+// postJSON 发送 JSON 请求，非 2xx 响应视为失败并附带响应内容
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,236 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"swag-cli/internal/sitecheck"
+)
+
+// Level 为消息级别，通知渠道据此设置优先级
+type Level string
+
+const (
+	LevelAlert    Level = "alert"
+	LevelWarning  Level = "warning"
+	LevelRecovery Level = "recovery"
+	LevelInfo     Level = "info"
+)
+
+// Message 为发送给通知渠道的消息
+type Message struct {
+	Title string    `json:"title"`
+	Body  string    `json:"message"`
+	Level Level     `json:"level"`
+	Site  string    `json:"site,omitempty"`
+	Check string    `json:"check,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Message 将事件格式化为通知消息
+func (e Event) Message() Message {
+	r := e.Result
+	m := Message{Site: e.Site, Check: string(e.Kind), Time: e.Time}
+	var lines []string
+	switch e.Type {
+	case EventDown:
+		m.Level = LevelAlert
+		m.Title = fmt.Sprintf("站点 %s 不可用 (%s)", e.Site, kindName(e.Kind))
+		if e.Reminder {
+			m.Title = fmt.Sprintf("站点 %s 仍不可用 (%s)", e.Site, kindName(e.Kind))
+		}
+		lines = append(lines, resultLine(r))
+		if r.Error != "" {
+			lines = append(lines, "错误: "+r.Error)
+		}
+		if e.Downtime > 0 {
+			lines = append(lines, "已持续: "+formatDuration(e.Downtime))
+		}
+	case EventUp:
+		m.Level = LevelRecovery
+		m.Title = fmt.Sprintf("站点 %s 已恢复 (%s)", e.Site, kindName(e.Kind))
+		lines = append(lines, resultLine(r))
+		if e.Downtime > 0 {
+			lines = append(lines, "不可用时长: "+formatDuration(e.Downtime))
+		}
+	case EventCertExpiring:
+		m.Level = LevelWarning
+		m.Title = fmt.Sprintf("站点 %s 的证书即将过期", e.Site)
+		if c := r.Cert; c != nil {
+			lines = append(lines,
+				fmt.Sprintf("证书将在 %d 天后过期 (%s)", c.DaysLeft, c.NotAfter.Format("2006-01-02")),
+				"颁发者: "+c.Issuer)
+		}
+		lines = append(lines, r.URL)
+	case EventCertRenewed:
+		m.Level = LevelRecovery
+		m.Title = fmt.Sprintf("站点 %s 的证书已更新", e.Site)
+		if c := r.Cert; c != nil {
+			lines = append(lines, fmt.Sprintf("新证书有效期至 %s (%d 天)", c.NotAfter.Format("2006-01-02"), c.DaysLeft))
+		}
+		lines = append(lines, r.URL)
+	}
+	m.Body = strings.Join(lines, "\n")
+	return m
+}
+
+func kindName(k sitecheck.Kind) string {
+	if k == sitecheck.KindInternal {
+		return "内部 SWAG -> 上游"
+	}
+	return "外部访问"
+}
+
+func resultLine(r sitecheck.Result) string {
+	line := r.URL
+	if r.Status > 0 {
+		line += fmt.Sprintf(" -> %d", r.Status)
+	}
+	if r.Latency > 0 {
+		line += fmt.Sprintf(" (%s)", r.Latency.Round(time.Millisecond))
+	}
+	return line
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Minute).String()
+}
+
+// Notifier 为通知渠道
+type Notifier interface {
+	// Name 用于日志与错误信息
+	Name() string
+	Notify(ctx context.Context, m Message) error
+}
+
+// NotifyAll 向所有渠道发送消息，单个渠道失败不影响其他渠道；返回发送成功的渠道数
+func NotifyAll(ctx context.Context, notifiers []Notifier, m Message) (int, error) {
+	sent := 0
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// Config 为通知配置文件（monitor.json）的内容
+type Config struct {
+	Notifiers []NotifierConfig `json:"notifiers"`
+}
+
+// NotifierConfig 为一个通知渠道的配置，Type 决定使用哪些字段：
+//
+//	webhook:  url, headers
+//	ntfy:     url (服务器，默认 https://ntfy.sh), topic, token
+//	gotify:   url, token (应用 token)
+//	telegram: token (bot token), chatId, url (API 地址，默认 https://api.telegram.org)
+//	email:    host, port (默认 587), username, password, from, to, tls (端口 465 的隐式 TLS)
+type NotifierConfig struct {
+	Type     string            `json:"type"`
+	Name     string            `json:"name,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Topic    string            `json:"topic,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	ChatID   string            `json:"chatId,omitempty"`
+	Host     string            `json:"host,omitempty"`
+	Port     int               `json:"port,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	From     string            `json:"from,omitempty"`
+	To       []string          `json:"to,omitempty"`
+	TLS      bool              `json:"tls,omitempty"`
+}
+
+// LoadNotifiers 读取通知配置并创建通知渠道，文件不存在时返回空列表
+func LoadNotifiers(p string, client *http.Client) ([]Notifier, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取通知配置失败 (%s): %w", p, err)
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return nil, nil
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("解析通知配置失败 (%s): %w", p, err)
+	}
+	var out []Notifier
+	for i, nc := range cfg.Notifiers {
+		n, err := nc.New(client)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个通知渠道配置无效 (%s): %w", i+1, p, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// New 根据配置创建通知渠道，HTTP 类渠道使用 client 发送请求
+func (c NotifierConfig) New(client *http.Client) (Notifier, error) {
+	name := c.Name
+	if name == "" {
+		name = c.Type
+	}
+	base := strings.TrimRight(c.URL, "/")
+	switch c.Type {
+	case "webhook":
+		if c.URL == "" {
+			return nil, errors.New("webhook 需要 url")
+		}
+		return &Webhook{name: name, Client: client, URL: c.URL, Headers: c.Headers}, nil
+	case "ntfy":
+		if c.Topic == "" {
+			return nil, errors.New("ntfy 需要 topic")
+		}
+		if base == "" {
+			base = "https://ntfy.sh"
+		}
+		return &Ntfy{name: name, Client: client, Server: base, Topic: c.Topic, Token: c.Token}, nil
+	case "gotify":
+		if base == "" || c.Token == "" {
+			return nil, errors.New("gotify 需要 url 与 token")
+		}
+		return &Gotify{name: name, Client: client, Server: base, Token: c.Token}, nil
+	case "telegram":
+		if c.Token == "" || c.ChatID == "" {
+			return nil, errors.New("telegram 需要 token 与 chatId")
+		}
+		if base == "" {
+			base = "https://api.telegram.org"
+		}
+		return &Telegram{name: name, Client: client, API: base, Token: c.Token, ChatID: c.ChatID}, nil
+	case "email":
+		if c.Host == "" || c.From == "" || len(c.To) == 0 {
+			return nil, errors.New("email 需要 host、from 与 to")
+		}
+		port := c.Port
+		if port == 0 {
+			port = 587
+			if c.TLS {
+				port = 465
+			}
+		}
+		return &Email{name: name, Host: c.Host, Port: port, Username: c.Username, Password: c.Password, From: c.From, To: c.To, TLS: c.TLS}, nil
+	case "":
+		return nil, errors.New("缺少 type")
+	default:
+		return nil, fmt.Errorf("未知的通知类型: %s (webhook、ntfy、gotify、telegram 或 email)", c.Type)
+	}
+}
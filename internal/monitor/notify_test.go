@@ -0,0 +1,305 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{Title: "站点 app 不可用", Body: "https://app.example.com -> 502", Level: LevelAlert, Site: "app", Check: "external"}
+
+func TestHTTPNotifiers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		config NotifierConfig
+		check  func(t *testing.T, r *http.Request, body map[string]any)
+	}{
+		{
+			NotifierConfig{Type: "webhook", URL: "/hook", Headers: map[string]string{"Authorization": "Bearer abc"}},
+			func(t *testing.T, r *http.Request, body map[string]any) {
+				if r.URL.Path != "/hook" || r.Header.Get("Authorization") != "Bearer abc" ||
+					body["title"] != testMessage.Title || body["level"] != "alert" || body["site"] != "app" {
+					t.Errorf("webhook request %s %v %v", r.URL.Path, r.Header, body)
+				}
+			},
+		},
+		{
+			NotifierConfig{Type: "ntfy", Topic: "swag", Token: "tk"},
+			func(t *testing.T, r *http.Request, body map[string]any) {
+				if r.URL.Path != "/" || r.Header.Get("Authorization") != "Bearer tk" ||
+					body["topic"] != "swag" || body["title"] != testMessage.Title || body["priority"] != float64(5) {
+					t.Errorf("ntfy request %s %v %v", r.URL.Path, r.Header, body)
+				}
+			},
+		},
+		{
+			NotifierConfig{Type: "gotify", Token: "app-token"},
+			func(t *testing.T, r *http.Request, body map[string]any) {
+				if r.URL.Path != "/message" || r.Header.Get("X-Gotify-Key") != "app-token" ||
+					body["message"] != testMessage.Body || body["priority"] != float64(8) {
+					t.Errorf("gotify request %s %v %v", r.URL.Path, r.Header, body)
+				}
+			},
+		},
+		{
+			NotifierConfig{Type: "telegram", Token: "123:abc", ChatID: "42"},
+			func(t *testing.T, r *http.Request, body map[string]any) {
+				text, _ := body["text"].(string)
+				if r.URL.Path != "/bot123:abc/sendMessage" || body["chat_id"] != "42" || !strings.HasPrefix(text, testMessage.Title) {
+					t.Errorf("telegram request %s %v", r.URL.Path, body)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		called := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("%s: decode body: %v", tt.config.Type, err)
+			}
+			tt.check(t, r, body)
+		}))
+		cfg := tt.config
+		cfg.URL = srv.URL + cfg.URL
+		n, err := cfg.New(srv.Client())
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", cfg.Type, err)
+		}
+		if err := n.Notify(context.Background(), testMessage); err != nil {
+			t.Fatalf("%s.Notify() error = %v", cfg.Type, err)
+		}
+		srv.Close()
+		if !called {
+			t.Fatalf("%s: server not called", cfg.Type)
+		}
+	}
+}
+
+func TestNotifyAllReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	notifiers := []Notifier{
+		&Webhook{name: "broken", Client: srv.Client(), URL: srv.URL + "/fail"},
+		&Webhook{name: "ok", Client: srv.Client(), URL: srv.URL + "/ok"},
+	}
+	sent, err := NotifyAll(context.Background(), notifiers, testMessage)
+	if err == nil || !strings.Contains(err.Error(), "broken: HTTP 401: invalid token") {
+		t.Fatalf("NotifyAll() error = %v, want 401 from broken", err)
+	}
+	if sent != 1 {
+		t.Fatalf("NotifyAll() sent = %d, want 1", sent)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (failure must not stop other notifiers)", calls)
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	t.Parallel()
+
+	srv := newSMTPServer(t)
+	n, err := NotifierConfig{
+		Type: "email", Host: "127.0.0.1", Port: srv.port,
+		Username: "user", Password: "secret",
+		From: "swag@example.com", To: []string{"ops@example.com", "me@example.com"},
+	}.New(nil)
+	if err != nil {
+		t.Fatalf("New(email) error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Notify(ctx, testMessage); err != nil {
+		t.Fatalf("Email.Notify() error = %v", err)
+	}
+
+	mail := srv.wait(t)
+	if mail.from != "swag@example.com" || strings.Join(mail.to, ",") != "ops@example.com,me@example.com" {
+		t.Fatalf("envelope = %s -> %v", mail.from, mail.to)
+	}
+	if !mail.auth {
+		t.Fatalf("AUTH not used")
+	}
+	header, body, _ := strings.Cut(mail.data, "\r\n\r\n")
+	var subject string
+	for _, line := range strings.Split(header, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "Subject: "); ok {
+			subject, _ = new(mime.WordDecoder).DecodeHeader(v)
+		}
+	}
+	if subject != testMessage.Title {
+		t.Fatalf("Subject = %q, want %q", subject, testMessage.Title)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(strings.TrimSpace(body), "\r\n", ""))
+	if err != nil || string(decoded) != testMessage.Body {
+		t.Fatalf("body = %q, %v", decoded, err)
+	}
+}
+
+func TestLoadNotifiers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "monitor.json")
+	if err := os.WriteFile(p, []byte(`{"notifiers": [
+		{"type": "ntfy", "topic": "swag"},
+		{"type": "email", "name": "ops-mail", "host": "smtp.example.com", "tls": true, "from": "a@example.com", "to": ["b@example.com"]}
+	]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := LoadNotifiers(p, nil)
+	if err != nil {
+		t.Fatalf("LoadNotifiers() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Name() != "ntfy" || got[0].(*Ntfy).Server != "https://ntfy.sh" ||
+		got[1].Name() != "ops-mail" || got[1].(*Email).Port != 465 {
+		t.Fatalf("LoadNotifiers() = %+v", got)
+	}
+
+	for _, cfg := range []NotifierConfig{
+		{Type: "slack"},
+		{Type: "gotify", URL: "https://gotify.example.com"},
+		{Type: "telegram", Token: "123:abc"},
+		{Type: "email", Host: "smtp.example.com"},
+	} {
+		if _, err := cfg.New(nil); err == nil {
+			t.Fatalf("New(%+v) should fail", cfg)
+		}
+	}
+
+	if got, err := LoadNotifiers(filepath.Join(dir, "missing.json"), nil); err != nil || len(got) != 0 {
+		t.Fatalf("LoadNotifiers(missing) = %v, %v", got, err)
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	t.Parallel()
+
+	e := Event{Type: EventUp, Site: "app", Kind: "internal", Downtime: 12 * time.Minute}
+	e.Result.URL = "http://app:8080/"
+	e.Result.Status = 200
+	m := e.Message()
+	if m.Level != LevelRecovery || !strings.Contains(m.Title, "已恢复") || !strings.Contains(m.Body, "12m0s") {
+		t.Fatalf("Message() = %+v", m)
+	}
+}
+
+// smtpServer 为最小的 SMTP 服务器，记录收到的一封邮件
+type smtpServer struct {
+	port int
+	mail chan receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+	auth bool
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	srv := &smtpServer{port: ln.Addr().(*net.TCPAddr).Port, mail: make(chan receivedMail, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		srv.serve(conn)
+	}()
+	return srv
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	var m receivedMail
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			m.auth = true
+			reply("235 ok")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			m.to = append(m.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			m.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			s.mail <- m
+			return
+		default:
+			reply("502 " + strconv.Quote(cmd))
+		}
+	}
+}
+
+func (s *smtpServer) wait(t *testing.T) receivedMail {
+	t.Helper()
+	select {
+	case m := <-s.mail:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no mail received")
+		return receivedMail{}
+	}
+}
@@ -0,0 +1,206 @@
+// Package monitor 周期性执行站点检查，跟踪状态变化（可用/不可用、证书即将过期），并通过通知渠道发送告警与恢复消息。
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"swag-cli/internal/sitecheck"
+)
+
+// EventType 为状态变化类型
+type EventType string
+
+const (
+	EventDown         EventType = "down"
+	EventUp           EventType = "up"
+	EventCertExpiring EventType = "cert_expiring"
+	EventCertRenewed  EventType = "cert_renewed"
+)
+
+// Event 为一次需要通知的状态变化
+type Event struct {
+	Type   EventType        `json:"type"`
+	Site   string           `json:"site"`
+	Kind   sitecheck.Kind   `json:"kind"`
+	Time   time.Time        `json:"time"`
+	Result sitecheck.Result `json:"result"`
+	// Reminder 表示不可用期间的重复提醒
+	Reminder bool `json:"reminder,omitempty"`
+	// Downtime 为不可用时长（从首次失败算起），用于不可用提醒与恢复消息
+	Downtime time.Duration `json:"downtime,omitempty"`
+}
+
+// Policy 控制何时发送通知
+type Policy struct {
+	// FailThreshold 为判定不可用所需的连续失败次数，小于 1 时按 1 处理
+	FailThreshold int
+	// Remind 大于 0 时，不可用期间每隔 Remind 重复提醒一次
+	Remind time.Duration
+}
+
+// CheckState 为单项检查（站点 + 类型）的状态
+type CheckState struct {
+	Down bool `json:"down"`
+	// Failures 为连续失败次数
+	Failures int `json:"failures,omitempty"`
+	// FirstFailure 为本轮连续失败的开始时间
+	FirstFailure time.Time `json:"firstFailure,omitempty"`
+	LastError    string    `json:"lastError,omitempty"`
+	LastNotified time.Time `json:"lastNotified,omitempty"`
+	// CertExpiring 为已提醒即将过期的证书的到期时间，同一张证书只提醒一次
+	CertExpiring time.Time `json:"certExpiring,omitempty"`
+}
+
+// State 为所有检查的状态，保存到文件以便在多次运行（如 cron 中的 --once）之间去重
+type State struct {
+	Checks map[string]*CheckState `json:"checks"`
+	// Pending 为所有渠道都发送失败的事件，下一轮重试
+	Pending []Event `json:"pending,omitempty"`
+}
+
+// maxPending 为保留的未送达事件上限，超出时丢弃最早的事件
+const maxPending = 100
+
+// NewState 返回空状态
+func NewState() *State {
+	return &State{Checks: map[string]*CheckState{}}
+}
+
+// LoadState 读取状态文件，文件不存在时返回空状态
+func LoadState(p string) (*State, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewState(), nil
+		}
+		return nil, fmt.Errorf("读取监控状态失败 (%s): %w", p, err)
+	}
+	s := NewState()
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("解析监控状态失败 (%s): %w", p, err)
+	}
+	if s.Checks == nil {
+		s.Checks = map[string]*CheckState{}
+	}
+	return s, nil
+}
+
+// Save 原子写入状态文件
+func (s *State) Save(p string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入监控状态失败: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入监控状态失败: %w", err)
+	}
+	return nil
+}
+
+// Update 根据一轮检查结果更新状态，返回需要通知的事件。
+// 只在状态变化时产生事件：连续失败达到阈值时不可用、此后首次成功时恢复、证书进入提醒期、已提醒的证书被更新；
+// 跳过的检查不影响状态。
+func (s *State) Update(results []sitecheck.Result, p Policy, now time.Time) []Event {
+	threshold := p.FailThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	var events []Event
+	for _, r := range results {
+		if r.Skipped != "" {
+			continue
+		}
+		key := r.Site + "/" + string(r.Kind)
+		st := s.Checks[key]
+		if st == nil {
+			st = &CheckState{}
+			s.Checks[key] = st
+		}
+		event := func(t EventType) Event {
+			return Event{Type: t, Site: r.Site, Kind: r.Kind, Time: now, Result: r}
+		}
+
+		if r.Failed() {
+			if st.Failures == 0 {
+				st.FirstFailure = now
+			}
+			st.Failures++
+			st.LastError = r.Error
+			switch {
+			case !st.Down && st.Failures >= threshold:
+				st.Down = true
+				st.LastNotified = now
+				e := event(EventDown)
+				e.Downtime = now.Sub(st.FirstFailure)
+				events = append(events, e)
+			case st.Down && p.Remind > 0 && now.Sub(st.LastNotified) >= p.Remind:
+				st.LastNotified = now
+				e := event(EventDown)
+				e.Reminder = true
+				e.Downtime = now.Sub(st.FirstFailure)
+				events = append(events, e)
+			}
+		} else {
+			if st.Down {
+				e := event(EventUp)
+				e.Downtime = now.Sub(st.FirstFailure)
+				events = append(events, e)
+			}
+			st.Down = false
+			st.Failures = 0
+			st.FirstFailure = time.Time{}
+			st.LastError = ""
+		}
+
+		if c := r.Cert; c != nil {
+			switch {
+			case c.Expiring && !st.CertExpiring.Equal(c.NotAfter):
+				st.CertExpiring = c.NotAfter
+				events = append(events, event(EventCertExpiring))
+			case !c.Expiring && c.Valid() && !st.CertExpiring.IsZero() && c.NotAfter.After(st.CertExpiring):
+				st.CertExpiring = time.Time{}
+				events = append(events, event(EventCertRenewed))
+			}
+		}
+	}
+	return events
+}
+
+// Deliver 依次发送之前未送达的事件与本轮的新事件。至少一个渠道发送成功（或未配置渠道）即视为已送达；
+// 所有渠道都失败的事件保留在 Pending 中，下一轮重试，避免一次发送失败导致告警丢失。
+// report 在每个事件发送后调用，retry 表示该事件是之前未送达的事件。
+func (s *State) Deliver(ctx context.Context, notifiers []Notifier, events []Event, report func(e Event, retry bool, err error)) {
+	retries := len(s.Pending)
+	queue := append(s.Pending, events...)
+	s.Pending = nil
+	for i, e := range queue {
+		sent, err := NotifyAll(ctx, notifiers, e.Message())
+		if report != nil {
+			report(e, i < retries, err)
+		}
+		if len(notifiers) > 0 && sent == 0 {
+			s.Pending = append(s.Pending, e)
+		}
+	}
+	if n := len(s.Pending); n > maxPending {
+		s.Pending = s.Pending[n-maxPending:]
+	}
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"swag-cli/internal/sitecheck"
+)
+
+func TestUpdateTransitions(t *testing.T) {
+	t.Parallel()
+
+	s := NewState()
+	p := Policy{FailThreshold: 2, Remind: time.Hour}
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	ok := sitecheck.Result{Site: "app", Kind: sitecheck.KindExternal, Pass: true, Status: 200}
+	fail := sitecheck.Result{Site: "app", Kind: sitecheck.KindExternal, Status: 502, Error: "status 502"}
+
+	steps := []struct {
+		at     time.Duration
+		result sitecheck.Result
+		want   []EventType
+	}{
+		{0, ok, nil},
+		{5 * time.Minute, fail, nil}, // 未达到阈值
+		{10 * time.Minute, fail, []EventType{EventDown}}, // 连续两次失败
+		{15 * time.Minute, fail, nil},                    // 已通知，去重
+		{70 * time.Minute, fail, []EventType{EventDown}}, // 重复提醒
+		{75 * time.Minute, ok, []EventType{EventUp}},
+		{80 * time.Minute, ok, nil},
+		{85 * time.Minute, fail, nil}, // 单次失败后恢复不通知
+		{90 * time.Minute, ok, nil},
+	}
+	for i, step := range steps {
+		events := s.Update([]sitecheck.Result{step.result}, p, start.Add(step.at))
+		var got []EventType
+		for _, e := range events {
+			got = append(got, e.Type)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("step %d: events = %v, want %v", i, got, step.want)
+		}
+		switch {
+		case i == 4 && !events[0].Reminder:
+			t.Fatalf("step %d: want reminder", i)
+		case i == 5 && events[0].Downtime != 70*time.Minute:
+			t.Fatalf("step %d: downtime = %s, want 70m", i, events[0].Downtime)
+		}
+	}
+}
+
+func TestUpdateCertExpiry(t *testing.T) {
+	t.Parallel()
+
+	s := NewState()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := func(notAfter time.Time, expiring bool) []sitecheck.Result {
+		return []sitecheck.Result{{
+			Site: "app", Kind: sitecheck.KindExternal, Pass: true, Status: 200,
+			Cert: &sitecheck.CertInfo{NotAfter: notAfter, Trusted: true, HostnameMatch: true, Expiring: expiring},
+		}}
+	}
+	old, renewed := now.AddDate(0, 0, 10), now.AddDate(0, 0, 90)
+
+	if got := s.Update(result(old, true), Policy{}, now); len(got) != 1 || got[0].Type != EventCertExpiring {
+		t.Fatalf("Update(expiring) = %+v, want cert_expiring", got)
+	}
+	if got := s.Update(result(old, true), Policy{}, now.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("Update(expiring again) = %+v, want no events", got)
+	}
+	if got := s.Update(result(renewed, false), Policy{}, now.Add(2*time.Hour)); len(got) != 1 || got[0].Type != EventCertRenewed {
+		t.Fatalf("Update(renewed) = %+v, want cert_renewed", got)
+	}
+	if got := s.Update(result(renewed, false), Policy{}, now.Add(3*time.Hour)); len(got) != 0 {
+		t.Fatalf("Update(renewed again) = %+v, want no events", got)
+	}
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "monitor-state.json")
+	s, err := LoadState(p)
+	if err != nil || len(s.Checks) != 0 {
+		t.Fatalf("LoadState(missing) = %+v, %v", s, err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fail := []sitecheck.Result{{Site: "app", Kind: sitecheck.KindInternal, Error: "connection refused"}}
+	s.Update(fail, Policy{}, now)
+	if err := s.Save(p); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// 重新加载后仍记得已通知，不再重复发送
+	s, err = LoadState(p)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if got := s.Update(fail, Policy{}, now.Add(time.Minute)); len(got) != 0 {
+		t.Fatalf("Update() after reload = %+v, want no events", got)
+	}
+	if got := s.Update([]sitecheck.Result{{Site: "app", Kind: sitecheck.KindInternal, Pass: true}}, Policy{}, now.Add(2*time.Minute)); len(got) != 1 || got[0].Type != EventUp {
+		t.Fatalf("Update(recovered) = %+v, want up", got)
+	}
+}
+
+func TestDeliverKeepsFailedEventsPending(t *testing.T) {
+	t.Parallel()
+
+	s := NewState()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	down := s.Update([]sitecheck.Result{{Site: "app", Kind: sitecheck.KindExternal, Error: "timeout"}}, Policy{}, now)
+
+	broken := &fakeNotifier{err: errors.New("smtp: 535 auth failed")}
+	var retried []bool
+	s.Deliver(context.Background(), []Notifier{broken}, down, func(e Event, retry bool, err error) {
+		retried = append(retried, retry)
+		if err == nil {
+			t.Fatalf("report() err = nil, want send failure")
+		}
+	})
+	if len(s.Pending) != 1 || s.Pending[0].Type != EventDown {
+		t.Fatalf("Pending = %+v, want the down event", s.Pending)
+	}
+
+	// 下一轮状态未变化，没有新事件，但未送达的告警会重试
+	if events := s.Update([]sitecheck.Result{{Site: "app", Kind: sitecheck.KindExternal, Error: "timeout"}}, Policy{}, now.Add(time.Minute)); len(events) != 0 {
+		t.Fatalf("Update() = %+v, want no new events", events)
+	}
+	up := s.Update([]sitecheck.Result{{Site: "app", Kind: sitecheck.KindExternal, Pass: true}}, Policy{}, now.Add(2*time.Minute))
+	ok := &fakeNotifier{}
+	s.Deliver(context.Background(), []Notifier{broken, ok}, up, func(e Event, retry bool, err error) {
+		retried = append(retried, retry)
+	})
+	if len(s.Pending) != 0 {
+		t.Fatalf("Pending = %+v, want empty after delivery", s.Pending)
+	}
+	if len(ok.titles) != 2 || !strings.Contains(ok.titles[0], "不可用") || !strings.Contains(ok.titles[1], "已恢复") {
+		t.Fatalf("sent = %v, want down then up", ok.titles)
+	}
+	if !reflect.DeepEqual(retried, []bool{false, true, false}) {
+		t.Fatalf("retry flags = %v", retried)
+	}
+}
+
+type fakeNotifier struct {
+	err    error
+	titles []string
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(ctx context.Context, m Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.titles = append(f.titles, m.Title)
+	return nil
+}
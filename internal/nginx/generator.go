@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"swag-cli/internal/nginxlog"
 	"swag-cli/templates"
 	"text/template"
 )
@@ -16,6 +17,8 @@ type ConfigData struct {
 	ContainerPort int
 	Protocol      string // http or https
 	ExtraConfig   string
+	// SiteLogs 为 true 时写入站点单独的 access_log/error_log（<subdomain>.access.log/.error.log）
+	SiteLogs bool
 }
 
 // Generator 处理 Nginx 配置文件生成
@@ -35,8 +38,11 @@ func (g *Generator) GenerateConfig(data ConfigData) (string, error) {
 		return "", fmt.Errorf("config directory does not exist: %s", g.BasePath)
 	}
 
-	// 准备模板
-	tmpl, err := template.New("proxy").Parse(templates.StandardProxyTemplate)
+	// 准备模板，站点日志路径统一由 nginxlog 定义
+	tmpl, err := template.New("proxy").Funcs(template.FuncMap{
+		"siteAccessLog": nginxlog.SiteAccessLog,
+		"siteErrorLog":  nginxlog.SiteErrorLog,
+	}).Parse(templates.StandardProxyTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -0,0 +1,38 @@
+package nginx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateConfigSiteLogs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gen := NewGenerator(dir)
+	path, err := gen.GenerateConfig(ConfigData{Subdomain: "app", ContainerName: "app", ContainerPort: 8080, Protocol: "http", SiteLogs: true})
+	if err != nil {
+		t.Fatalf("GenerateConfig() error = %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read error = %v", err)
+	}
+	for _, want := range []string{
+		"    server_name app.*;\n\n    access_log /config/log/nginx/app.access.log;\n    error_log /config/log/nginx/app.error.log;\n\n    include /config/nginx/ssl.conf;",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("config missing %q:\n%s", want, b)
+		}
+	}
+
+	path, err = gen.GenerateConfig(ConfigData{Subdomain: "plain", ContainerName: "plain", ContainerPort: 80, Protocol: "http"})
+	if err != nil {
+		t.Fatalf("GenerateConfig() error = %v", err)
+	}
+	b, _ = os.ReadFile(path)
+	if strings.Contains(string(b), "access_log") || !strings.Contains(string(b), "server_name plain.*;\n\n    include /config/nginx/ssl.conf;") {
+		t.Fatalf("config without site logs:\n%s", b)
+	}
+}
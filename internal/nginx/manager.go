@@ -45,6 +45,7 @@ type SiteConfig struct {
 	TargetDest    string     // 目标值 (容器名, IP, 路径等)
 	ContainerName string     // (Legacy) 兼容旧代码，同 TargetDest (如果是容器)
 	ContainerPort string     // 代理指向的端口 (从配置中解析)
+	UpstreamProto string     // 代理使用的协议 ($upstream_proto，http/https)
 }
 
 // Manager 管理 Nginx 配置文件
@@ -120,9 +121,10 @@ func (m *Manager) parseConfigDetails(config *SiteConfig) {
 	// 简单的正则匹配
 	reApp := regexp.MustCompile(`set\s+\$upstream_app\s+([^;]+);`)
 	rePort := regexp.MustCompile(`set\s+\$upstream_port\s+([^;]+);`)
+	reProto := regexp.MustCompile(`set\s+\$upstream_proto\s+([^;]+);`)
 	reRoot := regexp.MustCompile(`^\s*root\s+([^;]+);`)
 
-	var upstreamApp, upstreamPort, rootPath string
+	var upstreamApp, upstreamPort, upstreamProto, rootPath string
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -137,12 +139,16 @@ func (m *Manager) parseConfigDetails(config *SiteConfig) {
 		if matches := rePort.FindStringSubmatch(line); len(matches) > 1 {
 			upstreamPort = strings.TrimSpace(matches[1])
 		}
+		if matches := reProto.FindStringSubmatch(line); len(matches) > 1 {
+			upstreamProto = strings.TrimSpace(matches[1])
+		}
 		if matches := reRoot.FindStringSubmatch(line); len(matches) > 1 {
 			rootPath = strings.TrimSpace(matches[1])
 		}
 	}
 
 	config.ContainerPort = upstreamPort
+	config.UpstreamProto = upstreamProto
 
 	// 判定 TargetType
 	if upstreamApp != "" {
@@ -0,0 +1,141 @@
+// Package nginxlog 解析 SWAG 的 nginx access/error 日志，按站点、状态码与时间过滤并跟踪输出。
+package nginxlog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry 为一行日志解析后的字段；error log 只有 Time、Level、Message、Client 与 Host
+type Entry struct {
+	Raw        string
+	Error      bool
+	Host       string
+	RemoteAddr string
+	User       string
+	Time       time.Time
+	Method     string
+	Path       string
+	Protocol   string
+	Status     int
+	Bytes      int64
+	Referer    string
+	UserAgent  string
+	// UpstreamTime 为 $upstream_response_time（log_format 中以 urt= 或 upstream_response_time= 记录时才有），HasUpstreamTime 表示是否存在
+	UpstreamTime    time.Duration
+	HasUpstreamTime bool
+	// Level 与 Message 仅用于 error log
+	Level   string
+	Message string
+}
+
+// accessRe 匹配 combined 格式，前面可带主机名（vhost_combined），后面可带额外的引号字段（如 $http_x_forwarded_for、$host）
+var accessRe = regexp.MustCompile(`^(?:(\S+) )?(\S+) - (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?(.*)$`)
+
+var (
+	extraFieldRe   = regexp.MustCompile(`"([^"]*)"`)
+	upstreamTimeRe = regexp.MustCompile(`\b(?:urt|upstream_response_time|upstream_time)[=:]\s*"?(\d+(?:\.\d+)?)`)
+)
+
+const accessTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// ParseAccess 解析一行 access log，不是 combined 格式时返回 false
+func ParseAccess(line string) (Entry, bool) {
+	m := accessRe.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+	t, err := time.Parse(accessTimeLayout, m[4])
+	if err != nil {
+		return Entry{}, false
+	}
+	e := Entry{
+		Raw:        line,
+		RemoteAddr: m[2],
+		User:       m[3],
+		Time:       t,
+		Referer:    m[8],
+		UserAgent:  m[9],
+	}
+	e.Status, _ = strconv.Atoi(m[6])
+	if m[7] != "-" {
+		e.Bytes, _ = strconv.ParseInt(m[7], 10, 64)
+	}
+	if parts := strings.SplitN(m[5], " ", 3); len(parts) == 3 {
+		e.Method, e.Path, e.Protocol = parts[0], parts[1], parts[2]
+	} else {
+		e.Path = m[5]
+	}
+
+	if h := m[1]; h != "" {
+		e.Host = stripPort(h)
+	}
+	for _, f := range extraFieldRe.FindAllStringSubmatch(m[10], -1) {
+		if e.Host == "" && looksLikeHost(f[1]) {
+			e.Host = stripPort(f[1])
+		}
+	}
+	if um := upstreamTimeRe.FindStringSubmatch(m[10]); um != nil {
+		if sec, err := strconv.ParseFloat(um[1], 64); err == nil {
+			e.UpstreamTime = time.Duration(sec * float64(time.Second))
+			e.HasUpstreamTime = true
+		}
+	}
+	return e, true
+}
+
+var (
+	errorHeadRe = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) \[(\w+)\] (.*)$`)
+	errorHostRe = regexp.MustCompile(`, host: "([^"]+)"`)
+	errorCliRe  = regexp.MustCompile(`, client: ([^,]+),`)
+)
+
+// ParseError 解析一行 error log（时间按本地时区解析），不是 nginx error log 格式时返回 false
+func ParseError(line string) (Entry, bool) {
+	m := errorHeadRe.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+	t, err := time.ParseInLocation("2006/01/02 15:04:05", m[1], time.Local)
+	if err != nil {
+		return Entry{}, false
+	}
+	e := Entry{Raw: line, Error: true, Time: t, Level: m[2], Message: m[3]}
+	if hm := errorHostRe.FindStringSubmatch(line); hm != nil {
+		e.Host = stripPort(hm[1])
+	}
+	if cm := errorCliRe.FindStringSubmatch(line); cm != nil {
+		e.RemoteAddr = cm[1]
+	}
+	return e, true
+}
+
+// Parse 按格式自动解析 access 或 error log
+func Parse(line string) (Entry, bool) {
+	if e, ok := ParseAccess(line); ok {
+		return e, true
+	}
+	return ParseError(line)
+}
+
+var hostLikeRe = regexp.MustCompile(`^(?i)[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)+(?::\d+)?$`)
+
+func looksLikeHost(s string) bool {
+	if !hostLikeRe.MatchString(s) {
+		return false
+	}
+	// 排除 X-Forwarded-For 中的 IPv4 地址
+	last := s[strings.LastIndex(s, ".")+1:]
+	_, err := strconv.Atoi(strings.Split(last, ":")[0])
+	return err != nil
+}
+
+func stripPort(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	if i := strings.LastIndex(h, ":"); i > 0 && !strings.Contains(h[:i], ":") {
+		return h[:i]
+	}
+	return h
+}
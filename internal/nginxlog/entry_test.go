@@ -0,0 +1,121 @@
+package nginxlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAccess(t *testing.T) {
+	t.Parallel()
+
+	line := `203.0.113.7 - alice [01/Jan/2026:10:00:00 +0800] "GET /api/items?x=1 HTTP/2.0" 502 157 "-" "curl/8.0"`
+	e, ok := ParseAccess(line)
+	if !ok {
+		t.Fatalf("ParseAccess() failed")
+	}
+	if e.RemoteAddr != "203.0.113.7" || e.User != "alice" || e.Method != "GET" || e.Path != "/api/items?x=1" || e.Status != 502 || e.Bytes != 157 || e.UserAgent != "curl/8.0" || e.Host != "" {
+		t.Fatalf("ParseAccess() = %+v", e)
+	}
+	if want := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC); !e.Time.Equal(want) {
+		t.Fatalf("Time = %v, want %v", e.Time, want)
+	}
+
+	for name, tc := range map[string]struct {
+		line string
+		host string
+	}{
+		"vhost prefix":  {`app.example.com:443 203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 5 "-" "ua"`, "app.example.com"},
+		"host field":    {`203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 5 "-" "ua" "10.0.0.1" "App.Example.com"`, "app.example.com"},
+		"xff only":      {`203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 5 "-" "ua" "10.0.0.1"`, ""},
+		"no user agent": {`203.0.113.7 - - [01/Jan/2026:10:00:00 +0000] "GET / HTTP/1.1" 304 -`, ""},
+	} {
+		e, ok := ParseAccess(tc.line)
+		if !ok || e.Host != tc.host {
+			t.Fatalf("%s: ParseAccess() = %+v, %v", name, e, ok)
+		}
+	}
+
+	if _, ok := ParseAccess("2026/01/01 10:00:00 [error] 1#1: boom"); ok {
+		t.Fatalf("ParseAccess(error line) should fail")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	line := `2026/01/01 10:00:00 [error] 123#123: *45 connect() failed (111: Connection refused) while connecting to upstream, client: 203.0.113.7, server: app.*, request: "GET / HTTP/2.0", upstream: "http://172.18.0.5:8080/", host: "app.example.com"`
+	e, ok := Parse(line)
+	if !ok || !e.Error || e.Level != "error" || e.Host != "app.example.com" || e.RemoteAddr != "203.0.113.7" {
+		t.Fatalf("Parse() = %+v, %v", e, ok)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	status, err := ParseStatusFilter("4xx, 502")
+	if err != nil {
+		t.Fatalf("ParseStatusFilter() error = %v", err)
+	}
+	base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	f := Filter{ServerNames: []string{"app.*"}, Status: status, Since: base.Add(-time.Hour), Until: base.Add(time.Hour)}
+
+	for name, tc := range map[string]struct {
+		e    Entry
+		want bool
+	}{
+		"match":       {Entry{Host: "app.example.com", Status: 404, Time: base}, true},
+		"502":         {Entry{Host: "app.example.com", Status: 502, Time: base}, true},
+		"500":         {Entry{Host: "app.example.com", Status: 500, Time: base}, false},
+		"other host":  {Entry{Host: "www.example.com", Status: 404, Time: base}, false},
+		"too old":     {Entry{Host: "app.example.com", Status: 404, Time: base.Add(-2 * time.Hour)}, false},
+		"error entry": {Entry{Host: "app.example.com", Error: true, Time: base}, false},
+	} {
+		if got := f.Match(tc.e); got != tc.want {
+			t.Fatalf("%s: Match() = %v, want %v", name, got, tc.want)
+		}
+	}
+
+	if _, err := ParseStatusFilter("6xx"); err == nil {
+		t.Fatalf("ParseStatusFilter(6xx) should fail")
+	}
+}
+
+func TestMatchServerNames(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		names []string
+		host  string
+		want  bool
+	}{
+		{[]string{"app.*"}, "app.example.com", true},
+		{[]string{"app.*"}, "myapp.example.com", false},
+		{[]string{"*.example.com"}, "a.example.com", true},
+		{[]string{".example.com"}, "example.com", true},
+		{[]string{"~^api\\d+\\."}, "api2.example.com", true},
+		{[]string{"_"}, "anything", false},
+		{[]string{"example.com", "www.example.com"}, "WWW.example.com", true},
+	} {
+		if got := MatchServerNames(tc.names, tc.host); got != tc.want {
+			t.Fatalf("MatchServerNames(%v, %q) = %v, want %v", tc.names, tc.host, got, tc.want)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	got, err := ParseTimeBound("90m", now)
+	if err != nil || !got.Equal(now.Add(-90*time.Minute)) {
+		t.Fatalf("ParseTimeBound(90m) = %v, %v", got, err)
+	}
+	got, err = ParseTimeBound("2026-01-02T08:00:00Z", now)
+	if err != nil || !got.Equal(time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ParseTimeBound(RFC3339) = %v, %v", got, err)
+	}
+	if _, err := ParseTimeBound("yesterday", now); err == nil {
+		t.Fatalf("ParseTimeBound(yesterday) should fail")
+	}
+}
@@ -0,0 +1,133 @@
+package nginxlog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusFilter 匹配状态码：精确值（502）或类别（4xx）
+type StatusFilter struct {
+	codes   map[int]bool
+	classes map[int]bool
+}
+
+// ParseStatusFilter 解析以逗号分隔的状态码列表，如 "4xx,502"；空字符串返回 nil（匹配全部）
+func ParseStatusFilter(s string) (*StatusFilter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	f := &StatusFilter{codes: map[int]bool{}, classes: map[int]bool{}}
+	for _, p := range strings.Split(s, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if len(p) == 3 && strings.HasSuffix(p, "xx") && p[0] >= '1' && p[0] <= '5' {
+			f.classes[int(p[0]-'0')] = true
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 100 || n > 599 {
+			return nil, fmt.Errorf("无效的状态码: %s (示例: 4xx,502)", p)
+		}
+		f.codes[n] = true
+	}
+	return f, nil
+}
+
+// Match 判断状态码是否匹配；nil 匹配全部
+func (f *StatusFilter) Match(status int) bool {
+	if f == nil {
+		return true
+	}
+	return f.codes[status] || f.classes[status/100]
+}
+
+// Filter 为日志过滤条件，零值匹配全部
+type Filter struct {
+	// ServerNames 非空时只保留 Host 匹配其中任一 server_name 的行
+	ServerNames []string
+	Status      *StatusFilter
+	Since       time.Time
+	Until       time.Time
+}
+
+// Match 判断日志行是否满足过滤条件；状态码过滤时 error log 行不匹配
+func (f Filter) Match(e Entry) bool {
+	if len(f.ServerNames) > 0 && !MatchServerNames(f.ServerNames, e.Host) {
+		return false
+	}
+	if f.Status != nil && (e.Error || !f.Status.Match(e.Status)) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// MatchServerNames 判断主机名是否匹配 nginx server_name 列表（支持 *.example.com、app.* 与 ~正则）
+func MatchServerNames(names []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		switch {
+		case n == "" || n == "_":
+			continue
+		case strings.HasPrefix(n, "~"):
+			if re, err := regexp.Compile(n[1:]); err == nil && re.MatchString(host) {
+				return true
+			}
+		case strings.HasPrefix(n, "*."):
+			if strings.HasSuffix(host, n[1:]) {
+				return true
+			}
+		case strings.HasPrefix(n, "."):
+			if host == n[1:] || strings.HasSuffix(host, n) {
+				return true
+			}
+		case strings.HasSuffix(n, ".*"):
+			if strings.HasPrefix(host, n[:len(n)-1]) {
+				return true
+			}
+		case n == host:
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTimeBound 解析 --since/--until：时长表示距 now 之前（如 30m、2h、7d），也可为 "2006-01-02 15:04[:05]" 或 RFC3339 时间
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间: %s (示例: 30m、2h、7d、2026-01-02 15:04)", s)
+}
@@ -0,0 +1,98 @@
+package nginxlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SWAG 默认的日志文件（容器内路径）
+const (
+	DefaultAccessLog = "/config/log/nginx/access.log"
+	DefaultErrorLog  = "/config/log/nginx/error.log"
+)
+
+// SiteConf 为站点配置中与日志相关的指令
+type SiteConf struct {
+	ServerNames []string
+	// AccessLogs 与 ErrorLogs 为站点单独配置的日志文件（容器内路径），未配置时为空
+	AccessLogs []string
+	ErrorLogs  []string
+}
+
+var (
+	serverNameRe = regexp.MustCompile(`^\s*server_name\s+([^;]+);`)
+	accessLogRe  = regexp.MustCompile(`^\s*access_log\s+([^\s;]+)`)
+	errorLogRe   = regexp.MustCompile(`^\s*error_log\s+([^\s;]+)`)
+)
+
+// ParseSiteConf 读取站点配置中的 server_name、access_log 与 error_log（忽略注释、off 与 syslog）
+func ParseSiteConf(path string) (SiteConf, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return SiteConf{}, fmt.Errorf("读取站点配置失败 (%s): %w", path, err)
+	}
+	var sc SiteConf
+	for _, line := range strings.Split(string(b), "\n") {
+		if m := serverNameRe.FindStringSubmatch(line); m != nil {
+			for _, n := range strings.Fields(m[1]) {
+				if !contains(sc.ServerNames, n) {
+					sc.ServerNames = append(sc.ServerNames, n)
+				}
+			}
+		}
+		if m := accessLogRe.FindStringSubmatch(line); m != nil && logFile(m[1]) && !contains(sc.AccessLogs, m[1]) {
+			sc.AccessLogs = append(sc.AccessLogs, m[1])
+		}
+		if m := errorLogRe.FindStringSubmatch(line); m != nil && logFile(m[1]) && !contains(sc.ErrorLogs, m[1]) {
+			sc.ErrorLogs = append(sc.ErrorLogs, m[1])
+		}
+	}
+	return sc, nil
+}
+
+// SiteAccessLog 返回按站点名生成的 access_log 路径（容器内）
+func SiteAccessLog(site string) string {
+	return "/config/log/nginx/" + site + ".access.log"
+}
+
+// SiteErrorLog 返回按站点名生成的 error_log 路径（容器内）
+func SiteErrorLog(site string) string {
+	return "/config/log/nginx/" + site + ".error.log"
+}
+
+// HostPath 将容器内 /config 下的路径转换为 swag-dir 下的路径，其他路径原样返回
+func HostPath(swagDir, p string) string {
+	if rest, ok := strings.CutPrefix(p, "/config/"); ok {
+		return filepath.Join(swagDir, "config", filepath.FromSlash(rest))
+	}
+	return p
+}
+
+func logFile(p string) bool {
+	return p != "off" && !strings.HasPrefix(p, "syslog:") && !strings.HasPrefix(p, "/dev/")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// LogFiles 返回查看站点日志时应读取的文件（容器内路径）。
+// 站点未单独配置日志（或配置的就是 SWAG 的共享日志）时 shared 为 true，需要按 ServerNames 过滤。
+func (sc SiteConf) LogFiles(errorLog bool) (paths []string, shared bool) {
+	own, def := sc.AccessLogs, DefaultAccessLog
+	if errorLog {
+		own, def = sc.ErrorLogs, DefaultErrorLog
+	}
+	if len(own) == 0 {
+		return []string{def}, true
+	}
+	return own, contains(own, def)
+}
@@ -0,0 +1,300 @@
+package nginxlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogFile 为 LogDir() 下的一个日志文件（含轮转文件）
+type LogFile struct {
+	Path string
+	// Site 为站点单独日志（<site>.access.log*、<site>.error.log*）所属的站点，共享日志为空
+	Site string
+}
+
+// AccessLogFiles 列出目录下的 access log 及其轮转文件（access.log.1、access.log.2.gz 等）
+func AccessLogFiles(dir string) ([]LogFile, error) {
+	return logFiles(dir, "access")
+}
+
+// ErrorLogFiles 列出目录下的 error log 及其轮转文件
+func ErrorLogFiles(dir string) ([]LogFile, error) {
+	return logFiles(dir, "error")
+}
+
+func logFiles(dir, kind string) ([]LogFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取日志目录失败 (%s): %w", dir, err)
+	}
+	var out []LogFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		base, _, _ := strings.Cut(name, ".log")
+		switch {
+		case base == kind:
+			out = append(out, LogFile{Path: filepath.Join(dir, name)})
+		case strings.HasSuffix(base, "."+kind):
+			out = append(out, LogFile{Path: filepath.Join(dir, name), Site: strings.TrimSuffix(base, "."+kind)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// ScanLog 逐行读取日志文件（.gz 文件透明解压）
+func ScanLog(path string, fn func(line string)) error {
+	rc, err := openLog(path)
+	if err != nil {
+		return fmt.Errorf("打开日志失败 (%s): %w", path, err)
+	}
+	defer rc.Close()
+
+	r := bufio.NewReaderSize(rc, 64*1024)
+	for {
+		line, _, err := readLine(r)
+		if line != "" {
+			fn(strings.TrimRight(line, "\r\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取日志失败 (%s): %w", path, err)
+		}
+	}
+}
+
+// openLog 打开日志文件，.gz 文件透明解压
+func openLog(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, f}, nil
+}
+
+// StatsOptions 为统计参数
+type StatsOptions struct {
+	// ServerNames 为站点名到 server_name 的映射，用于将共享日志中的请求归属到站点
+	ServerNames map[string][]string
+	// Site 非空时只统计该站点
+	Site  string
+	Since time.Time
+	// Top 为 TopPaths/TopClients 的条数，默认 10
+	Top int
+}
+
+// Count 为计数排行中的一项
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// SiteStats 为一个站点（或合计）的统计结果
+type SiteStats struct {
+	Site     string         `json:"site"`
+	Requests int            `json:"requests"`
+	Bytes    int64          `json:"bytes"`
+	Classes  map[string]int `json:"classes"`
+	Statuses map[int]int    `json:"statuses"`
+	// TopPaths 不含查询参数
+	TopPaths   []Count `json:"topPaths"`
+	TopClients []Count `json:"topClients"`
+	// UpstreamSamples 为记录了 upstream 响应时间的请求数，为 0 时 P50/P95 无意义
+	UpstreamSamples int           `json:"upstreamSamples"`
+	UpstreamP50     time.Duration `json:"upstreamP50,omitempty"`
+	UpstreamP95     time.Duration `json:"upstreamP95,omitempty"`
+}
+
+// Report 为 Stats 的结果
+type Report struct {
+	Files []string `json:"files"`
+	// First/Last 为统计到的最早与最晚请求时间
+	First time.Time   `json:"first"`
+	Last  time.Time   `json:"last"`
+	Sites []SiteStats `json:"sites"`
+	Total SiteStats   `json:"total"`
+	// Unparsed 为无法按 combined 格式解析的行数
+	Unparsed int `json:"unparsed"`
+}
+
+// UnknownSite 为共享日志中无法确定站点（没有主机名或主机名不匹配任何站点）的请求
+const UnknownSite = "-"
+
+type siteAgg struct {
+	stats    SiteStats
+	paths    map[string]int
+	clients  map[string]int
+	upstream []time.Duration
+}
+
+func newSiteAgg(site string) *siteAgg {
+	return &siteAgg{
+		stats:   SiteStats{Site: site, Classes: map[string]int{}, Statuses: map[int]int{}},
+		paths:   map[string]int{},
+		clients: map[string]int{},
+	}
+}
+
+func (a *siteAgg) add(e Entry) {
+	a.stats.Requests++
+	a.stats.Bytes += e.Bytes
+	a.stats.Statuses[e.Status]++
+	a.stats.Classes[fmt.Sprintf("%dxx", e.Status/100)]++
+	path, _, _ := strings.Cut(e.Path, "?")
+	a.paths[path]++
+	a.clients[e.RemoteAddr]++
+	if e.HasUpstreamTime {
+		a.upstream = append(a.upstream, e.UpstreamTime)
+	}
+}
+
+func (a *siteAgg) finish(top int) SiteStats {
+	s := a.stats
+	s.TopPaths = topCounts(a.paths, top)
+	s.TopClients = topCounts(a.clients, top)
+	s.UpstreamSamples = len(a.upstream)
+	if len(a.upstream) > 0 {
+		sort.Slice(a.upstream, func(i, j int) bool { return a.upstream[i] < a.upstream[j] })
+		s.UpstreamP50 = percentile(a.upstream, 50)
+		s.UpstreamP95 = percentile(a.upstream, 95)
+	}
+	return s
+}
+
+// Stats 统计 access log：按站点汇总请求数、状态码、流量、热门路径与客户端、upstream 响应时间
+func Stats(files []LogFile, opts StatsOptions) (Report, error) {
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	var rep Report
+	total := newSiteAgg("TOTAL")
+	sites := map[string]*siteAgg{}
+
+	for _, lf := range files {
+		if opts.Site != "" && lf.Site != "" && lf.Site != opts.Site {
+			continue
+		}
+		if !opts.Since.IsZero() {
+			if st, err := os.Stat(lf.Path); err == nil && st.ModTime().Before(opts.Since) {
+				continue
+			}
+		}
+		rep.Files = append(rep.Files, lf.Path)
+
+		err := ScanLog(lf.Path, func(line string) {
+			e, ok := ParseAccess(line)
+			if !ok {
+				if strings.TrimSpace(line) != "" {
+					rep.Unparsed++
+				}
+				return
+			}
+			if !opts.Since.IsZero() && e.Time.Before(opts.Since) {
+				return
+			}
+			site := lf.Site
+			if site == "" {
+				site = siteForHost(opts.ServerNames, e.Host)
+			}
+			if opts.Site != "" && site != opts.Site {
+				return
+			}
+
+			a, ok := sites[site]
+			if !ok {
+				a = newSiteAgg(site)
+				sites[site] = a
+			}
+			a.add(e)
+			total.add(e)
+			if rep.First.IsZero() || e.Time.Before(rep.First) {
+				rep.First = e.Time
+			}
+			if e.Time.After(rep.Last) {
+				rep.Last = e.Time
+			}
+		})
+		if err != nil {
+			return rep, err
+		}
+	}
+
+	for _, a := range sites {
+		rep.Sites = append(rep.Sites, a.finish(opts.Top))
+	}
+	sort.Slice(rep.Sites, func(i, j int) bool {
+		if rep.Sites[i].Requests != rep.Sites[j].Requests {
+			return rep.Sites[i].Requests > rep.Sites[j].Requests
+		}
+		return rep.Sites[i].Site < rep.Sites[j].Site
+	})
+	rep.Total = total.finish(opts.Top)
+	return rep, nil
+}
+
+// siteForHost 按 server_name 将主机名归属到站点；没有匹配的站点时返回主机名本身
+func siteForHost(serverNames map[string][]string, host string) string {
+	if host == "" {
+		return UnknownSite
+	}
+	names := make([]string, 0, len(serverNames))
+	for name := range serverNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if MatchServerNames(serverNames[name], host) {
+			return name
+		}
+	}
+	return host
+}
+
+func topCounts(m map[string]int, n int) []Count {
+	out := make([]Count, 0, len(m))
+	for k, v := range m {
+		out = append(out, Count{Key: k, Count: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// percentile 返回已排序样本的第 p 百分位（nearest-rank）
+func percentile(sorted []time.Duration, p int) time.Duration {
+	idx := (p*len(sorted)+99)/100 - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
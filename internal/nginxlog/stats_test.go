@@ -0,0 +1,83 @@
+package nginxlog
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatsAggregatesSitesAndRotatedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mustWriteLog(t, filepath.Join(dir, "access.log"),
+		`203.0.113.7 - - [02/Jan/2026:10:00:00 +0000] "GET /a?x=1 HTTP/1.1" 200 100 "-" "ua" "app.example.com" urt="0.010"`+"\n"+
+			`203.0.113.8 - - [02/Jan/2026:10:01:00 +0000] "GET /a HTTP/1.1" 502 10 "-" "ua" "app.example.com" urt="0.200"`+"\n"+
+			`203.0.113.7 - - [02/Jan/2026:10:02:00 +0000] "GET / HTTP/1.1" 200 5 "-" "ua"`+"\n"+
+			"garbage line\n")
+	writeGzip(t, filepath.Join(dir, "access.log.2.gz"),
+		`203.0.113.9 - - [01/Jan/2026:10:00:00 +0000] "GET /old HTTP/1.1" 404 0 "-" "ua" "app.example.com" urt="0.030"`+"\n")
+	mustWriteLog(t, filepath.Join(dir, "vault.access.log"),
+		`198.51.100.1 - - [02/Jan/2026:11:00:00 +0000] "POST /identity/connect/token HTTP/2.0" 400 50 "-" "ua"`+"\n")
+	mustWriteLog(t, filepath.Join(dir, "error.log"), "2026/01/02 10:00:00 [error] 1#1: boom\n")
+
+	files, err := AccessLogFiles(dir)
+	if err != nil {
+		t.Fatalf("AccessLogFiles() error = %v", err)
+	}
+	if len(files) != 3 || files[2].Site != "vault" {
+		t.Fatalf("AccessLogFiles() = %+v", files)
+	}
+
+	rep, err := Stats(files, StatsOptions{ServerNames: map[string][]string{"app": {"app.*"}}})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if rep.Total.Requests != 5 || rep.Unparsed != 1 || rep.Total.Bytes != 165 {
+		t.Fatalf("Total = %+v, unparsed = %d", rep.Total, rep.Unparsed)
+	}
+	bySite := map[string]SiteStats{}
+	for _, s := range rep.Sites {
+		bySite[s.Site] = s
+	}
+	app := bySite["app"]
+	if app.Requests != 3 || app.Classes["5xx"] != 1 || app.Statuses[404] != 1 {
+		t.Fatalf("app = %+v", app)
+	}
+	if app.TopPaths[0] != (Count{Key: "/a", Count: 2}) {
+		t.Fatalf("TopPaths = %+v", app.TopPaths)
+	}
+	if app.UpstreamSamples != 3 || app.UpstreamP50 != 30*time.Millisecond || app.UpstreamP95 != 200*time.Millisecond {
+		t.Fatalf("upstream = %d %v %v", app.UpstreamSamples, app.UpstreamP50, app.UpstreamP95)
+	}
+	if bySite["vault"].Requests != 1 || bySite[UnknownSite].Requests != 1 {
+		t.Fatalf("sites = %+v", rep.Sites)
+	}
+
+	rep, err = Stats(files, StatsOptions{ServerNames: map[string][]string{"app": {"app.*"}}, Site: "app", Since: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Stats(site) error = %v", err)
+	}
+	if len(rep.Sites) != 1 || rep.Total.Requests != 2 || rep.Total.TopClients[0].Key != "203.0.113.7" {
+		t.Fatalf("Stats(site) = %+v", rep)
+	}
+}
+
+func writeGzip(t *testing.T, p, content string) {
+	t.Helper()
+
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatalf("gzip write error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package nginxlog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxLineSize 为单行日志的最大长度，超出部分会被截断
+const maxLineSize = 1 << 20
+
+// Last 返回文件中最后 n 行满足 keep 的日志（n <= 0 表示全部），以及读取结束时的偏移量（供 Follow 继续读取）
+func Last(path string, n int, keep func(line string) bool) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("打开日志失败 (%s): %w", path, err)
+	}
+	defer f.Close()
+
+	var out []string
+	var offset int64
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, size, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("读取日志失败 (%s): %w", path, err)
+		}
+		if err == io.EOF && !strings.HasSuffix(line, "\n") {
+			// 不完整的最后一行留给 Follow
+			break
+		}
+		offset += size
+		line = strings.TrimRight(line, "\r\n")
+		if keep == nil || keep(line) {
+			out = append(out, line)
+			if n > 0 && len(out) > n {
+				out = out[1:]
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return out, offset, nil
+}
+
+// Follow 从 offset 开始持续读取新写入的行并调用 fn，直到 ctx 取消。
+// 文件被轮转（变小或被替换）时从新文件开头读取。
+func Follow(ctx context.Context, path string, offset int64, interval time.Duration, fn func(line string)) error {
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	var (
+		f   *os.File
+		r   *bufio.Reader
+		st  os.FileInfo
+		buf string
+	)
+	open := func(at int64) error {
+		nf, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		nst, err := nf.Stat()
+		if err != nil {
+			nf.Close()
+			return err
+		}
+		if at > nst.Size() {
+			at = 0
+		}
+		if _, err := nf.Seek(at, io.SeekStart); err != nil {
+			nf.Close()
+			return err
+		}
+		if f != nil {
+			f.Close()
+		}
+		f, st, r, offset, buf = nf, nst, bufio.NewReaderSize(nf, 64*1024), at, ""
+		return nil
+	}
+	if err := open(offset); err != nil {
+		return fmt.Errorf("打开日志失败 (%s): %w", path, err)
+	}
+	defer func() { f.Close() }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			line, size, err := readLine(r)
+			offset += size
+			buf += line
+			if strings.HasSuffix(buf, "\n") {
+				fn(strings.TrimRight(buf, "\r\n"))
+				buf = ""
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("读取日志失败 (%s): %w", path, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// 检查轮转：路径指向了新文件，或文件被截断
+		if nst, err := os.Stat(path); err == nil && (!os.SameFile(st, nst) || nst.Size() < offset) {
+			if err := open(0); err != nil {
+				return fmt.Errorf("重新打开日志失败 (%s): %w", path, err)
+			}
+		}
+	}
+}
+
+// readLine 读取一行（含换行符）并返回实际读取的字节数，超过 maxLineSize 的部分被丢弃
+func readLine(r *bufio.Reader) (string, int64, error) {
+	var sb strings.Builder
+	var n int64
+	for {
+		chunk, err := r.ReadSlice('\n')
+		n += int64(len(chunk))
+		if sb.Len() < maxLineSize {
+			sb.Write(chunk)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		line := sb.String()
+		if err == nil && !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		return line, n, err
+	}
+}
@@ -0,0 +1,122 @@
+package nginxlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastKeepsLastMatchingLines(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "access.log")
+	mustWriteLog(t, p, "a1\nb1\na2\na3\npartial")
+
+	got, off, err := Last(p, 2, func(l string) bool { return strings.HasPrefix(l, "a") })
+	if err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a2", "a3"}) {
+		t.Fatalf("Last() = %v", got)
+	}
+	if off != int64(len("a1\nb1\na2\na3\n")) {
+		t.Fatalf("offset = %d", off)
+	}
+}
+
+func TestFollowReadsAppendedLinesAndRotation(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "access.log")
+	mustWriteLog(t, p, "old\n")
+	_, off, err := Last(p, 0, nil)
+	if err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Follow(ctx, p, off, 10*time.Millisecond, func(l string) { lines <- l })
+	}()
+
+	appendLog(t, p, "new1\nne")
+	expectLine(t, lines, "new1")
+	appendLog(t, p, "w2\n")
+	expectLine(t, lines, "new2")
+
+	// 轮转：改名后创建新文件
+	if err := os.Rename(p, p+".1"); err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+	mustWriteLog(t, p, "rotated\n")
+	expectLine(t, lines, "rotated")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+}
+
+func TestSiteConfLogFiles(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "app.subdomain.conf")
+	mustWriteLog(t, p, "server {\n    server_name app.* app2.example.com;\n    access_log /config/log/nginx/app.access.log;\n    #error_log /config/log/nginx/app.error.log;\n}\n")
+	sc, err := ParseSiteConf(p)
+	if err != nil {
+		t.Fatalf("ParseSiteConf() error = %v", err)
+	}
+	if !reflect.DeepEqual(sc.ServerNames, []string{"app.*", "app2.example.com"}) {
+		t.Fatalf("ServerNames = %v", sc.ServerNames)
+	}
+	if paths, shared := sc.LogFiles(false); shared || !reflect.DeepEqual(paths, []string{"/config/log/nginx/app.access.log"}) {
+		t.Fatalf("LogFiles(access) = %v, %v", paths, shared)
+	}
+	if paths, shared := sc.LogFiles(true); !shared || paths[0] != DefaultErrorLog {
+		t.Fatalf("LogFiles(error) = %v, %v", paths, shared)
+	}
+	if got := HostPath("/srv/swag", "/config/log/nginx/app.access.log"); got != filepath.Join("/srv/swag", "config", "log", "nginx", "app.access.log") {
+		t.Fatalf("HostPath() = %s", got)
+	}
+}
+
+func expectLine(t *testing.T, lines <-chan string, want string) {
+	t.Helper()
+
+	select {
+	case got := <-lines:
+		if got != want {
+			t.Fatalf("line = %q, want %q", got, want)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for %q", want)
+	}
+}
+
+func mustWriteLog(t *testing.T, p, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+}
+
+func appendLog(t *testing.T, p, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open error = %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("append error = %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package sitecheck 检查站点的内部（SWAG -> 上游）与外部（域名）可用性，并发执行并记录状态码、耗时与 TLS 信息。
+package sitecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"swag-cli/internal/nginxlog"
+)
+
+// Kind 为检查类型
+type Kind string
+
+const (
+	KindInternal Kind = "internal"
+	KindExternal Kind = "external"
+)
+
+// Expect 为站点的期望结果，零值表示请求 / 且状态码小于 500 即通过
+type Expect struct {
+	// Path 为请求路径，默认 /
+	Path string `json:"path,omitempty"`
+	// Status 为期望的状态码列表，如 "200,3xx"
+	Status string `json:"status,omitempty"`
+	// Body 非空时响应内容需包含该字符串
+	Body string `json:"body,omitempty"`
+	// TCP 为 true 时内部检查只要求能建立 TCP 连接（用于非 HTTP 上游）
+	TCP bool `json:"tcp,omitempty"`
+}
+
+// ChecksFile 为检查期望文件的内容
+type ChecksFile struct {
+	Sites map[string]Expect `json:"sites"`
+}
+
+// LoadExpectations 读取各站点的期望结果，文件不存在时返回空集合
+func LoadExpectations(p string) (map[string]Expect, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Expect{}, nil
+		}
+		return nil, fmt.Errorf("读取检查配置失败 (%s): %w", p, err)
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return map[string]Expect{}, nil
+	}
+
+	var f ChecksFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("解析检查配置失败 (%s): %w", p, err)
+	}
+	out := make(map[string]Expect, len(f.Sites))
+	for site, e := range f.Sites {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("站点 %s 的检查配置无效 (%s): %w", site, p, err)
+		}
+		out[site] = e
+	}
+	return out, nil
+}
+
+func (e Expect) validate() error {
+	if e.Path != "" && !strings.HasPrefix(e.Path, "/") {
+		return fmt.Errorf("path 需以 / 开头: %s", e.Path)
+	}
+	_, err := nginxlog.ParseStatusFilter(e.Status)
+	return err
+}
+
+// RequestPath 返回请求路径
+func (e Expect) RequestPath() string {
+	if e.Path == "" {
+		return "/"
+	}
+	return e.Path
+}
+
+// Verify 判断响应是否符合期望
+func (e Expect) Verify(status int, body string) error {
+	sf, err := nginxlog.ParseStatusFilter(e.Status)
+	if err != nil {
+		return err
+	}
+	switch {
+	case sf != nil && !sf.Match(status):
+		return fmt.Errorf("状态码 %d，期望 %s", status, e.Status)
+	case sf == nil && status >= 500:
+		return fmt.Errorf("状态码 %d", status)
+	case e.Body != "" && !strings.Contains(body, e.Body):
+		return fmt.Errorf("响应中不包含 %q", e.Body)
+	}
+	return nil
+}
+
+// TLSInfo 为 TLS 连接信息
+type TLSInfo struct {
+	Version  string    `json:"version,omitempty"`
+	Cipher   string    `json:"cipher,omitempty"`
+	Subject  string    `json:"subject,omitempty"`
+	NotAfter time.Time `json:"notAfter,omitempty"`
+}
+
+// Result 为一次检查的结果
+type Result struct {
+	Site    string        `json:"site"`
+	Kind    Kind          `json:"kind"`
+	URL     string        `json:"url,omitempty"`
+	Status  int           `json:"status,omitempty"`
+	Latency time.Duration `json:"latency,omitempty"`
+	Timing  *Timing       `json:"timing,omitempty"`
+	TLS     *TLSInfo      `json:"tls,omitempty"`
+	// DNS 与 Cert 仅用于外部检查
+	DNS  *DNSInfo  `json:"dns,omitempty"`
+	Cert *CertInfo `json:"cert,omitempty"`
+	// Via 为内部检查的执行方式：host（本机直连容器 IP）或 exec（SWAG 容器内 curl）
+	Via  string `json:"via,omitempty"`
+	Pass bool   `json:"pass"`
+	// Skipped 非空表示未执行检查及其原因（如静态站点、未知域名）
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Warnings 为不影响通过与否的提醒（如证书即将过期）
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Failed 判断检查是否执行且未通过
+func (r Result) Failed() bool {
+	return r.Skipped == "" && !r.Pass
+}
+
+// Check 为一项待执行的检查
+type Check func(ctx context.Context) Result
+
+// RunAll 以 workers 个并发执行检查，结果顺序与 checks 一致
+func RunAll(ctx context.Context, checks []Check, workers int) []Result {
+	if workers <= 0 {
+		workers = 1
+	}
+	results := make([]Result, len(checks))
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(checks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				results[i] = checks[i](ctx)
+			}
+		}()
+	}
+	for i := range checks {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	return results
+}
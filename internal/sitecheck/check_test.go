@@ -0,0 +1,104 @@
+package sitecheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadExpectations(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "site-checks.json")
+	mustWrite(t, p, `{"sites": {"app": {"path": "/health", "status": "200,3xx", "body": "ok"}}}`)
+	got, err := LoadExpectations(p)
+	if err != nil {
+		t.Fatalf("LoadExpectations() error = %v", err)
+	}
+	want := Expect{Path: "/health", Status: "200,3xx", Body: "ok"}
+	if got["app"] != want {
+		t.Fatalf("app = %+v, want %+v", got["app"], want)
+	}
+
+	for name, content := range map[string]string{
+		"bad status": `{"sites": {"app": {"status": "abc"}}}`,
+		"bad path":   `{"sites": {"app": {"path": "health"}}}`,
+		"bad json":   `{"sites": `,
+	} {
+		mustWrite(t, p, content)
+		if _, err := LoadExpectations(p); err == nil {
+			t.Fatalf("LoadExpectations(%s) should fail", name)
+		}
+	}
+
+	got, err = LoadExpectations(filepath.Join(dir, "missing.json"))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("LoadExpectations(missing) = %v, %v", got, err)
+	}
+}
+
+func TestExpectVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expect Expect
+		status int
+		body   string
+		ok     bool
+	}{
+		{Expect{}, 401, "", true},
+		{Expect{}, 502, "", false},
+		{Expect{Status: "200"}, 302, "", false},
+		{Expect{Status: "2xx,302"}, 302, "", true},
+		{Expect{Body: "healthy"}, 200, `{"status":"healthy"}`, true},
+		{Expect{Body: "healthy"}, 200, "starting", false},
+	}
+	for _, tt := range tests {
+		if err := tt.expect.Verify(tt.status, tt.body); (err == nil) != tt.ok {
+			t.Errorf("%+v.Verify(%d, %q) error = %v, want ok=%v", tt.expect, tt.status, tt.body, err, tt.ok)
+		}
+	}
+}
+
+func TestRunAllKeepsOrderAndLimitsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var running, peak int32
+	var checks []Check
+	for i := 0; i < 10; i++ {
+		site := string(rune('a' + i))
+		checks = append(checks, func(ctx context.Context) Result {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return Result{Site: site, Pass: true}
+		})
+	}
+
+	results := RunAll(context.Background(), checks, 3)
+	for i, r := range results {
+		if want := string(rune('a' + i)); r.Site != want {
+			t.Fatalf("results[%d].Site = %q, want %q", i, r.Site, want)
+		}
+	}
+	if peak > 3 {
+		t.Fatalf("peak concurrency = %d, want <= 3", peak)
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package sitecheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxBody 为读取响应内容（用于 Expect.Body）的上限
+const maxBody = 1 << 20
+
+// DefaultCertWarn 为证书即将过期的默认提醒时间
+const DefaultCertWarn = 14 * 24 * time.Hour
+
+// NewHTTPClient 返回外部检查使用的 HTTP 客户端：不跟随重定向，以便按原始状态码判断；
+// 证书由 ExternalChecker 自行校验，以便在证书无效时仍能报告状态码。
+// resolve 非空（IP 或 IP:端口）时所有请求都连接到该地址，SNI 与 Host 仍为站点域名（用于没有 NAT 回环的内网）。
+func NewHTTPClient(timeout time.Duration, resolve string) (*http.Client, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := &http.Transport{
+		DialContext:       dialer.DialContext,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}
+	if resolve != "" {
+		ip, port := resolve, ""
+		if h, p, err := net.SplitHostPort(resolve); err == nil {
+			ip, port = h, p
+		}
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("无效的 --resolve 地址: %s (示例: 192.168.1.10 或 192.168.1.10:443)", resolve)
+		}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			p := port
+			if p == "" {
+				_, p, _ = net.SplitHostPort(addr)
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ip, p))
+		}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// Resolver 为 DNS 查询，由 *net.Resolver 实现
+type Resolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// DNSInfo 为站点域名的 DNS 记录
+type DNSInfo struct {
+	CNAME string   `json:"cname,omitempty"`
+	A     []string `json:"a,omitempty"`
+	AAAA  []string `json:"aaaa,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// CertInfo 为站点证书的校验结果
+type CertInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dnsNames,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	// DaysLeft 为剩余有效天数，已过期时为负数
+	DaysLeft      int  `json:"daysLeft"`
+	Trusted       bool `json:"trusted"`
+	HostnameMatch bool `json:"hostnameMatch"`
+	// Expiring 表示剩余有效期低于 ExternalChecker.CertWarn
+	Expiring bool `json:"expiring,omitempty"`
+	// Error 为证书链校验失败的原因（不受信任、已过期等）
+	Error string `json:"error,omitempty"`
+}
+
+// Valid 判断证书受信任、在有效期内且与域名匹配
+func (c CertInfo) Valid() bool {
+	return c.Trusted && c.HostnameMatch
+}
+
+// ExternalChecker 从本机请求站点的公开地址，并报告 DNS 记录与证书状态
+type ExternalChecker struct {
+	Client *http.Client
+	// Resolver 为 nil 时不查询 DNS
+	Resolver Resolver
+	// Roots 为校验证书的根证书，nil 时使用系统根证书
+	Roots *x509.CertPool
+	// CertWarn 为证书即将过期的提醒时间，默认 DefaultCertWarn
+	CertWarn time.Duration
+}
+
+// Check 返回对 baseURL（https://<站点域名>）的检查
+func (e ExternalChecker) Check(site, baseURL string, expect Expect) Check {
+	return func(ctx context.Context) Result {
+		res := Result{Site: site, Kind: KindExternal, URL: baseURL + expect.RequestPath()}
+		u, err := url.Parse(res.URL)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		if e.Resolver != nil {
+			res.DNS = lookupDNS(ctx, e.Resolver, u.Hostname())
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, res.URL, nil)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		start := time.Now()
+		resp, err := e.Client.Do(req)
+		if err != nil {
+			res.Latency = time.Since(start)
+			res.Error = err.Error()
+			return res
+		}
+		defer resp.Body.Close()
+
+		var body []byte
+		if expect.Body != "" {
+			body, err = io.ReadAll(io.LimitReader(resp.Body, maxBody))
+			if err != nil {
+				res.Error = fmt.Sprintf("读取响应失败: %v", err)
+				return res
+			}
+		}
+		res.Latency = time.Since(start)
+		res.Status = resp.StatusCode
+		res.TLS = tlsInfo(resp.TLS)
+
+		if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+			now := time.Now()
+			res.Cert = verifyCert(resp.TLS.PeerCertificates, u.Hostname(), e.Roots, now)
+			warn := e.CertWarn
+			if warn <= 0 {
+				warn = DefaultCertWarn
+			}
+			if res.Cert.Trusted && res.Cert.NotAfter.Sub(now) < warn {
+				res.Cert.Expiring = true
+				res.Warnings = append(res.Warnings, fmt.Sprintf("证书将在 %d 天后过期 (%s)", res.Cert.DaysLeft, res.Cert.NotAfter.Format("2006-01-02")))
+			}
+			if !res.Cert.Valid() {
+				res.Error = certError(*res.Cert, u.Hostname())
+				return res
+			}
+		}
+
+		if err := expect.Verify(resp.StatusCode, string(body)); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Pass = true
+		return res
+	}
+}
+
+func lookupDNS(ctx context.Context, r Resolver, host string) *DNSInfo {
+	info := &DNSInfo{}
+	if cname, err := r.LookupCNAME(ctx, host); err == nil {
+		cname = strings.TrimSuffix(cname, ".")
+		if !strings.EqualFold(cname, host) {
+			info.CNAME = cname
+		}
+	}
+	var errs []string
+	for _, network := range []string{"ip4", "ip6"} {
+		ips, err := r.LookupIP(ctx, network, host)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, ip := range ips {
+			if network == "ip4" {
+				info.A = append(info.A, ip.String())
+			} else {
+				info.AAAA = append(info.AAAA, ip.String())
+			}
+		}
+	}
+	if len(info.A) == 0 && len(info.AAAA) == 0 && len(errs) > 0 {
+		info.Error = errs[0]
+	}
+	return info
+}
+
+// verifyCert 校验证书链（使用服务器发送的中间证书）、有效期与域名
+func verifyCert(chain []*x509.Certificate, host string, roots *x509.CertPool, now time.Time) *CertInfo {
+	leaf := chain[0]
+	info := &CertInfo{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		DNSNames:  leaf.DNSNames,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+		DaysLeft:  int(math.Floor(leaf.NotAfter.Sub(now).Hours() / 24)),
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now})
+	info.Trusted = err == nil
+	if err != nil {
+		info.Error = err.Error()
+	}
+	info.HostnameMatch = leaf.VerifyHostname(host) == nil
+	return info
+}
+
+func certError(c CertInfo, host string) string {
+	var reasons []string
+	if !c.Trusted {
+		reasons = append(reasons, c.Error)
+	}
+	if !c.HostnameMatch {
+		reasons = append(reasons, fmt.Sprintf("证书不包含 %s (%s)", host, strings.Join(c.DNSNames, ", ")))
+	}
+	return "证书无效: " + strings.Join(reasons, "; ")
+}
+
+func tlsInfo(cs *tls.ConnectionState) *TLSInfo {
+	if cs == nil {
+		return nil
+	}
+	info := &TLSInfo{
+		Version: tls.VersionName(cs.Version),
+		Cipher:  tls.CipherSuiteName(cs.CipherSuite),
+	}
+	if len(cs.PeerCertificates) > 0 {
+		cert := cs.PeerCertificates[0]
+		info.Subject = cert.Subject.String()
+		info.NotAfter = cert.NotAfter
+	}
+	return info
+}
@@ -0,0 +1,122 @@
+package sitecheck
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExternalResolveAndCert(t *testing.T) {
+	t.Parallel()
+
+	srv := newExternalServer(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	// httptest 的证书包含 example.com；--resolve 使请求连接到本地服务器，SNI 仍为 example.com
+	client, err := NewHTTPClient(time.Second, srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	checker := ExternalChecker{
+		Client:   client,
+		Roots:    roots,
+		Resolver: fakeResolver{cname: "edge.example.net.", a: []string{"203.0.113.10"}},
+		CertWarn: 100 * 365 * 24 * time.Hour,
+	}
+
+	res := checker.Check("app", "https://example.com", Expect{Path: "/health", Body: "ok"})(context.Background())
+	if !res.Pass || res.Status != 200 {
+		t.Fatalf("Check() = %+v, want pass", res)
+	}
+	if res.Cert == nil || !res.Cert.Valid() || res.Cert.DaysLeft <= 0 {
+		t.Fatalf("Cert = %+v, want valid", res.Cert)
+	}
+	if !res.Cert.Expiring || len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "过期") {
+		t.Fatalf("Warnings = %v, want expiry warning", res.Warnings)
+	}
+	want := &DNSInfo{CNAME: "edge.example.net", A: []string{"203.0.113.10"}}
+	if !reflect.DeepEqual(res.DNS, want) {
+		t.Fatalf("DNS = %+v, want %+v", res.DNS, want)
+	}
+
+	// 不跟随重定向，按原始状态码判断
+	res = checker.Check("app", "https://example.com", Expect{Status: "200"})(context.Background())
+	if res.Pass || res.Status != http.StatusFound || !strings.Contains(res.Error, "302") {
+		t.Fatalf("Check(/) = %+v, want 302 failure", res)
+	}
+}
+
+func TestExternalInvalidCert(t *testing.T) {
+	t.Parallel()
+
+	srv := newExternalServer(t)
+	client, err := NewHTTPClient(time.Second, srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+
+	// 系统根证书不信任 httptest 的证书，但仍报告状态码
+	res := ExternalChecker{Client: client}.Check("app", "https://example.com", Expect{Path: "/health"})(context.Background())
+	if res.Pass || res.Status != 200 || res.Cert == nil || res.Cert.Trusted || !strings.Contains(res.Error, "证书无效") {
+		t.Fatalf("Check(untrusted) = %+v", res)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	res = ExternalChecker{Client: client, Roots: roots}.Check("app", "https://app.home.arpa", Expect{Path: "/health"})(context.Background())
+	if res.Pass || res.Cert == nil || res.Cert.HostnameMatch || !strings.Contains(res.Error, "app.home.arpa") {
+		t.Fatalf("Check(hostname mismatch) = %+v", res)
+	}
+}
+
+func TestNewHTTPClientRejectsInvalidResolve(t *testing.T) {
+	t.Parallel()
+
+	for _, resolve := range []string{"swag.local", "1.2.3:443"} {
+		if _, err := NewHTTPClient(time.Second, resolve); err == nil {
+			t.Fatalf("NewHTTPClient(%q) should fail", resolve)
+		}
+	}
+}
+
+func newExternalServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte("ok"))
+		default:
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type fakeResolver struct {
+	cname string
+	a     []string
+}
+
+func (f fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return f.cname, nil
+}
+
+func (f fakeResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	if network != "ip4" {
+		return nil, errors.New("no such host")
+	}
+	var ips []net.IP
+	for _, s := range f.a {
+		ips = append(ips, net.ParseIP(s))
+	}
+	return ips, nil
+}
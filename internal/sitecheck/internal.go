@@ -0,0 +1,171 @@
+package sitecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"swag-cli/internal/docker"
+)
+
+// Docker 为内部检查所需的 Docker 操作，由 *docker.Client 实现
+type Docker interface {
+	Exec(ctx context.Context, containerName string, cmd []string) (string, error)
+}
+
+const (
+	writeOutMarker = "__SWAG_CLI_RESULT__"
+	verboseMarker  = "__SWAG_CLI_VERBOSE__"
+)
+
+// curlScript 将 curl 的响应内容与 -w 结果输出到 stdout，随后输出 -v 的连接信息（stderr），以区分两者
+const curlScript = `exec 3>&1; v=$(curl "$@" 2>&1 1>&3); rc=$?; printf '\n%s\n%s\n' "` + verboseMarker + `" "$v"; exit $rc`
+
+// Internal 在 SWAG 容器内用 curl 请求上游（$upstream_proto://$upstream_app:$upstream_port）
+func Internal(d Docker, container, site, upstreamURL string, expect Expect, timeout time.Duration) Check {
+	return func(ctx context.Context) Result {
+		reqURL := upstreamURL + expect.RequestPath()
+		res := Result{Site: site, Kind: KindInternal, URL: reqURL, Via: ViaExec}
+		if expect.TCP {
+			res.URL = tcpURL(upstreamURL)
+		}
+		out, err := d.Exec(ctx, container, CurlCommand(reqURL, expect.Body != "" && !expect.TCP, timeout))
+		var ee *docker.ExecError
+		if errors.As(err, &ee) {
+			out = ee.Stdout + ee.Stderr
+		} else if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+
+		co := ParseCurlOutput(out)
+		res.Status, res.Latency, res.Timing, res.TLS = co.Status, co.Latency, co.Timing, co.TLS
+		switch {
+		case ee != nil && ee.ExitCode == 127:
+			res.Error = "SWAG 容器内没有 curl"
+			return res
+		case expect.TCP:
+			// 只要求 TCP 可连接：curl 记录了连接耗时即表示已建立连接
+			res.Status = 0
+			if co.Timing == nil || co.Timing.Connect == 0 {
+				res.Error = "无法建立 TCP 连接"
+				if co.Error != "" {
+					res.Error = co.Error
+				}
+				return res
+			}
+			res.Pass = true
+			return res
+		case ee != nil && co.Error != "":
+			res.Error = co.Error
+			return res
+		case ee != nil:
+			res.Error = fmt.Sprintf("curl 退出码 %d", ee.ExitCode)
+			return res
+		case co.Status == 0:
+			res.Error = "无法解析 curl 输出"
+			return res
+		}
+
+		if err := expect.Verify(co.Status, co.Body); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Pass = true
+		return res
+	}
+}
+
+// CurlCommand 返回在容器内执行的 curl 命令；不校验上游证书（内部服务多为自签证书）
+func CurlCommand(url string, withBody bool, timeout time.Duration) []string {
+	secs := int(timeout.Round(time.Second) / time.Second)
+	if secs <= 0 {
+		secs = 5
+	}
+	args := []string{"sh", "-c", curlScript, "curl", "-sS", "-v", "-k", "-m", strconv.Itoa(secs)}
+	if withBody {
+		args = append(args, "--max-filesize", strconv.Itoa(maxBody))
+	} else {
+		args = append(args, "-o", "/dev/null")
+	}
+	return append(args, "-w", "\n"+writeOutMarker+" %{http_code} %{time_total} %{time_connect} %{time_appconnect} %{time_starttransfer}\n", url)
+}
+
+// CurlOutput 为 CurlCommand 输出解析后的结果
+type CurlOutput struct {
+	Status  int
+	Latency time.Duration
+	Timing  *Timing
+	Body    string
+	TLS     *TLSInfo
+	// Error 为 curl 的错误信息（curl: (7) ...）
+	Error string
+}
+
+var (
+	curlErrRe     = regexp.MustCompile(`(?m)^curl: \(\d+\) (.+)$`)
+	sslConnRe     = regexp.MustCompile(`(?m)^\* SSL connection using (\S+) / (\S+)`)
+	certSubjectRe = regexp.MustCompile(`(?m)^\*\s+subject: (.+)$`)
+	certExpireRe  = regexp.MustCompile(`(?m)^\*\s+expire date: (.+)$`)
+)
+
+const curlExpireLayout = "Jan _2 15:04:05 2006 GMT"
+
+// ParseCurlOutput 解析 CurlCommand 的输出
+func ParseCurlOutput(out string) CurlOutput {
+	var co CurlOutput
+	stdout, verbose := out, ""
+	if i := strings.LastIndex(out, "\n"+verboseMarker+"\n"); i >= 0 {
+		stdout, verbose = out[:i], out[i+len(verboseMarker)+2:]
+	}
+
+	if i := strings.LastIndex(stdout, writeOutMarker+" "); i >= 0 {
+		fields := strings.Fields(stdout[i+len(writeOutMarker):])
+		if len(fields) >= 2 {
+			co.Status, _ = strconv.Atoi(fields[0])
+			co.Latency = curlSeconds(fields[1])
+		}
+		if len(fields) >= 5 {
+			co.Timing = &Timing{Connect: curlSeconds(fields[2]), TLS: curlSeconds(fields[3]), FirstByte: curlSeconds(fields[4])}
+		}
+		co.Body = strings.TrimSuffix(stdout[:i], "\n")
+	}
+
+	if m := curlErrRe.FindStringSubmatch(verbose); m != nil {
+		co.Error = strings.TrimSpace(m[1])
+	}
+	if m := sslConnRe.FindStringSubmatch(verbose); m != nil {
+		co.TLS = &TLSInfo{Version: m[1], Cipher: m[2]}
+		if sm := certSubjectRe.FindStringSubmatch(verbose); sm != nil {
+			co.TLS.Subject = strings.TrimSpace(sm[1])
+		}
+		if em := certExpireRe.FindStringSubmatch(verbose); em != nil {
+			if t, err := time.Parse(curlExpireLayout, strings.TrimSpace(em[1])); err == nil {
+				co.TLS.NotAfter = t
+			}
+		}
+	}
+	return co
+}
+
+// curlSeconds 解析 curl -w 输出的秒数，无法解析时返回 0
+func curlSeconds(s string) time.Duration {
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(sec * float64(time.Second))
+}
+
+// tcpURL 将 http(s)://host:port 转换为 tcp://host:port，用于显示 TCP 检查
+func tcpURL(upstreamURL string) string {
+	if u, err := url.Parse(upstreamURL); err == nil && u.Host != "" {
+		return "tcp://" + u.Host
+	}
+	return upstreamURL
+}
@@ -0,0 +1,105 @@
+package sitecheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"swag-cli/internal/docker"
+)
+
+const curlTLSOutput = `<html>ok</html>
+__SWAG_CLI_RESULT__ 200 0.012345
+
+__SWAG_CLI_VERBOSE__
+*   Trying 172.20.0.5:8443...
+* Connected to app (172.20.0.5) port 8443
+* SSL connection using TLSv1.3 / TLS_AES_256_GCM_SHA384 / x25519 / RSASSA-PSS
+* Server certificate:
+*  subject: CN=app
+*  start date: Jan  1 00:00:00 2026 GMT
+*  expire date: Mar  1 12:00:00 2027 GMT
+> GET / HTTP/1.1
+< HTTP/1.1 200 OK
+`
+
+const curlRefusedOutput = `
+__SWAG_CLI_RESULT__ 000 0.001000
+
+__SWAG_CLI_VERBOSE__
+*   Trying 172.20.0.5:8080...
+* connect to 172.20.0.5 port 8080 failed: Connection refused
+curl: (7) Failed to connect to app port 8080 after 1 ms: Couldn't connect to server
+`
+
+func TestParseCurlOutput(t *testing.T) {
+	t.Parallel()
+
+	co := ParseCurlOutput(curlTLSOutput)
+	if co.Status != 200 || co.Latency != 12345*time.Microsecond || co.Body != "<html>ok</html>" {
+		t.Fatalf("ParseCurlOutput() = %+v", co)
+	}
+	want := TLSInfo{Version: "TLSv1.3", Cipher: "TLS_AES_256_GCM_SHA384", Subject: "CN=app", NotAfter: time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if co.TLS == nil || *co.TLS != want {
+		t.Fatalf("TLS = %+v, want %+v", co.TLS, want)
+	}
+
+	co = ParseCurlOutput(curlRefusedOutput)
+	if co.Status != 0 || !strings.Contains(co.Error, "Failed to connect") || co.TLS != nil {
+		t.Fatalf("ParseCurlOutput(refused) = %+v", co)
+	}
+}
+
+func TestInternal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		out    string
+		err    error
+		expect Expect
+		pass   bool
+		errSub string
+	}{
+		{"pass", curlTLSOutput, nil, Expect{Body: "ok"}, true, ""},
+		{"body mismatch", curlTLSOutput, nil, Expect{Body: "healthy"}, false, "healthy"},
+		{"refused", "", &docker.ExecError{ExitCode: 7, Stdout: curlRefusedOutput}, Expect{}, false, "Failed to connect"},
+		{"no curl", "", &docker.ExecError{ExitCode: 127, Stdout: "sh: curl: not found"}, Expect{}, false, "没有 curl"},
+	}
+	for _, tt := range tests {
+		d := &fakeDocker{out: tt.out, err: tt.err}
+		res := Internal(d, "swag", "app", "https://app:8443", tt.expect, 5*time.Second)(context.Background())
+		if res.Pass != tt.pass || !strings.Contains(res.Error, tt.errSub) {
+			t.Fatalf("%s: Internal() = %+v", tt.name, res)
+		}
+		if d.container != "swag" || d.cmd[len(d.cmd)-1] != "https://app:8443/" {
+			t.Fatalf("%s: exec %s %v", tt.name, d.container, d.cmd)
+		}
+	}
+}
+
+func TestCurlCommandDiscardsBodyUnlessNeeded(t *testing.T) {
+	t.Parallel()
+
+	cmd := strings.Join(CurlCommand("http://app:80/", false, 3*time.Second), " ")
+	if !strings.Contains(cmd, "-o /dev/null") || !strings.Contains(cmd, "-m 3") {
+		t.Fatalf("CurlCommand() = %s", cmd)
+	}
+	cmd = strings.Join(CurlCommand("http://app:80/", true, 3*time.Second), " ")
+	if strings.Contains(cmd, "/dev/null") {
+		t.Fatalf("CurlCommand(withBody) = %s", cmd)
+	}
+}
+
+type fakeDocker struct {
+	out       string
+	err       error
+	container string
+	cmd       []string
+}
+
+func (f *fakeDocker) Exec(ctx context.Context, containerName string, cmd []string) (string, error) {
+	f.container, f.cmd = containerName, cmd
+	return f.out, f.err
+}
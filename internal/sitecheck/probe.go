@@ -0,0 +1,145 @@
+package sitecheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Via 表示内部检查的执行方式
+const (
+	ViaHost = "host"
+	ViaExec = "exec"
+)
+
+// Timing 为请求各阶段的耗时（自请求开始计）
+type Timing struct {
+	Connect   time.Duration `json:"connect,omitempty"`
+	TLS       time.Duration `json:"tls,omitempty"`
+	FirstByte time.Duration `json:"firstByte,omitempty"`
+}
+
+// errUnreachable 表示本机无法访问上游所在的网络（如 Docker Desktop 的容器网络），需要改为在 SWAG 容器内检查
+var errUnreachable = errors.New("本机无法访问上游网络")
+
+// Native 从本机直接连接上游的容器 IP（addr 为 ip:port）进行 TCP/HTTP 检查；
+// 本机无法访问该网络且 fallback 非 nil 时改用 fallback（通常为 Internal）
+func Native(site, upstreamURL, addr string, expect Expect, timeout time.Duration, fallback Check) Check {
+	return func(ctx context.Context) Result {
+		res, err := probe(ctx, site, upstreamURL, addr, expect, timeout)
+		if errors.Is(err, errUnreachable) && fallback != nil {
+			return fallback(ctx)
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		return res
+	}
+}
+
+func probe(ctx context.Context, site, upstreamURL, addr string, expect Expect, timeout time.Duration) (Result, error) {
+	res := Result{Site: site, Kind: KindInternal, URL: upstreamURL + expect.RequestPath(), Via: ViaHost}
+	u, err := url.Parse(res.URL)
+	if err != nil {
+		return res, err
+	}
+	if expect.TCP {
+		res.URL = tcpURL(upstreamURL)
+	}
+
+	// 先单独建立 TCP 连接，以区分网络不可达与服务未监听
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		res.Latency = time.Since(start)
+		if unreachable(err) {
+			return res, fmt.Errorf("%w: %v", errUnreachable, err)
+		}
+		return res, err
+	}
+	res.Timing = &Timing{Connect: time.Since(start)}
+	if expect.TCP {
+		conn.Close()
+		res.Latency = res.Timing.Connect
+		res.Pass = true
+		return res, nil
+	}
+
+	// HTTP 请求复用已建立的连接，耗时从建立连接开始计
+	used := false
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			if !used {
+				used = true
+				return conn, nil
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true, ServerName: u.Hostname()},
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			res.Timing.TLS = time.Since(start)
+		},
+		GotFirstResponseByte: func() {
+			res.Timing.FirstByte = time.Since(start)
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, res.URL, nil)
+	if err != nil {
+		conn.Close()
+		return res, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Latency = time.Since(start)
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if expect.Body != "" {
+		if body, err = io.ReadAll(io.LimitReader(resp.Body, maxBody)); err != nil {
+			return res, fmt.Errorf("读取响应失败: %w", err)
+		}
+	}
+	res.Latency = time.Since(start)
+	res.Status = resp.StatusCode
+	res.TLS = tlsInfo(resp.TLS)
+	if err := expect.Verify(resp.StatusCode, string(body)); err != nil {
+		return res, err
+	}
+	res.Pass = true
+	return res, nil
+}
+
+// unreachable 判断连接错误是否表示本机无法访问该网络；连接被拒绝说明网络可达、服务未监听
+func unreachable(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH)
+}
@@ -0,0 +1,96 @@
+package sitecheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNativeProbe(t *testing.T) {
+	t.Parallel()
+
+	var gotHost string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		w.Write([]byte("healthy"))
+	}))
+	defer srv.Close()
+	addr := srv.Listener.Addr().String()
+
+	fallback := func(ctx context.Context) Result {
+		t.Fatalf("fallback should not run when the upstream is reachable")
+		return Result{}
+	}
+	res := Native("app", "http://app:8080", addr, Expect{Path: "/health", Body: "healthy"}, time.Second, fallback)(context.Background())
+	if !res.Pass || res.Status != 200 || res.Via != ViaHost || res.URL != "http://app:8080/health" {
+		t.Fatalf("Native() = %+v, want pass via host", res)
+	}
+	if res.Timing == nil || res.Timing.Connect <= 0 || res.Timing.FirstByte < res.Timing.Connect {
+		t.Fatalf("Timing = %+v, want connect and first byte", res.Timing)
+	}
+	if gotHost != "app:8080" {
+		t.Fatalf("Host = %q, want upstream host", gotHost)
+	}
+
+	res = Native("app", "http://app:8080", addr, Expect{TCP: true}, time.Second, fallback)(context.Background())
+	if !res.Pass || res.Status != 0 || res.URL != "tcp://app:8080" {
+		t.Fatalf("Native(tcp) = %+v, want tcp pass", res)
+	}
+}
+
+func TestNativeProbeRefusedDoesNotFallBack(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	called := false
+	fallback := func(ctx context.Context) Result {
+		called = true
+		return Result{Via: ViaExec}
+	}
+	res := Native("app", "http://app:8080", addr, Expect{}, time.Second, fallback)(context.Background())
+	if called || res.Pass || res.Via != ViaHost || !strings.Contains(res.Error, "refused") {
+		t.Fatalf("Native(refused) = %+v, fallback called = %v", res, called)
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	t.Parallel()
+
+	dialErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"refused", dialErr(syscall.ECONNREFUSED), false},
+		{"host unreachable", dialErr(syscall.EHOSTUNREACH), true},
+		{"network unreachable", dialErr(syscall.ENETUNREACH), true},
+		{"timeout", &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}, true},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := unreachable(tt.err); got != tt.want {
+			t.Errorf("unreachable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
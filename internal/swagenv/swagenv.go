@@ -0,0 +1,216 @@
+// Package swagenv 解析 SWAG 容器中与证书相关的环境变量
+// (URL / SUBDOMAINS / EXTRA_DOMAINS / ONLY_SUBDOMAINS / VALIDATION)，
+// 并判断某个域名是否被证书覆盖。
+package swagenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"swag-cli/internal/compose"
+)
+
+// Env 表示 SWAG 证书相关配置
+type Env struct {
+	URL            string
+	Subdomains     []string // 不含 "wildcard"
+	Wildcard       bool
+	ExtraDomains   []string
+	OnlySubdomains bool
+	Validation     string
+}
+
+// FromList 从 "KEY=VALUE" 形式的环境变量列表解析（如 docker inspect 的 Config.Env）
+func FromList(env []string) Env {
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		m[strings.TrimSpace(k)] = v
+	}
+	return FromMap(m)
+}
+
+// FromMap 从环境变量 map 解析
+func FromMap(m map[string]string) Env {
+	e := Env{
+		URL:            normalizeHost(m["URL"]),
+		OnlySubdomains: strings.EqualFold(strings.TrimSpace(m["ONLY_SUBDOMAINS"]), "true"),
+		Validation:     strings.ToLower(strings.TrimSpace(m["VALIDATION"])),
+	}
+	for _, s := range splitList(m["SUBDOMAINS"]) {
+		if strings.EqualFold(s, "wildcard") {
+			e.Wildcard = true
+			continue
+		}
+		e.Subdomains = append(e.Subdomains, strings.ToLower(s))
+	}
+	for _, d := range splitList(m["EXTRA_DOMAINS"]) {
+		e.ExtraDomains = append(e.ExtraDomains, normalizeHost(d))
+	}
+	return e
+}
+
+// Host 返回子域名对应的完整主机名
+func (e Env) Host(subdomain string) string {
+	subdomain = normalizeHost(subdomain)
+	if e.URL == "" {
+		return subdomain
+	}
+	return subdomain + "." + e.URL
+}
+
+// Covers 判断证书是否覆盖指定主机名
+func (e Env) Covers(host string) bool {
+	host = normalizeHost(host)
+	if host == "" || e.URL == "" {
+		return false
+	}
+
+	if host == e.URL {
+		return !e.OnlySubdomains
+	}
+
+	if sub, ok := strings.CutSuffix(host, "."+e.URL); ok {
+		if e.Wildcard && !strings.Contains(sub, ".") {
+			return true
+		}
+		for _, s := range e.Subdomains {
+			if s == sub {
+				return true
+			}
+		}
+	}
+
+	for _, d := range e.ExtraDomains {
+		if matchDomain(d, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoversSubdomain 判断 <subdomain>.<URL> 是否被证书覆盖
+func (e Env) CoversSubdomain(subdomain string) bool {
+	return e.Covers(e.Host(subdomain))
+}
+
+// AddSubdomain 将子域名追加到 SUBDOMAINS 原始值中，返回新值；若已包含（或为 wildcard）则 changed=false
+func AddSubdomain(value, subdomain string) (string, bool) {
+	subdomain = normalizeHost(subdomain)
+	items := splitList(value)
+	for _, s := range items {
+		if strings.EqualFold(s, "wildcard") || strings.EqualFold(s, subdomain) {
+			return value, false
+		}
+	}
+	items = append(items, subdomain)
+	return strings.Join(items, ","), true
+}
+
+// ErrNoURL 表示 SWAG 容器未设置 URL，无法确定基础域名
+var ErrNoURL = errors.New("SWAG 容器未设置 URL 环境变量")
+
+// Coverage 描述新站点的证书覆盖情况
+type Coverage struct {
+	Subdomain string
+	Host      string
+	// Covered 表示当前运行中的 SWAG 证书已覆盖该主机名
+	Covered bool
+
+	// 以下字段仅在未覆盖且 compose 文件可用时填充
+	ComposePath    string
+	ComposeService string
+	// ComposeCovered 表示 compose.yaml 已包含该子域名，但容器尚未重建
+	ComposeCovered bool
+	OldSubdomains  string
+	NewSubdomains  string
+}
+
+// CheckCoverage 结合容器当前环境变量与 compose 文件，检查子域名的证书覆盖情况。
+//
+// liveEnv 为容器的 Config.Env；composePath/containerName 用于定位 compose 中的 SWAG 服务。
+// compose 文件读取失败时返回 Coverage 及错误，调用方可仅展示覆盖结果。
+func CheckCoverage(liveEnv []string, composePath string, containerName string, subdomain string) (Coverage, error) {
+	live := FromList(liveEnv)
+	c := Coverage{
+		Subdomain: normalizeHost(subdomain),
+		Host:      live.Host(subdomain),
+		Covered:   live.CoversSubdomain(subdomain),
+	}
+	if live.URL == "" {
+		return c, ErrNoURL
+	}
+	if c.Covered {
+		return c, nil
+	}
+
+	f, err := compose.Load(composePath)
+	if err != nil {
+		return c, err
+	}
+	service, err := f.FindService(containerName)
+	if err != nil {
+		return c, err
+	}
+	env, err := f.EnvMap(service)
+	if err != nil {
+		return c, err
+	}
+
+	c.ComposePath = composePath
+	c.ComposeService = service
+	c.ComposeCovered = FromMap(env).Covers(c.Host)
+	c.OldSubdomains = env["SUBDOMAINS"]
+	c.NewSubdomains, _ = AddSubdomain(c.OldSubdomains, subdomain)
+	return c, nil
+}
+
+// ApplyCoverage 将 Coverage.NewSubdomains 写入 compose 文件，返回备份路径
+func ApplyCoverage(c Coverage) (string, error) {
+	if c.ComposePath == "" || c.ComposeService == "" {
+		return "", fmt.Errorf("未定位到 compose 文件中的 SWAG 服务")
+	}
+	if c.ComposeCovered {
+		return "", nil
+	}
+
+	f, err := compose.Load(c.ComposePath)
+	if err != nil {
+		return "", err
+	}
+	changed, err := f.SetEnv(c.ComposeService, "SUBDOMAINS", c.NewSubdomains)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return "", nil
+	}
+	return f.Save()
+}
+
+func matchDomain(pattern, host string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		sub, ok := strings.CutSuffix(host, "."+rest)
+		return ok && sub != "" && !strings.Contains(sub, ".")
+	}
+	return pattern == host
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func normalizeHost(s string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+}
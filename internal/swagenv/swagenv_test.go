@@ -0,0 +1,107 @@
+package swagenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCovers(t *testing.T) {
+	t.Parallel()
+
+	e := FromList([]string{
+		"URL=example.com",
+		"SUBDOMAINS=www, FTP",
+		"EXTRA_DOMAINS=example.org,*.example.net",
+		"VALIDATION=http",
+	})
+
+	cases := map[string]bool{
+		"example.com":       true,
+		"www.example.com":   true,
+		"ftp.example.com":   true,
+		"app.example.com":   false,
+		"example.org":       true,
+		"a.example.org":     false,
+		"a.example.net":     true,
+		"a.b.example.net":   false,
+		"WWW.Example.Com.":  true,
+		"www.example.com.x": false,
+	}
+	for host, want := range cases {
+		if got := e.Covers(host); got != want {
+			t.Errorf("Covers(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestCoversWildcardAndOnlySubdomains(t *testing.T) {
+	t.Parallel()
+
+	e := FromList([]string{"URL=example.com", "SUBDOMAINS=wildcard", "ONLY_SUBDOMAINS=true", "VALIDATION=dns"})
+	if !e.CoversSubdomain("app") {
+		t.Fatalf("wildcard should cover app.example.com")
+	}
+	if e.CoversSubdomain("a.b") {
+		t.Fatalf("wildcard should not cover two-level subdomains")
+	}
+	if e.Covers("example.com") {
+		t.Fatalf("ONLY_SUBDOMAINS=true should not cover the root domain")
+	}
+}
+
+func TestAddSubdomain(t *testing.T) {
+	t.Parallel()
+
+	if got, changed := AddSubdomain("www,ftp", "app"); !changed || got != "www,ftp,app" {
+		t.Fatalf("AddSubdomain() = %q, %v", got, changed)
+	}
+	if got, changed := AddSubdomain("", "app"); !changed || got != "app" {
+		t.Fatalf("AddSubdomain() = %q, %v", got, changed)
+	}
+	if _, changed := AddSubdomain("www,APP", "app"); changed {
+		t.Fatalf("AddSubdomain() should be no-op when already present")
+	}
+	if _, changed := AddSubdomain("wildcard", "app"); changed {
+		t.Fatalf("AddSubdomain() should be no-op for wildcard")
+	}
+}
+
+func TestCheckAndApplyCoverage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "compose.yaml")
+	content := "services:\n  swag:\n    image: lscr.io/linuxserver/swag\n    environment:\n      - URL=example.com\n      - SUBDOMAINS=www\n"
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatalf("write compose error = %v", err)
+	}
+
+	live := []string{"URL=example.com", "SUBDOMAINS=www"}
+	c, err := CheckCoverage(live, p, "swag", "www")
+	if err != nil || !c.Covered {
+		t.Fatalf("www should be covered: %+v err=%v", c, err)
+	}
+
+	c, err = CheckCoverage(live, p, "swag", "app")
+	if err != nil {
+		t.Fatalf("CheckCoverage() error = %v", err)
+	}
+	if c.Covered || c.ComposeCovered || c.ComposeService != "swag" || c.NewSubdomains != "www,app" {
+		t.Fatalf("unexpected coverage: %+v", c)
+	}
+
+	if _, err := ApplyCoverage(c); err != nil {
+		t.Fatalf("ApplyCoverage() error = %v", err)
+	}
+	b, _ := os.ReadFile(p)
+	if !strings.Contains(string(b), "- SUBDOMAINS=www,app\n") {
+		t.Fatalf("compose not updated:\n%s", b)
+	}
+
+	c, err = CheckCoverage(live, p, "swag", "app")
+	if err != nil || c.Covered || !c.ComposeCovered {
+		t.Fatalf("compose should now cover app while container does not: %+v err=%v", c, err)
+	}
+}
@@ -0,0 +1,505 @@
+package swagexport
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveFormat 为导出归档的格式
+type ArchiveFormat string
+
+const (
+	FormatZip    ArchiveFormat = "zip"
+	FormatTarGz  ArchiveFormat = "tar.gz"
+	FormatTarZst ArchiveFormat = "tar.zst"
+)
+
+// ArchiveFormats 返回支持的归档格式
+func ArchiveFormats() []ArchiveFormat {
+	return []ArchiveFormat{FormatZip, FormatTarGz, FormatTarZst}
+}
+
+// ParseArchiveFormat 解析归档格式，支持 tgz/tzst 简写；空字符串返回空格式（由输出路径推断）
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), ".")) {
+	case "":
+		return "", nil
+	case "zip":
+		return FormatZip, nil
+	case "tar.gz", "tgz":
+		return FormatTarGz, nil
+	case "tar.zst", "tzst":
+		return FormatTarZst, nil
+	}
+	return "", fmt.Errorf("不支持的归档格式: %s (可选: zip, tar.gz, tar.zst)", s)
+}
+
+// Ext 返回格式对应的文件扩展名
+func (f ArchiveFormat) Ext() string {
+	return "." + string(f)
+}
+
+// FormatFromPath 根据文件扩展名（忽略 .age 后缀）推断归档格式
+func FormatFromPath(p string) (ArchiveFormat, bool) {
+	l := strings.TrimSuffix(strings.ToLower(p), ".age")
+	switch {
+	case strings.HasSuffix(l, ".zip"):
+		return FormatZip, true
+	case strings.HasSuffix(l, ".tar.gz"), strings.HasSuffix(l, ".tgz"):
+		return FormatTarGz, true
+	case strings.HasSuffix(l, ".tar.zst"), strings.HasSuffix(l, ".tzst"):
+		return FormatTarZst, true
+	}
+	return "", false
+}
+
+// archiveWriter 将文件写入某种格式的归档
+type archiveWriter interface {
+	// writeFile 写入单个文件或符号链接，返回写入字节数与内容的 sha256（符号链接为其目标路径）
+	writeFile(e fileEntry) (int64, string, error)
+	writeBytes(relPath string, data []byte, mode os.FileMode, modTime time.Time) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case FormatZip, "":
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	case FormatTarGz:
+		gw := gzip.NewWriter(w)
+		return &tarArchiveWriter{tw: tar.NewWriter(gw), comp: gw}, nil
+	case FormatTarZst:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("初始化 zstd 压缩失败: %w", err)
+		}
+		return &tarArchiveWriter{tw: tar.NewWriter(zw), comp: zw}, nil
+	}
+	return nil, fmt.Errorf("不支持的归档格式: %s", format)
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) writeFile(e fileEntry) (int64, string, error) {
+	h := &zip.FileHeader{
+		Name:     toZipPath(e.RelPath),
+		Method:   zip.Deflate,
+		Modified: e.ModTime,
+	}
+	h.SetMode(e.Mode)
+
+	// 与 Info-ZIP 一致：符号链接条目的内容为目标路径
+	if e.Linkname != "" {
+		h.Method = zip.Store
+		if err := w.writeHeader(h, []byte(e.Linkname)); err != nil {
+			return 0, "", err
+		}
+		return int64(len(e.Linkname)), sha256Hex([]byte(e.Linkname)), nil
+	}
+
+	f, err := os.Open(e.AbsPath)
+	if err != nil {
+		return 0, "", fmt.Errorf("打开文件失败 (%s): %w", e.AbsPath, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zf, err := w.zw.CreateHeader(h)
+	if err != nil {
+		return 0, "", fmt.Errorf("写入 zip 条目失败 (%s): %w", e.RelPath, err)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(zf, hash), f)
+	if err != nil {
+		return n, "", fmt.Errorf("写入 zip 内容失败 (%s): %w", e.RelPath, err)
+	}
+	return n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (w *zipArchiveWriter) writeBytes(relPath string, data []byte, mode os.FileMode, modTime time.Time) error {
+	h := &zip.FileHeader{
+		Name:     toZipPath(relPath),
+		Method:   zip.Deflate,
+		Modified: modTime,
+	}
+	h.SetMode(mode)
+	return w.writeHeader(h, data)
+}
+
+func (w *zipArchiveWriter) writeHeader(h *zip.FileHeader, data []byte) error {
+	zf, err := w.zw.CreateHeader(h)
+	if err != nil {
+		return fmt.Errorf("写入 zip 条目失败 (%s): %w", h.Name, err)
+	}
+	if _, err := zf.Write(data); err != nil {
+		return fmt.Errorf("写入 zip 内容失败 (%s): %w", h.Name, err)
+	}
+	return nil
+}
+
+func (w *zipArchiveWriter) Close() error {
+	if err := w.zw.Close(); err != nil {
+		return fmt.Errorf("写入 zip 失败: %w", err)
+	}
+	return nil
+}
+
+type tarArchiveWriter struct {
+	tw   *tar.Writer
+	comp io.WriteCloser
+}
+
+func (w *tarArchiveWriter) writeFile(e fileEntry) (int64, string, error) {
+	h := &tar.Header{
+		Name:    toZipPath(e.RelPath),
+		Mode:    int64(e.Mode.Perm()),
+		ModTime: e.ModTime,
+		Format:  tar.FormatPAX,
+	}
+	if e.Linkname != "" {
+		h.Typeflag = tar.TypeSymlink
+		h.Linkname = e.Linkname
+		if err := w.tw.WriteHeader(h); err != nil {
+			return 0, "", fmt.Errorf("写入 tar 条目失败 (%s): %w", e.RelPath, err)
+		}
+		return int64(len(e.Linkname)), sha256Hex([]byte(e.Linkname)), nil
+	}
+
+	f, err := os.Open(e.AbsPath)
+	if err != nil {
+		return 0, "", fmt.Errorf("打开文件失败 (%s): %w", e.AbsPath, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// tar 需要预先写入大小，以打开后的大小为准，避免规划后文件变化导致条目损坏
+	st, err := f.Stat()
+	if err != nil {
+		return 0, "", fmt.Errorf("读取文件失败 (%s): %w", e.AbsPath, err)
+	}
+	h.Typeflag = tar.TypeReg
+	h.Size = st.Size()
+	if err := w.tw.WriteHeader(h); err != nil {
+		return 0, "", fmt.Errorf("写入 tar 条目失败 (%s): %w", e.RelPath, err)
+	}
+	hash := sha256.New()
+	n, err := io.CopyN(io.MultiWriter(w.tw, hash), f, h.Size)
+	if err != nil {
+		return n, "", fmt.Errorf("写入 tar 内容失败 (%s): %w", e.RelPath, err)
+	}
+	return n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (w *tarArchiveWriter) writeBytes(relPath string, data []byte, mode os.FileMode, modTime time.Time) error {
+	h := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     toZipPath(relPath),
+		Mode:     int64(mode.Perm()),
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	}
+	if err := w.tw.WriteHeader(h); err != nil {
+		return fmt.Errorf("写入 tar 条目失败 (%s): %w", relPath, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("写入 tar 内容失败 (%s): %w", relPath, err)
+	}
+	return nil
+}
+
+func (w *tarArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("写入 tar 失败: %w", err)
+	}
+	if err := w.comp.Close(); err != nil {
+		return fmt.Errorf("写入压缩数据失败: %w", err)
+	}
+	return nil
+}
+
+// archiveFile 为归档中的单个条目（zip 与 tar 统一表示）
+type archiveFile struct {
+	Name     string
+	Size     int64
+	Mode     os.FileMode
+	Modified time.Time
+	// Linkname 为符号链接的目标路径，普通文件为空
+	Linkname string
+
+	open func() (io.ReadCloser, error)
+}
+
+// Open 打开条目内容；符号链接的内容为其目标路径
+func (f *archiveFile) Open() (io.ReadCloser, error) {
+	if f.Linkname != "" {
+		return io.NopCloser(strings.NewReader(f.Linkname)), nil
+	}
+	return f.open()
+}
+
+// archiveReader 为已打开的归档
+type archiveReader struct {
+	Format ArchiveFormat
+	Files  []*archiveFile
+}
+
+// entries 返回除 manifest 以外的条目
+func (a *archiveReader) entries() map[string]*archiveFile {
+	out := make(map[string]*archiveFile, len(a.Files))
+	for _, f := range a.Files {
+		if f.Name != manifestName {
+			out[f.Name] = f
+		}
+	}
+	return out
+}
+
+var (
+	zipMagic  = []byte("PK")
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// openArchive 打开归档：必要时先解密，再按内容识别 zip/tar.gz/tar.zst。
+//
+// 加密或压缩的 tar 归档会解出到 0600 权限的临时文件以便随机读取，返回的 cleanup 负责关闭并删除。
+func openArchive(archivePath string, dec Decryption) (*archiveReader, func(), error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开归档失败 (%s): %w", archivePath, err)
+	}
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	cleanups = append(cleanups, func() { _ = f.Close() })
+
+	a, err := readArchive(f, dec, &cleanups)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("打开归档失败 (%s): %w", archivePath, err)
+	}
+	return a, cleanup, nil
+}
+
+func readArchive(f *os.File, dec Decryption, cleanups *[]func()) (*archiveReader, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var ra io.ReaderAt = f
+	size := st.Size()
+
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(len(ageHeader)); string(head) == ageHeader {
+		dr, err := decryptReader(br, dec)
+		if err != nil {
+			return nil, err
+		}
+		tmp, n, err := spoolToTemp(dr, cleanups)
+		if err != nil {
+			return nil, fmt.Errorf("解密归档失败: %w", err)
+		}
+		ra, size = tmp, n
+	}
+
+	magic := make([]byte, 4)
+	n, _ := ra.ReadAt(magic, 0)
+	magic = magic[:n]
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return readZipArchive(ra, size)
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(io.NewSectionReader(ra, 0, size))
+		if err != nil {
+			return nil, fmt.Errorf("解压 gzip 失败: %w", err)
+		}
+		tmp, n, err := spoolToTemp(gr, cleanups)
+		if err != nil {
+			return nil, fmt.Errorf("解压 gzip 失败: %w", err)
+		}
+		return readTarArchive(tmp, n, FormatTarGz)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(io.NewSectionReader(ra, 0, size))
+		if err != nil {
+			return nil, fmt.Errorf("解压 zstd 失败: %w", err)
+		}
+		defer zr.Close()
+		tmp, n, err := spoolToTemp(zr, cleanups)
+		if err != nil {
+			return nil, fmt.Errorf("解压 zstd 失败: %w", err)
+		}
+		return readTarArchive(tmp, n, FormatTarZst)
+	}
+	return nil, errors.New("无法识别的归档格式（支持 zip、tar.gz、tar.zst）")
+}
+
+func readZipArchive(ra io.ReaderAt, size int64) (*archiveReader, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archiveReader{Format: FormatZip}
+	for _, zf := range zr.File {
+		if strings.HasSuffix(zf.Name, "/") {
+			continue
+		}
+		af := &archiveFile{
+			Name:     zf.Name,
+			Size:     int64(zf.UncompressedSize64),
+			Mode:     zf.Mode(),
+			Modified: zf.Modified,
+			open:     zf.Open,
+		}
+		if zf.Mode()&os.ModeSymlink != 0 {
+			target, err := readEntry(af)
+			if err != nil {
+				return nil, err
+			}
+			af.Linkname = string(target)
+		}
+		a.Files = append(a.Files, af)
+	}
+	return a, nil
+}
+
+func readTarArchive(ra io.ReaderAt, size int64, format ArchiveFormat) (*archiveReader, error) {
+	cr := &countingReader{r: io.NewSectionReader(ra, 0, size)}
+	tr := tar.NewReader(cr)
+
+	a := &archiveReader{Format: format}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 tar 失败: %w", err)
+		}
+
+		af := &archiveFile{
+			Name:     h.Name,
+			Size:     h.Size,
+			Mode:     h.FileInfo().Mode(),
+			Modified: h.ModTime,
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeSymlink:
+			af.Linkname = h.Linkname
+			af.Size = int64(len(h.Linkname))
+		case tar.TypeReg:
+			// tar.Reader 不预读，Next 返回后底层读取位置即为条目内容的起始位置
+			section := io.NewSectionReader(ra, cr.n, h.Size)
+			af.open = func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(section, 0, section.Size())), nil
+			}
+		default:
+			return nil, fmt.Errorf("不支持的 tar 条目类型 (%s): %c", h.Name, h.Typeflag)
+		}
+		a.Files = append(a.Files, af)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// spoolToTemp 将内容写入 0600 权限的临时文件，并登记删除操作
+func spoolToTemp(r io.Reader, cleanups *[]func()) (*os.File, int64, error) {
+	tmp, err := os.CreateTemp("", "swag-cli-archive-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	*cleanups = append(*cleanups, func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	})
+	if err := tmp.Chmod(0o600); err != nil {
+		return nil, 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tmp, n, nil
+}
+
+// readEntry 读取条目的全部内容
+func readEntry(f *archiveFile) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取归档条目失败 (%s): %w", f.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("读取归档条目失败 (%s): %w", f.Name, err)
+	}
+	return b, nil
+}
+
+func hashEntry(f *archiveFile) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("读取归档条目失败 (%s): %w", f.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", fmt.Errorf("读取归档条目失败 (%s): %w", f.Name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// readLive 读取现有文件；符号链接返回其目标路径，与归档中的表示一致
+func readLive(abs string) ([]byte, os.FileInfo, error) {
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(abs)
+		if err != nil {
+			return nil, info, fmt.Errorf("读取符号链接失败 (%s): %w", abs, err)
+		}
+		return []byte(target), info, nil
+	}
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, info, fmt.Errorf("读取现有文件失败 (%s): %w", abs, err)
+	}
+	return b, info, nil
+}
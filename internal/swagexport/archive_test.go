@@ -0,0 +1,153 @@
+package swagexport
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportTarFormatsWithSymlinks(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []ArchiveFormat{FormatTarGz, FormatTarZst, FormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			swagDir := newMinimalSwagDir(t)
+			link := filepath.Join(swagDir, "config", "nginx", "proxy-confs", "b.subdomain.conf")
+			if err := os.Symlink("a.subdomain.conf", link); err != nil {
+				t.Fatalf("symlink error = %v", err)
+			}
+
+			outPath := filepath.Join(t.TempDir(), "out"+format.Ext())
+			res, err := Export(Options{SwagDir: swagDir, OutPath: outPath, Profile: ProfileMinimal})
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			var symlinks int
+			for _, f := range res.Manifest.Files {
+				if f.Symlink {
+					symlinks++
+				}
+			}
+			if symlinks != 1 || res.FileCount != 5 {
+				t.Fatalf("manifest = %+v", res.Manifest.Files)
+			}
+
+			report, err := Verify(VerifyOptions{ArchivePath: outPath, SwagDir: swagDir})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !report.OK() || !report.Live.Empty() {
+				t.Fatalf("Verify() = %+v, live = %+v", report, report.Live)
+			}
+
+			target := t.TempDir()
+			if _, err := Import(ImportOptions{ArchivePath: outPath, SwagDir: target}); err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			got, err := os.Readlink(filepath.Join(target, "config", "nginx", "proxy-confs", "b.subdomain.conf"))
+			if err != nil || got != "a.subdomain.conf" {
+				t.Fatalf("restored symlink = %q, err = %v", got, err)
+			}
+
+			plan, err := PlanImport(ImportOptions{ArchivePath: outPath, SwagDir: target})
+			if err != nil {
+				t.Fatalf("PlanImport() error = %v", err)
+			}
+			for _, e := range plan.Entries {
+				if e.Action != ActionUnchanged {
+					t.Fatalf("re-import should be a no-op: %+v", e)
+				}
+			}
+		})
+	}
+}
+
+func TestExportStreamsToWriter(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	var buf bytes.Buffer
+	res, err := Export(Options{SwagDir: swagDir, Profile: ProfileMinimal, Format: FormatTarGz, Out: &buf})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if res.OutPath != "-" {
+		t.Fatalf("OutPath = %q", res.OutPath)
+	}
+
+	p := filepath.Join(t.TempDir(), "stream.bin")
+	if err := os.WriteFile(p, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+	m, err := ReadManifest(p, Decryption{})
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if m.Digest != res.Manifest.Digest {
+		t.Fatalf("digest = %s, want %s", m.Digest, res.Manifest.Digest)
+	}
+}
+
+func TestImportRejectsPathsBelowSymlink(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	mustTar(t, tw, &tar.Header{Typeflag: tar.TypeSymlink, Name: "config/www", Linkname: "/etc"})
+	mustTar(t, tw, &tar.Header{Typeflag: tar.TypeReg, Name: "config/www/passwd", Size: 1, Mode: 0o644})
+	if _, err := tw.Write([]byte("x")); err != nil {
+		t.Fatalf("tar write error = %v", err)
+	}
+	manifest := []byte(`{"formatVersion":3}`)
+	mustTar(t, tw, &tar.Header{Typeflag: tar.TypeReg, Name: manifestName, Size: int64(len(manifest)), Mode: 0o644})
+	if _, err := tw.Write(manifest); err != nil {
+		t.Fatalf("tar write error = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close error = %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip close error = %v", err)
+	}
+
+	p := filepath.Join(t.TempDir(), "evil.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+	_, err := PlanImport(ImportOptions{ArchivePath: p, SwagDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "符号链接") {
+		t.Fatalf("PlanImport() should reject paths below a symlink entry, err = %v", err)
+	}
+}
+
+func TestParseArchiveFormat(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]ArchiveFormat{"": "", "zip": FormatZip, "tgz": FormatTarGz, ".tar.zst": FormatTarZst} {
+		got, err := ParseArchiveFormat(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseArchiveFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseArchiveFormat("rar"); err == nil {
+		t.Fatalf("ParseArchiveFormat() should reject rar")
+	}
+	if f, ok := FormatFromPath("backup.tar.zst.age"); !ok || f != FormatTarZst {
+		t.Fatalf("FormatFromPath() = %q, %v", f, ok)
+	}
+}
+
+func mustTar(t *testing.T, tw *tar.Writer, h *tar.Header) {
+	t.Helper()
+
+	if err := tw.WriteHeader(h); err != nil {
+		t.Fatalf("tar header error = %v", err)
+	}
+}
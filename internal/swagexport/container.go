@@ -0,0 +1,386 @@
+package swagexport
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Container 记录导出时 SWAG 容器的运行参数，用于在新主机上重新生成 compose 或 docker run 命令
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// ImageID 为本地镜像 ID，RepoDigest 为镜像仓库中的 digest（可用于固定镜像版本）
+	ImageID    string `json:"imageId,omitempty"`
+	RepoDigest string `json:"repoDigest,omitempty"`
+	// Env 为 "KEY=VALUE" 列表，不含镜像内置的 ENV；敏感变量的值为 ***
+	Env         []string          `json:"env,omitempty"`
+	Mounts      []ContainerMount  `json:"mounts,omitempty"`
+	NetworkMode string            `json:"networkMode,omitempty"`
+	Networks    []string          `json:"networks,omitempty"`
+	Ports       []ContainerPort   `json:"ports,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	CapAdd      []string          `json:"capAdd,omitempty"`
+	Restart     string            `json:"restart,omitempty"`
+}
+
+// ContainerMount 为 bind 挂载或命名卷
+type ContainerMount struct {
+	Type string `json:"type"`
+	// Source 为 bind 的主机路径或卷名
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+// ContainerPort 为端口映射
+type ContainerPort struct {
+	HostIP        string `json:"hostIp,omitempty"`
+	HostPort      string `json:"hostPort"`
+	ContainerPort string `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+const maskedValue = "***"
+
+var secretEnvRe = regexp.MustCompile(`(?i)(pass|secret|token|api_?key|license_?key|private_?key|access_?key|credential|_key$)`)
+
+// IsSecretEnv 判断环境变量名是否可能包含敏感值（token、密码、API key 等）
+func IsSecretEnv(key string) bool {
+	return secretEnvRe.MatchString(key)
+}
+
+func (m ContainerMount) String() string {
+	s := m.Source + ":" + m.Target
+	if m.ReadOnly {
+		s += ":ro"
+	}
+	return s
+}
+
+func (p ContainerPort) String() string {
+	s := p.HostPort + ":" + p.ContainerPort
+	if p.HostIP != "" && p.HostIP != "0.0.0.0" && p.HostIP != "::" {
+		s = p.HostIP + ":" + s
+	}
+	if p.Protocol != "" && p.Protocol != "tcp" {
+		s += "/" + p.Protocol
+	}
+	return s
+}
+
+// ContainerFromInspect 从 docker inspect 数据提取容器运行参数，敏感环境变量的值替换为 ***。
+//
+// img 为容器镜像的 inspect 数据，可为 nil；提供时会剔除与镜像内置相同的 ENV 与标签，并记录 RepoDigest。
+func ContainerFromInspect(info types.ContainerJSON, img *types.ImageInspect) (*Container, error) {
+	if info.ContainerJSONBase == nil || info.Config == nil {
+		return nil, errors.New("容器 inspect 数据不完整")
+	}
+
+	c := &Container{
+		Name:    strings.TrimPrefix(info.Name, "/"),
+		Image:   info.Config.Image,
+		ImageID: info.Image,
+	}
+
+	imageEnv := make(map[string]bool)
+	imageLabels := make(map[string]string)
+	if img != nil {
+		if len(img.RepoDigests) > 0 {
+			c.RepoDigest = img.RepoDigests[0]
+		}
+		if img.Config != nil {
+			for _, kv := range img.Config.Env {
+				imageEnv[kv] = true
+			}
+			imageLabels = img.Config.Labels
+		}
+	}
+
+	for _, kv := range info.Config.Env {
+		if imageEnv[kv] {
+			continue
+		}
+		k, _, _ := strings.Cut(kv, "=")
+		if IsSecretEnv(k) {
+			kv = k + "=" + maskedValue
+		}
+		c.Env = append(c.Env, kv)
+	}
+
+	for k, v := range info.Config.Labels {
+		if iv, ok := imageLabels[k]; ok && iv == v {
+			continue
+		}
+		if c.Labels == nil {
+			c.Labels = make(map[string]string)
+		}
+		c.Labels[k] = v
+	}
+
+	for _, m := range info.Mounts {
+		cm := ContainerMount{Type: string(m.Type), Source: m.Source, Target: m.Destination, ReadOnly: !m.RW}
+		switch m.Type {
+		case "bind":
+		case "volume":
+			cm.Source = m.Name
+		default:
+			continue
+		}
+		c.Mounts = append(c.Mounts, cm)
+	}
+	sort.Slice(c.Mounts, func(i, j int) bool { return c.Mounts[i].Target < c.Mounts[j].Target })
+
+	if hc := info.HostConfig; hc != nil {
+		c.NetworkMode = string(hc.NetworkMode)
+		c.CapAdd = append(c.CapAdd, hc.CapAdd...)
+		if hc.RestartPolicy.Name != "" && hc.RestartPolicy.Name != "no" {
+			c.Restart = string(hc.RestartPolicy.Name)
+			if hc.RestartPolicy.Name == "on-failure" && hc.RestartPolicy.MaximumRetryCount > 0 {
+				c.Restart = fmt.Sprintf("on-failure:%d", hc.RestartPolicy.MaximumRetryCount)
+			}
+		}
+		for p, bindings := range hc.PortBindings {
+			for _, b := range bindings {
+				hostPort := b.HostPort
+				if hostPort == "" {
+					hostPort = p.Port()
+				}
+				c.Ports = append(c.Ports, ContainerPort{HostIP: b.HostIP, HostPort: hostPort, ContainerPort: p.Port(), Protocol: p.Proto()})
+			}
+		}
+		sort.Slice(c.Ports, func(i, j int) bool { return c.Ports[i].String() < c.Ports[j].String() })
+	}
+
+	if info.NetworkSettings != nil {
+		for name := range info.NetworkSettings.Networks {
+			c.Networks = append(c.Networks, name)
+		}
+		sort.Strings(c.Networks)
+		// 自定义网络优先作为主网络，与 NetworkMode 保持一致
+		if i := indexOf(c.Networks, c.NetworkMode); i > 0 {
+			c.Networks = append(append([]string{c.NetworkMode}, c.Networks[:i]...), c.Networks[i+1:]...)
+		}
+	}
+	return c, nil
+}
+
+// MaskedEnv 返回导出时值已被隐藏的环境变量名
+func (c Container) MaskedEnv() []string {
+	var out []string
+	for _, kv := range c.Env {
+		if k, v, _ := strings.Cut(kv, "="); v == maskedValue {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// customNetworks 返回需要单独声明的网络（不含 bridge/host/none 等内置网络）
+func (c Container) customNetworks() []string {
+	if c.hostOrNoneNetwork() {
+		return nil
+	}
+	var out []string
+	for _, n := range c.Networks {
+		if n != "bridge" && n != "host" && n != "none" {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (c Container) hostOrNoneNetwork() bool {
+	return c.NetworkMode == "host" || c.NetworkMode == "none"
+}
+
+// userLabels 返回去掉 compose 自动添加的标签后的标签
+func (c Container) userLabels() map[string]string {
+	out := make(map[string]string)
+	for k, v := range c.Labels {
+		if !strings.HasPrefix(k, "com.docker.compose.") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (c Container) serviceName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return "swag"
+}
+
+// regenHeader 为生成文件开头的说明注释
+func (c Container) regenHeader() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 由 swag-cli 根据导出时的容器信息生成（容器 %s）\n", c.serviceName())
+	if c.RepoDigest != "" {
+		fmt.Fprintf(&b, "# 导出时的镜像 digest: %s\n", c.RepoDigest)
+	}
+	if masked := c.MaskedEnv(); len(masked) > 0 {
+		fmt.Fprintf(&b, "# 以下环境变量的值在导出时已隐藏，需自行提供: %s\n", strings.Join(masked, ", "))
+	}
+	return b.String()
+}
+
+type composeService struct {
+	Image         string            `yaml:"image"`
+	ContainerName string            `yaml:"container_name,omitempty"`
+	CapAdd        []string          `yaml:"cap_add,omitempty"`
+	Environment   []string          `yaml:"environment,omitempty"`
+	Volumes       []string          `yaml:"volumes,omitempty"`
+	Ports         []string          `yaml:"ports,omitempty"`
+	NetworkMode   string            `yaml:"network_mode,omitempty"`
+	Networks      []string          `yaml:"networks,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	Restart       string            `yaml:"restart,omitempty"`
+}
+
+type composeNamed struct {
+	Name string `yaml:"name"`
+}
+
+type composeDoc struct {
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNamed   `yaml:"networks,omitempty"`
+	Volumes  map[string]composeNamed   `yaml:"volumes,omitempty"`
+}
+
+// ComposeYAML 生成等价的 compose.yaml；已隐藏的环境变量写为 ${KEY}，由 .env 或环境变量提供
+func (c Container) ComposeYAML() ([]byte, error) {
+	svc := composeService{
+		Image:         c.Image,
+		ContainerName: c.Name,
+		CapAdd:        c.CapAdd,
+		Restart:       c.Restart,
+	}
+	for _, kv := range c.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		if v == maskedValue {
+			v = "${" + k + "}"
+		} else {
+			v = strings.ReplaceAll(v, "$", "$$")
+		}
+		svc.Environment = append(svc.Environment, k+"="+v)
+	}
+
+	doc := composeDoc{}
+	for _, m := range c.Mounts {
+		svc.Volumes = append(svc.Volumes, m.String())
+		if m.Type == "volume" {
+			if doc.Volumes == nil {
+				doc.Volumes = make(map[string]composeNamed)
+			}
+			doc.Volumes[m.Source] = composeNamed{Name: m.Source}
+		}
+	}
+	if !c.hostOrNoneNetwork() {
+		for _, p := range c.Ports {
+			svc.Ports = append(svc.Ports, p.String())
+		}
+	} else {
+		svc.NetworkMode = c.NetworkMode
+	}
+	for _, n := range c.customNetworks() {
+		svc.Networks = append(svc.Networks, n)
+		if doc.Networks == nil {
+			doc.Networks = make(map[string]composeNamed)
+		}
+		doc.Networks[n] = composeNamed{Name: n}
+	}
+	if labels := c.userLabels(); len(labels) > 0 {
+		svc.Labels = labels
+	}
+	doc.Services = map[string]composeService{c.serviceName(): svc}
+
+	body, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("生成 compose.yaml 失败: %w", err)
+	}
+	return append([]byte(c.regenHeader()), body...), nil
+}
+
+// DockerRun 生成等价的 docker run 命令；已隐藏的环境变量写为 -e KEY，从当前 shell 环境读取
+func (c Container) DockerRun() string {
+	args := []string{"docker run -d"}
+	add := func(flag, value string) { args = append(args, flag+" "+shellQuote(value)) }
+
+	if c.Name != "" {
+		add("--name", c.Name)
+	}
+	for _, capName := range c.CapAdd {
+		add("--cap-add", capName)
+	}
+	if c.Restart != "" {
+		add("--restart", c.Restart)
+	}
+	networks := c.customNetworks()
+	switch {
+	case c.hostOrNoneNetwork():
+		add("--network", c.NetworkMode)
+	case len(networks) > 0:
+		add("--network", networks[0])
+	}
+	if !c.hostOrNoneNetwork() {
+		for _, p := range c.Ports {
+			add("-p", p.String())
+		}
+	}
+	for _, kv := range c.Env {
+		if k, v, _ := strings.Cut(kv, "="); v == maskedValue {
+			add("-e", k)
+			continue
+		}
+		add("-e", kv)
+	}
+	for _, m := range c.Mounts {
+		add("-v", m.String())
+	}
+	labels := c.userLabels()
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add("--label", k+"="+labels[k])
+	}
+	args = append(args, shellQuote(c.Image))
+
+	var b strings.Builder
+	b.WriteString(c.regenHeader())
+	for _, n := range networks {
+		fmt.Fprintf(&b, "docker network create %s 2>/dev/null || true\n", shellQuote(n))
+	}
+	b.WriteString(strings.Join(args, " \\\n  "))
+	b.WriteString("\n")
+	for _, n := range networks[min(1, len(networks)):] {
+		fmt.Fprintf(&b, "docker network connect %s %s\n", shellQuote(n), shellQuote(c.serviceName()))
+	}
+	return b.String()
+}
+
+var shellSafeRe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+func shellQuote(s string) string {
+	if shellSafeRe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
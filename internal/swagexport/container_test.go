@@ -0,0 +1,151 @@
+package swagexport
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"gopkg.in/yaml.v3"
+)
+
+func TestContainerFromInspectMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	c, err := ContainerFromInspect(testInspect(), &types.ImageInspect{
+		RepoDigests: []string{"lscr.io/linuxserver/swag@sha256:abc"},
+		Config: &container.Config{
+			Env:    []string{"PATH=/usr/bin", "S6_VERBOSITY=1"},
+			Labels: map[string]string{"build_version": "1.0"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ContainerFromInspect() error = %v", err)
+	}
+
+	if got := strings.Join(c.Env, ","); got != "URL=example.com,CF_API_TOKEN=***,MAXMINDDB_LICENSE_KEY=***,PRICE=$5" {
+		t.Fatalf("Env = %s", got)
+	}
+	if c.RepoDigest != "lscr.io/linuxserver/swag@sha256:abc" || c.Name != "swag" {
+		t.Fatalf("container = %+v", c)
+	}
+	if _, ok := c.Labels["build_version"]; ok {
+		t.Fatalf("image labels should be dropped: %v", c.Labels)
+	}
+	if strings.Join(c.Networks, ",") != "swag,backend" {
+		t.Fatalf("Networks = %v", c.Networks)
+	}
+	if len(c.Mounts) != 2 || c.Mounts[0].String() != "/srv/swag:/config" || c.Mounts[1].String() != "swag-cache:/data:ro" {
+		t.Fatalf("Mounts = %+v", c.Mounts)
+	}
+	if len(c.Ports) != 2 || c.Ports[0].String() != "127.0.0.1:8443:443" || c.Ports[1].String() != "80:80" {
+		t.Fatalf("Ports = %+v", c.Ports)
+	}
+}
+
+func TestContainerComposeYAMLAndDockerRun(t *testing.T) {
+	t.Parallel()
+
+	c, err := ContainerFromInspect(testInspect(), nil)
+	if err != nil {
+		t.Fatalf("ContainerFromInspect() error = %v", err)
+	}
+
+	b, err := c.ComposeYAML()
+	if err != nil {
+		t.Fatalf("ComposeYAML() error = %v", err)
+	}
+	var doc composeDoc
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("generated compose.yaml is invalid: %v\n%s", err, b)
+	}
+	svc := doc.Services["swag"]
+	if svc.Image != "lscr.io/linuxserver/swag:latest" || svc.Restart != "unless-stopped" || strings.Join(svc.CapAdd, ",") != "NET_ADMIN" {
+		t.Fatalf("service = %+v", svc)
+	}
+	env := strings.Join(svc.Environment, ",")
+	if !strings.Contains(env, "CF_API_TOKEN=${CF_API_TOKEN}") || !strings.Contains(env, "PRICE=$$5") {
+		t.Fatalf("Environment = %s", env)
+	}
+	if _, ok := svc.Labels["com.docker.compose.project"]; ok || svc.Labels["swag"] != "enable" {
+		t.Fatalf("Labels = %v", svc.Labels)
+	}
+	if doc.Networks["backend"].Name != "backend" || doc.Volumes["swag-cache"].Name != "swag-cache" {
+		t.Fatalf("networks = %v, volumes = %v", doc.Networks, doc.Volumes)
+	}
+	if !strings.Contains(string(b), "# 以下环境变量的值在导出时已隐藏，需自行提供: CF_API_TOKEN, MAXMINDDB_LICENSE_KEY") {
+		t.Fatalf("missing masked env note:\n%s", b)
+	}
+
+	run := c.DockerRun()
+	for _, want := range []string{
+		"--network swag",
+		"-p 127.0.0.1:8443:443",
+		"-e CF_API_TOKEN \\",
+		"-e 'PRICE=$5'",
+		"-v swag-cache:/data:ro",
+		"docker network connect backend swag",
+	} {
+		if !strings.Contains(run, want) {
+			t.Fatalf("DockerRun() missing %q:\n%s", want, run)
+		}
+	}
+}
+
+func TestExportRecordsContainer(t *testing.T) {
+	t.Parallel()
+
+	c, err := ContainerFromInspect(testInspect(), nil)
+	if err != nil {
+		t.Fatalf("ContainerFromInspect() error = %v", err)
+	}
+	outPath := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: newMinimalSwagDir(t), OutPath: outPath, Profile: ProfileMinimal, Container: c}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	m, err := ReadManifest(outPath, Decryption{})
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if m.Container == nil || m.Container.Image != c.Image || strings.Join(m.Container.MaskedEnv(), ",") != "CF_API_TOKEN,MAXMINDDB_LICENSE_KEY" {
+		t.Fatalf("manifest container = %+v", m.Container)
+	}
+}
+
+func testInspect() types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			Name:  "/swag",
+			Image: "sha256:1234",
+			HostConfig: &container.HostConfig{
+				NetworkMode:   "swag",
+				CapAdd:        []string{"NET_ADMIN"},
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+				PortBindings: nat.PortMap{
+					"443/tcp": {{HostIP: "127.0.0.1", HostPort: "8443"}},
+					"80/tcp":  {{HostPort: "80"}},
+				},
+			},
+		},
+		Config: &container.Config{
+			Image: "lscr.io/linuxserver/swag:latest",
+			Env:   []string{"PATH=/usr/bin", "URL=example.com", "CF_API_TOKEN=s3cr3t", "MAXMINDDB_LICENSE_KEY=abc", "PRICE=$5"},
+			Labels: map[string]string{
+				"build_version":              "1.0",
+				"com.docker.compose.project": "swag",
+				"swag":                       "enable",
+			},
+		},
+		Mounts: []types.MountPoint{
+			{Type: "volume", Name: "swag-cache", Source: "/var/lib/docker/volumes/swag-cache/_data", Destination: "/data"},
+			{Type: "bind", Source: "/srv/swag", Destination: "/config", RW: true},
+			{Type: "tmpfs", Destination: "/tmp", RW: true},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"backend": {}, "swag": {}},
+		},
+	}
+}
@@ -0,0 +1,162 @@
+package swagexport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Encryption 描述导出归档的 age 加密参数：Passphrase 与 Recipients（age1... 公钥）二选一，均为空表示不加密
+type Encryption struct {
+	Passphrase string
+	Recipients []string
+}
+
+// Enabled 返回是否需要加密
+func (e Encryption) Enabled() bool {
+	return e.Passphrase != "" || len(e.Recipients) > 0
+}
+
+// Decryption 描述读取加密归档所需的凭据：Passphrase 或 age 私钥（AGE-SECRET-KEY-... 或 identity 文件内容）
+type Decryption struct {
+	Passphrase string
+	Identities []string
+}
+
+const ageHeader = "age-encryption.org/v1"
+
+// IsEncrypted 判断归档是否为 age 加密文件
+func IsEncrypted(archivePath string) (bool, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return false, fmt.Errorf("打开归档失败 (%s): %w", archivePath, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	head := make([]byte, len(ageHeader))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false, nil
+	}
+	return string(head) == ageHeader, nil
+}
+
+// CanDecrypt 判断 dec 能否解密归档，只解析 age 头部而不读取内容；未加密的归档返回 true
+func CanDecrypt(archivePath string, dec Decryption) bool {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(len(ageHeader)); string(head) != ageHeader {
+		return true
+	}
+	identities, err := dec.identities()
+	if err != nil {
+		return false
+	}
+	_, err = age.Decrypt(br, identities...)
+	return err == nil
+}
+
+// ParseRecipients 解析 age 公钥，支持逐行书写的 recipients 文件内容（忽略空行与 # 注释）
+func ParseRecipients(values []string) ([]string, error) {
+	var out []string
+	for _, v := range values {
+		for _, line := range strings.Split(v, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if _, err := age.ParseX25519Recipient(line); err != nil {
+				return nil, fmt.Errorf("无效的 age 公钥 %q: %w", line, err)
+			}
+			out = append(out, line)
+		}
+	}
+	return out, nil
+}
+
+func (e Encryption) recipients() ([]age.Recipient, error) {
+	if e.Passphrase != "" && len(e.Recipients) > 0 {
+		return nil, errors.New("passphrase 与 age 公钥不能同时使用")
+	}
+	if e.Passphrase != "" {
+		r, err := age.NewScryptRecipient(e.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("初始化 passphrase 加密失败: %w", err)
+		}
+		return []age.Recipient{r}, nil
+	}
+
+	var out []age.Recipient
+	for _, s := range e.Recipients {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("无效的 age 公钥 %q: %w", s, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (d Decryption) identities() ([]age.Identity, error) {
+	var out []age.Identity
+	if d.Passphrase != "" {
+		id, err := age.NewScryptIdentity(d.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("初始化 passphrase 解密失败: %w", err)
+		}
+		out = append(out, id)
+	}
+	for _, s := range d.Identities {
+		ids, err := age.ParseIdentities(strings.NewReader(s))
+		if err != nil {
+			return nil, fmt.Errorf("解析 age 私钥失败: %w", err)
+		}
+		out = append(out, ids...)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("归档已加密，请提供 passphrase 或 age 私钥")
+	}
+	return out, nil
+}
+
+// encryptWriter 在 enc 启用时返回加密 Writer，关闭时写入 age 尾部；未启用时原样返回
+func encryptWriter(w io.Writer, enc Encryption) (io.WriteCloser, error) {
+	if !enc.Enabled() {
+		return nopWriteCloser{w}, nil
+	}
+	recipients, err := enc.recipients()
+	if err != nil {
+		return nil, err
+	}
+	ew, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密失败: %w", err)
+	}
+	return ew, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// decryptReader 返回解密后的内容流
+func decryptReader(r io.Reader, dec Decryption) (io.Reader, error) {
+	identities, err := dec.identities()
+	if err != nil {
+		return nil, err
+	}
+	dr, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("解密归档失败（passphrase 或私钥不正确？）: %w", err)
+	}
+	return dr, nil
+}
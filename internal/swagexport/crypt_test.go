@@ -0,0 +1,129 @@
+package swagexport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestExportEncryptedWithPassphrase(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "config", "dns-conf", "cloudflare.ini"), "dns_cloudflare_api_token = secret\n")
+
+	outPath := filepath.Join(t.TempDir(), "out.zip.age")
+	if _, err := Export(Options{
+		SwagDir:        swagDir,
+		OutPath:        outPath,
+		Profile:        ProfileFull,
+		IncludeSecrets: true,
+		Encryption:     Encryption{Passphrase: "correct horse"},
+	}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	encrypted, err := IsEncrypted(outPath)
+	if err != nil || !encrypted {
+		t.Fatalf("IsEncrypted() = %v, %v", encrypted, err)
+	}
+	if _, err := ReadManifest(outPath, Decryption{}); err == nil {
+		t.Fatalf("ReadManifest() should require credentials")
+	}
+	if _, err := ReadManifest(outPath, Decryption{Passphrase: "wrong"}); err == nil {
+		t.Fatalf("ReadManifest() should fail with wrong passphrase")
+	}
+
+	target := t.TempDir()
+	if _, err := Import(ImportOptions{ArchivePath: outPath, SwagDir: target, Decryption: Decryption{Passphrase: "correct horse"}}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(target, "config", "dns-conf", "cloudflare.ini"))
+	if err != nil || string(b) != "dns_cloudflare_api_token = secret\n" {
+		t.Fatalf("restored secret = %q, err = %v", b, err)
+	}
+}
+
+func TestExportEncryptedWithRecipient(t *testing.T) {
+	t.Parallel()
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+
+	recipients, err := ParseRecipients([]string{"# backup key\n" + id.Recipient().String() + "\n"})
+	if err != nil {
+		t.Fatalf("ParseRecipients() error = %v", err)
+	}
+
+	swagDir := newMinimalSwagDir(t)
+	outPath := filepath.Join(t.TempDir(), "out.zip.age")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outPath, Profile: ProfileMinimal, Encryption: Encryption{Recipients: recipients}}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	report, err := Verify(VerifyOptions{ArchivePath: outPath, Decryption: Decryption{Identities: []string{id.String()}}})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() || !report.Manifest.Encrypted {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if _, err := Verify(VerifyOptions{ArchivePath: outPath, Decryption: Decryption{Identities: []string{other.String()}}}); err == nil {
+		t.Fatalf("Verify() should fail with a different key")
+	}
+}
+
+func TestImportChainWithPerArchiveDecryption(t *testing.T) {
+	t.Parallel()
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	swagDir := newMinimalSwagDir(t)
+	out := t.TempDir()
+
+	base := filepath.Join(out, "base.zip.age")
+	baseDec := Decryption{Passphrase: "correct horse"}
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: base, Profile: ProfileMinimal, Encryption: Encryption{Passphrase: "correct horse"}}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "new.subdomain.conf"), "# new\n")
+	inc := filepath.Join(out, "inc.zip.age")
+	incDec := Decryption{Identities: []string{id.String()}}
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: inc, IncrementalFrom: base, BaseDecryption: baseDec, Encryption: Encryption{Recipients: []string{id.Recipient().String()}}}); err != nil {
+		t.Fatalf("Export(incremental) error = %v", err)
+	}
+
+	if !CanDecrypt(base, baseDec) || CanDecrypt(inc, baseDec) || !CanDecrypt(inc, incDec) {
+		t.Fatalf("CanDecrypt() mismatch")
+	}
+
+	// 沿用基准归档的凭据时应在写入任何文件前失败
+	target := t.TempDir()
+	if _, err := Import(ImportOptions{ArchivePath: base, Increments: []string{inc}, SwagDir: target, NoBackup: true, Decryption: baseDec}); err == nil {
+		t.Fatalf("Import() should fail without the increment's credentials")
+	}
+	if entries, _ := os.ReadDir(target); len(entries) != 0 {
+		t.Fatalf("target written before failure: %v", entries)
+	}
+
+	if _, err := Import(ImportOptions{
+		ArchivePath: base, Increments: []string{inc}, SwagDir: target, NoBackup: true,
+		Decryption: baseDec, IncrementDecryptions: []Decryption{incDec},
+	}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(target, "config", "nginx", "proxy-confs", "new.subdomain.conf")); err != nil || string(b) != "# new\n" {
+		t.Fatalf("restored increment = %q, err = %v", b, err)
+	}
+}
@@ -0,0 +1,323 @@
+package swagexport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DiffOptions 描述归档比对参数：ArchivePath 与 OtherPath（另一个归档）或 SwagDir（现有目录）比对
+type DiffOptions struct {
+	ArchivePath string
+	OtherPath   string
+	SwagDir     string
+
+	Decryption      Decryption
+	OtherDecryption Decryption
+
+	// ShowSecrets 为 true 时不隐藏敏感文件内容与配置中的 token/password 等值
+	ShowSecrets bool
+	// Context 为统一 diff 的上下文行数，<=0 时默认 3
+	Context int
+}
+
+// DiffStatus 表示单个文件的变化类型
+type DiffStatus string
+
+const (
+	DiffAdded   DiffStatus = "added"
+	DiffRemoved DiffStatus = "removed"
+	DiffChanged DiffStatus = "changed"
+)
+
+// FileChange 为单个文件的差异
+type FileChange struct {
+	Path   string
+	Status DiffStatus
+	// Unified 为文本文件的统一 diff，二进制、敏感或过大的文件为空
+	Unified string
+	// Binary 表示文件不是文本，未生成 diff
+	Binary bool
+	// Redacted 表示 diff 中的敏感内容已被隐藏
+	Redacted bool
+	// NoContent 表示增量归档未包含该文件内容（沿用基准），只能按 sha256 判断变化
+	NoContent bool
+}
+
+// DiffReport 为比对结果，From 为第一个归档，To 为第二个归档或现有目录
+type DiffReport struct {
+	From    string
+	To      string
+	Changes []FileChange
+}
+
+// maxDiffBytes 超过该大小的文件只报告变化，不生成文本 diff
+const maxDiffBytes = 1 << 20
+
+// Diff 比对归档与现有 SWAG 目录（或另一个归档），列出新增/删除/修改的文件与文本 diff
+func Diff(opts DiffOptions) (DiffReport, error) {
+	from, closeFrom, err := openDiffArchive(opts.ArchivePath, opts.Decryption)
+	if err != nil {
+		return DiffReport{}, err
+	}
+	defer closeFrom()
+
+	report := DiffReport{From: opts.ArchivePath}
+	var to diffSource
+	switch {
+	case strings.TrimSpace(opts.OtherPath) != "":
+		other, closeOther, err := openDiffArchive(opts.OtherPath, opts.OtherDecryption)
+		if err != nil {
+			return DiffReport{}, err
+		}
+		defer closeOther()
+		to = other
+		report.To = opts.OtherPath
+	case strings.TrimSpace(opts.SwagDir) != "":
+		live, err := openLiveSource(filepath.Clean(strings.TrimSpace(opts.SwagDir)), from.m)
+		if err != nil {
+			return DiffReport{}, err
+		}
+		to = live
+		report.To = live.dir
+	default:
+		return DiffReport{}, errors.New("需要指定第二个归档或 swag-dir")
+	}
+
+	fromSums, toSums := from.sums(), to.sums()
+	paths := make(map[string]bool, len(fromSums)+len(toSums))
+	for p := range fromSums {
+		paths[p] = true
+	}
+	for p := range toSums {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		a, inFrom := fromSums[p]
+		b, inTo := toSums[p]
+		var c FileChange
+		switch {
+		case !inTo:
+			c = FileChange{Path: p, Status: DiffRemoved}
+		case !inFrom:
+			c = FileChange{Path: p, Status: DiffAdded}
+		case a != "" && a == b:
+			continue
+		default:
+			c = FileChange{Path: p, Status: DiffChanged}
+		}
+
+		var oldData, newData []byte
+		var noContent bool
+		if inFrom {
+			if oldData, err = from.read(p); errors.Is(err, errNoContent) {
+				noContent = true
+			} else if err != nil {
+				return report, err
+			}
+		}
+		if inTo {
+			if newData, err = to.read(p); errors.Is(err, errNoContent) {
+				noContent = true
+			} else if err != nil {
+				return report, err
+			}
+		}
+		if noContent {
+			if c.Status == DiffChanged && (a == sha256Hex(newData) || b == sha256Hex(oldData)) {
+				continue
+			}
+			c.NoContent = true
+			report.Changes = append(report.Changes, c)
+			continue
+		}
+		if c.Status == DiffChanged && bytes.Equal(oldData, newData) {
+			continue
+		}
+		fillTextDiff(&c, oldData, newData, opts)
+		report.Changes = append(report.Changes, c)
+	}
+	return report, nil
+}
+
+// fillTextDiff 为文本文件生成统一 diff，并按需隐藏敏感内容
+func fillTextDiff(c *FileChange, oldData, newData []byte, opts DiffOptions) {
+	if !isText(oldData) || !isText(newData) || len(oldData) > maxDiffBytes || len(newData) > maxDiffBytes {
+		c.Binary = true
+		return
+	}
+	if !opts.ShowSecrets && IsSecretPath(c.Path) {
+		c.Redacted = true
+		return
+	}
+
+	oldText, newText := string(oldData), string(newData)
+	if !opts.ShowSecrets {
+		var r1, r2 bool
+		oldText, r1 = RedactSecrets(oldText)
+		newText, r2 = RedactSecrets(newText)
+		c.Redacted = r1 || r2
+	}
+
+	ctx := opts.Context
+	if ctx <= 0 {
+		ctx = 3
+	}
+	fromName, toName := "a/"+c.Path, "b/"+c.Path
+	if c.Status == DiffAdded {
+		fromName = "/dev/null"
+	}
+	if c.Status == DiffRemoved {
+		toName = "/dev/null"
+	}
+	c.Unified = UnifiedDiff(fromName, toName, oldText, newText, ctx)
+}
+
+func isText(b []byte) bool {
+	return !bytes.Contains(b, []byte{0}) && utf8.Valid(b)
+}
+
+// secretPrefixes 为整体视为敏感、默认不显示内容的归档路径前缀
+var secretPrefixes = []string{
+	"config/dns-conf/",
+	"config/keys/",
+	"config/etc/letsencrypt/",
+}
+
+// IsSecretPath 判断归档路径是否为敏感文件（DNS API 凭据、私钥、证书、.htpasswd 等）
+func IsSecretPath(p string) bool {
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	base := strings.ToLower(filepath.Base(p))
+	return base == ".htpasswd" || strings.HasSuffix(base, ".pem") || strings.HasSuffix(base, ".key")
+}
+
+var secretValueRe = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key|apikey|private[_-]?key|access[_-]?key|credentials?)[A-Za-z0-9_.-]*"?\s*[:=]\s*"?)([^"\s#;]+)`)
+
+var authHeaderRe = regexp.MustCompile(`(?i)(authorization"?\s+"?(?:basic|bearer)\s+)([^"\s;]+)`)
+
+// RedactSecrets 将配置文本中形如 token=xxx、password: xxx、Authorization "Basic xxx" 的值替换为 ***，返回是否有替换
+func RedactSecrets(text string) (string, bool) {
+	redacted := false
+	replace := func(re *regexp.Regexp, s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			if sub[2] == "***" {
+				return m
+			}
+			redacted = true
+			return sub[1] + "***"
+		})
+	}
+	text = replace(secretValueRe, text)
+	text = replace(authHeaderRe, text)
+	return text, redacted
+}
+
+// diffSource 为 diff 的一侧：归档或现有目录
+type diffSource interface {
+	// sums 返回路径到 sha256 的映射，sha256 未知时为空字符串
+	sums() map[string]string
+	read(p string) ([]byte, error)
+}
+
+type archiveSource struct {
+	m     Manifest
+	files map[string]*archiveFile
+}
+
+func openDiffArchive(archivePath string, dec Decryption) (*archiveSource, func(), error) {
+	a, cleanup, err := openArchive(archivePath, dec)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := readManifest(a)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	src := &archiveSource{m: m, files: a.entries()}
+	for _, mf := range m.Files {
+		if _, ok := src.files[mf.Path]; !ok {
+			cleanup()
+			return nil, nil, fmt.Errorf("归档不完整 (%s): manifest 中的 %s 不存在", archivePath, mf.Path)
+		}
+	}
+	return src, cleanup, nil
+}
+
+// errNoContent 表示文件属于增量归档沿用基准的部分，归档中没有其内容
+var errNoContent = errors.New("增量归档未包含该文件内容")
+
+func (s *archiveSource) sums() map[string]string {
+	state := s.m.State()
+	out := make(map[string]string, len(state))
+	for _, mf := range state {
+		out[mf.Path] = mf.SHA256
+	}
+	return out
+}
+
+func (s *archiveSource) read(p string) ([]byte, error) {
+	f, ok := s.files[p]
+	if !ok {
+		if s.m.Incremental != nil {
+			return nil, errNoContent
+		}
+		return nil, fmt.Errorf("归档中不存在 %s", p)
+	}
+	return readEntry(f)
+}
+
+type liveSource struct {
+	dir   string
+	paths map[string]string
+}
+
+// openLiveSource 按归档 manifest 记录的导出选项规划现有目录的文件，保证两侧的文件范围一致
+func openLiveSource(swagDir string, m Manifest) (*liveSource, error) {
+	src := &liveSource{dir: swagDir, paths: make(map[string]string)}
+	if m.Profile == ProfilePreImport {
+		// pre-import 备份只包含被覆盖的文件，只比对这些文件
+		for _, mf := range m.Files {
+			if _, err := os.Lstat(filepath.Join(swagDir, filepath.FromSlash(mf.Path))); err == nil {
+				src.paths[mf.Path] = ""
+			}
+		}
+		return src, nil
+	}
+
+	entries, err := buildFilePlan(swagDir, m.planOptions())
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		src.paths[e.RelPath] = ""
+	}
+	return src, nil
+}
+
+func (s *liveSource) sums() map[string]string {
+	return s.paths
+}
+
+func (s *liveSource) read(p string) ([]byte, error) {
+	b, _, err := readLive(filepath.Join(s.dir, filepath.FromSlash(p)))
+	return b, err
+}
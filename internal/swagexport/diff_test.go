@@ -0,0 +1,139 @@
+package swagexport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainstLiveDir(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services:\n  swag:\n    environment:\n      - CF_API_TOKEN=old\n")
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outZip, Profile: ProfileMinimal, ProxyConfOnly: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# a\nserver_name a.*;\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "b.subdomain.conf"), "# b\n")
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services:\n  swag:\n    environment:\n      - CF_API_TOKEN=new\n")
+	if err := os.Remove(filepath.Join(swagDir, "config", "nginx", "site-confs", "default")); err != nil {
+		t.Fatalf("remove error = %v", err)
+	}
+
+	report, err := Diff(DiffOptions{ArchivePath: outZip, SwagDir: swagDir})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	got := map[string]FileChange{}
+	for _, c := range report.Changes {
+		got[c.Path] = c
+	}
+	if len(got) != 4 {
+		t.Fatalf("Changes = %+v", report.Changes)
+	}
+	if got["config/nginx/proxy-confs/b.subdomain.conf"].Status != DiffAdded {
+		t.Fatalf("b.subdomain.conf = %+v", got["config/nginx/proxy-confs/b.subdomain.conf"])
+	}
+	if got["config/nginx/site-confs/default"].Status != DiffRemoved {
+		t.Fatalf("default = %+v", got["config/nginx/site-confs/default"])
+	}
+
+	a := got["config/nginx/proxy-confs/a.subdomain.conf"]
+	if a.Status != DiffChanged || !strings.Contains(a.Unified, "+server_name a.*;\n") {
+		t.Fatalf("a.subdomain.conf = %+v", a)
+	}
+
+	compose := got["compose.yaml"]
+	if !compose.Redacted || strings.Contains(compose.Unified, "old") || strings.Contains(compose.Unified, "new") {
+		t.Fatalf("compose.yaml should be redacted: %+v", compose)
+	}
+
+	report, err = Diff(DiffOptions{ArchivePath: outZip, SwagDir: swagDir, ShowSecrets: true})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	for _, c := range report.Changes {
+		if c.Path == "compose.yaml" && !strings.Contains(c.Unified, "+      - CF_API_TOKEN=new\n") {
+			t.Fatalf("compose.yaml with secrets = %q", c.Unified)
+		}
+	}
+}
+
+func TestDiffBetweenArchivesHidesSecretFiles(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	ini := filepath.Join(swagDir, "config", "dns-conf", "cloudflare.ini")
+	mustWrite(t, ini, "dns_cloudflare_api_token = one\n")
+	opts := Options{SwagDir: swagDir, Profile: ProfileFull, IncludeSecrets: true}
+
+	opts.OutPath = filepath.Join(t.TempDir(), "1.zip")
+	if _, err := Export(opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	first := opts.OutPath
+
+	mustWrite(t, ini, "dns_cloudflare_api_token = two\n")
+	opts.OutPath = filepath.Join(t.TempDir(), "2.zip")
+	if _, err := Export(opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	report, err := Diff(DiffOptions{ArchivePath: first, OtherPath: opts.OutPath})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(report.Changes) != 1 {
+		t.Fatalf("Changes = %+v", report.Changes)
+	}
+	c := report.Changes[0]
+	if c.Path != "config/dns-conf/cloudflare.ini" || !c.Redacted || c.Unified != "" {
+		t.Fatalf("secret file change = %+v", c)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11"
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -8,3 +8,4 @@
+ 8
+ 9
+ 10
++11
+\ No newline at end of file
+`
+	if got := UnifiedDiff("a", "b", a, b, 3); got != want {
+		t.Fatalf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("a", "b", a, a, 3); got != "" {
+		t.Fatalf("UnifiedDiff() of equal input = %q", got)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	t.Parallel()
+
+	in := "dns_cloudflare_api_token = abc123\nproxy_set_header Authorization \"Basic dXNlcjpwYXNz\";\nserver_name example.com;\n"
+	got, redacted := RedactSecrets(in)
+	want := "dns_cloudflare_api_token = ***\nproxy_set_header Authorization \"Basic ***\";\nserver_name example.com;\n"
+	if !redacted || got != want {
+		t.Fatalf("RedactSecrets() = %q, %v", got, redacted)
+	}
+}
@@ -12,6 +12,11 @@ type excluder struct {
 }
 
 func newExcluder(patterns []string) (*excluder, error) {
+	return newGlobSet("exclude-glob", patterns)
+}
+
+// newGlobSet 编译一组 glob，kind 用于错误提示；没有有效模式时返回 nil
+func newGlobSet(kind string, patterns []string) (*excluder, error) {
 	var cleaned []string
 	for _, p := range patterns {
 		p = strings.TrimSpace(p)
@@ -28,7 +33,7 @@ func newExcluder(patterns []string) (*excluder, error) {
 	for _, p := range cleaned {
 		re, err := globToRegexp(p)
 		if err != nil {
-			return nil, fmt.Errorf("无效 exclude-glob: %s: %w", p, err)
+			return nil, fmt.Errorf("无效 %s: %s: %w", kind, p, err)
 		}
 		res = append(res, re)
 	}
@@ -40,6 +45,11 @@ func newExcluder(patterns []string) (*excluder, error) {
 }
 
 func (e *excluder) IsExcluded(path string) bool {
+	return e.Match(path)
+}
+
+// Match 判断路径是否匹配任一模式；nil 不匹配任何路径
+func (e *excluder) Match(path string) bool {
 	if e == nil {
 		return false
 	}
@@ -67,6 +77,12 @@ func globToRegexp(glob string) (*regexp.Regexp, error) {
 		ch := runes[i]
 
 		if ch == '*' {
+			// "**/" 匹配零或多级目录，因此 "**/*.conf" 也匹配顶层的 a.conf
+			if i+2 < len(runes) && runes[i+1] == '*' && runes[i+2] == '/' && (i == 0 || runes[i-1] == '/') {
+				b.WriteString("(?:.*/)?")
+				i += 2
+				continue
+			}
 			if i+1 < len(runes) && runes[i+1] == '*' {
 				b.WriteString(".*")
 				i++
@@ -1,7 +1,6 @@
 package swagexport
 
 import (
-	"archive/zip"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,6 +18,9 @@ const (
 	ProfileMinimal  Profile = "minimal"
 	ProfileStandard Profile = "standard"
 	ProfileFull     Profile = "full"
+
+	// ProfilePreImport 标记 swag import 在覆盖前自动创建的备份
+	ProfilePreImport Profile = "pre-import"
 )
 
 type Options struct {
@@ -28,7 +30,31 @@ type Options struct {
 	IncludeSecrets bool
 	ProxyConfOnly  bool
 	ExcludeGlobs   []string
-	Version        string
+	// IncludeGlobs 为额外导出的路径（相对 swag-dir），敏感目录仍需 IncludeSecrets
+	IncludeGlobs []string
+	// Custom 非空时 Profile 为自定义档位名，文件集合由其定义决定
+	Custom  *CustomProfile
+	Version string
+	// Encryption 启用时整个归档使用 age 加密
+	Encryption Encryption
+	// Format 为归档格式，为空时按 OutPath 的扩展名推断，默认 zip
+	Format ArchiveFormat
+	// Out 非空时将归档写入该 Writer（如 stdout），忽略 OutPath
+	Out io.Writer
+	// IncrementalFrom 非空时只导出相对该归档新增或变化的文件，并沿用其导出档位与过滤规则
+	IncrementalFrom string
+	// BaseDecryption 为加密基准归档的解密凭据
+	BaseDecryption Decryption
+	// Container 为 SWAG 容器的运行参数，非空时写入 manifest
+	Container *Container
+	// Redact 启用时以稳定的占位符替换域名、公网 IP、邮箱、密钥与 htpasswd 哈希，私钥与证书不写入归档
+	Redact bool
+	// RedactMapPath 为脱敏映射文件（占位符 -> 原始值）的路径，默认 <归档>.redact-map.json；已存在时沿用其中的占位符
+	RedactMapPath string
+
+	incremental *Incremental
+	// swagDirBase 非空时替代 manifest 中的 swag-dir 目录名（脱敏导出时使用）
+	swagDirBase string
 
 	Now func() time.Time
 }
@@ -38,6 +64,9 @@ type Result struct {
 	FileCount    int
 	TotalBytes   int64
 	ManifestPath string
+	Manifest     Manifest
+	// Redaction 为脱敏导出的结果，未启用脱敏时为 nil
+	Redaction *RedactionSummary
 }
 
 type fileEntry struct {
@@ -46,9 +75,15 @@ type fileEntry struct {
 	Size    int64
 	Mode    os.FileMode
 	ModTime time.Time
+	SHA256  string
+	// Linkname 为符号链接的目标路径，普通文件为空
+	Linkname string
+	// Content 非 nil 时写入该内容而不是读取 AbsPath（脱敏导出）
+	Content []byte
 }
 
-type manifest struct {
+// Manifest 是归档内 swag-cli-manifest.json 的内容
+type Manifest struct {
 	FormatVersion  int       `json:"formatVersion"`
 	CreatedAt      time.Time `json:"createdAt"`
 	SwagDirBase    string    `json:"swagDirBase"`
@@ -56,167 +91,297 @@ type manifest struct {
 	Profile        Profile   `json:"profile"`
 	IncludeSecrets bool      `json:"includeSecrets"`
 	ProxyConfOnly  bool      `json:"proxyConfOnly"`
+	Encrypted      bool      `json:"encrypted,omitempty"`
 	ExcludeGlobs   []string  `json:"excludeGlobs"`
-	Files          []struct {
-		Path string `json:"path"`
-		Size int64  `json:"size"`
-	} `json:"files"`
+	IncludeGlobs   []string  `json:"includeGlobs,omitempty"`
+	// CustomProfile 为导出时使用的自定义档位定义（Profile 为其名称）
+	CustomProfile *CustomProfile `json:"customProfile,omitempty"`
+	Files         []ManifestFile `json:"files"`
+	// Digest 为全部文件 sha256 的汇总摘要（见 ArchiveDigest），formatVersion 2 起提供
+	Digest string `json:"digest,omitempty"`
+	// Incremental 非空表示增量归档，Files 只包含相对基准新增或变化的文件
+	Incremental *Incremental `json:"incremental,omitempty"`
+	// Container 为导出时 SWAG 容器的运行参数（敏感环境变量已隐藏）
+	Container *Container `json:"container,omitempty"`
+	// Redacted 表示归档已脱敏，只用于分享，不能用于恢复
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// planOptions 返回 manifest 记录的文件范围选项，用于按导出时的规则重新规划现有目录
+func (m Manifest) planOptions() Options {
+	return Options{
+		Profile:        m.Profile,
+		IncludeSecrets: m.IncludeSecrets,
+		ProxyConfOnly:  m.ProxyConfOnly,
+		ExcludeGlobs:   m.ExcludeGlobs,
+		IncludeGlobs:   m.IncludeGlobs,
+		Custom:         m.CustomProfile,
+	}
 }
 
-const manifestName = "swag-cli-manifest.json"
+// ManifestFile 记录归档内的单个文件
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	// Symlink 表示该条目为符号链接，Size 与 SHA256 对应其目标路径
+	Symlink bool `json:"symlink,omitempty"`
+}
+
+const (
+	manifestName = "swag-cli-manifest.json"
+
+	// FormatVersion 为当前 manifest 格式版本（2: 增加 sha256 与 digest；3: 支持 tar 归档与符号链接条目）
+	FormatVersion = 3
+)
 
 func Export(opts Options) (Result, error) {
+	opts, swagDir, entries, err := prepareExport(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	outPath := strings.TrimSpace(opts.OutPath)
+	if opts.Format == "" {
+		opts.Format = FormatZip
+		if f, ok := FormatFromPath(outPath); ok {
+			opts.Format = f
+		}
+	}
+	switch {
+	case opts.Out != nil:
+		outPath = "-"
+	case outPath == "":
+		outPath = defaultOutPath(opts.Now(), opts.Format)
+		if opts.Encryption.Enabled() {
+			outPath += ".age"
+		}
+		outPath = filepath.Clean(outPath)
+	default:
+		outPath = filepath.Clean(outPath)
+	}
+	if !opts.Redact {
+		return writeArchive(outPath, swagDir, opts, entries)
+	}
+	return exportRedacted(outPath, swagDir, opts, entries)
+}
+
+// exportRedacted 脱敏后写入归档，成功后再保存映射文件
+func exportRedacted(outPath string, swagDir string, opts Options, entries []fileEntry) (Result, error) {
+	if strings.TrimSpace(opts.IncrementalFrom) != "" {
+		return Result{}, errors.New("脱敏导出不支持增量导出")
+	}
+	mapPath := strings.TrimSpace(opts.RedactMapPath)
+	if mapPath == "" {
+		if opts.Out != nil {
+			return Result{}, errRedactStream
+		}
+		mapPath = outPath + ".redact-map.json"
+	}
+
+	rm, err := LoadRedactionMap(mapPath)
+	if err != nil {
+		return Result{}, err
+	}
+	r := newRedactor(rm)
+	entries, dropped, err := redactEntries(entries, r)
+	if err != nil {
+		return Result{}, err
+	}
+	opts.Container = r.container(opts.Container)
+	// manifest 中的过滤规则与目录名同样可能包含域名，使用同一映射替换
+	opts.ExcludeGlobs = r.texts(opts.ExcludeGlobs)
+	opts.IncludeGlobs = r.texts(opts.IncludeGlobs)
+	opts.Custom = r.customProfile(opts.Custom)
+	opts.swagDirBase = r.text(filepath.Base(swagDir))
+
+	res, err := writeArchive(outPath, swagDir, opts, entries)
+	if err != nil {
+		return res, err
+	}
+	rm.UpdatedAt = opts.Now()
+	rm.Entries = r.entries
+	if err := saveRedactionMap(mapPath, rm); err != nil {
+		return res, err
+	}
+	res.Redaction = &RedactionSummary{MapPath: mapPath, Added: r.added, Dropped: dropped}
+	return res, nil
+}
+
+// prepareExport 补全默认值（增量导出时沿用基准归档的档位与过滤规则），返回将写入归档的文件
+func prepareExport(opts Options) (Options, string, []fileEntry, error) {
 	if opts.Now == nil {
 		opts.Now = time.Now
 	}
 	if strings.TrimSpace(opts.SwagDir) == "" {
-		return Result{}, errors.New("swag-dir 为空")
+		return opts, "", nil, errors.New("swag-dir 为空")
 	}
 
 	swagDir := filepath.Clean(strings.TrimSpace(opts.SwagDir))
+	var base *Manifest
+	if strings.TrimSpace(opts.IncrementalFrom) != "" {
+		m, err := ReadManifest(strings.TrimSpace(opts.IncrementalFrom), opts.BaseDecryption)
+		if err != nil {
+			return opts, "", nil, fmt.Errorf("读取基准归档失败: %w", err)
+		}
+		if m.Profile == ProfilePreImport {
+			return opts, "", nil, errors.New("不能以 pre-import 备份作为增量导出的基准")
+		}
+		planned := m.planOptions()
+		opts.Profile = planned.Profile
+		opts.IncludeSecrets = planned.IncludeSecrets
+		opts.ProxyConfOnly = planned.ProxyConfOnly
+		opts.ExcludeGlobs = planned.ExcludeGlobs
+		opts.IncludeGlobs = planned.IncludeGlobs
+		opts.Custom = planned.Custom
+		base = &m
+	}
 	if opts.Profile == "" {
 		opts.Profile = ProfileStandard
 	}
 
-	outPath := filepath.Clean(strings.TrimSpace(opts.OutPath))
-	if outPath == "" {
-		outPath = defaultOutPath(opts.Now())
-	}
-
 	entries, err := buildFilePlan(swagDir, opts)
 	if err != nil {
-		return Result{}, err
+		return opts, "", nil, err
+	}
+	if base != nil {
+		if entries, opts.incremental, err = incrementalEntries(entries, *base); err != nil {
+			return opts, "", nil, err
+		}
 	}
+	return opts, swagDir, entries, nil
+}
 
-	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
-		return Result{}, fmt.Errorf("创建导出目录失败: %w", err)
+// writeArchive 将文件与 manifest 写入归档；opts.Out 非空时写入该 Writer，否则写入 outPath
+func writeArchive(outPath string, swagDir string, opts Options, entries []fileEntry) (Result, error) {
+	// written 在归档完整写入后置为 true，否则删除未写完的文件
+	written := false
+	out := opts.Out
+	var zf *os.File
+	if out == nil {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return Result{}, fmt.Errorf("创建导出目录失败: %w", err)
+		}
+		// 归档可能包含密钥与私钥，只允许当前用户读取；不覆盖已有文件
+		var err error
+		zf, err = os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return Result{}, fmt.Errorf("创建导出文件失败 (%s): %w", outPath, err)
+		}
+		defer func() {
+			_ = zf.Close()
+			if !written {
+				_ = os.Remove(outPath)
+			}
+		}()
+		out = zf
 	}
 
-	zf, err := os.Create(outPath)
+	ew, err := encryptWriter(out, opts.Encryption)
 	if err != nil {
-		return Result{}, fmt.Errorf("创建导出文件失败 (%s): %w", outPath, err)
+		return Result{}, err
 	}
-	defer func() { _ = zf.Close() }()
-
-	zw := zip.NewWriter(zf)
-	defer func() { _ = zw.Close() }()
+	aw, err := newArchiveWriter(ew, opts.Format)
+	if err != nil {
+		return Result{}, err
+	}
+	defer func() { _ = aw.Close() }()
 
 	var total int64
-	for _, e := range entries {
-		n, err := writeZipFile(zw, e)
+	for i, e := range entries {
+		var n int64
+		var sum string
+		if e.Content != nil && e.Linkname == "" {
+			err = aw.writeBytes(e.RelPath, e.Content, e.Mode, e.ModTime)
+			n, sum = int64(len(e.Content)), sha256Hex(e.Content)
+		} else {
+			n, sum, err = aw.writeFile(e)
+		}
 		if err != nil {
 			return Result{}, err
 		}
+		entries[i].Size = n
+		entries[i].SHA256 = sum
 		total += n
 	}
 
-	manifestBytes, err := buildManifest(swagDir, opts, entries)
+	m, manifestBytes, err := buildManifest(swagDir, opts, entries)
 	if err != nil {
 		return Result{}, err
 	}
-	if err := writeZipBytes(zw, manifestName, manifestBytes, 0o644, opts.Now()); err != nil {
+	if err := aw.writeBytes(manifestName, manifestBytes, 0o644, opts.Now()); err != nil {
 		return Result{}, err
 	}
 
-	if err := zw.Close(); err != nil {
-		return Result{}, fmt.Errorf("写入 zip 失败: %w", err)
+	if err := aw.Close(); err != nil {
+		return Result{}, err
+	}
+	if err := ew.Close(); err != nil {
+		return Result{}, fmt.Errorf("写入加密归档失败: %w", err)
 	}
-	if err := zf.Close(); err != nil {
-		return Result{}, fmt.Errorf("关闭导出文件失败: %w", err)
+	if zf != nil {
+		if err := zf.Close(); err != nil {
+			return Result{}, fmt.Errorf("关闭导出文件失败: %w", err)
+		}
 	}
 
+	written = true
 	return Result{
 		OutPath:      outPath,
 		FileCount:    len(entries),
 		TotalBytes:   total,
 		ManifestPath: manifestName,
+		Manifest:     m,
 	}, nil
 }
 
-func defaultOutPath(now time.Time) string {
-	name := fmt.Sprintf("swag-export.%s.zip", now.Format("20060102-150405"))
+func defaultOutPath(now time.Time, format ArchiveFormat) string {
+	name := fmt.Sprintf("swag-export.%s%s", now.Format("20060102-150405"), format.Ext())
 	return filepath.Join(".", name)
 }
 
-func buildManifest(swagDir string, opts Options, entries []fileEntry) ([]byte, error) {
-	m := manifest{
-		FormatVersion:  1,
+func buildManifest(swagDir string, opts Options, entries []fileEntry) (Manifest, []byte, error) {
+	base := opts.swagDirBase
+	if base == "" {
+		base = filepath.Base(swagDir)
+	}
+	m := Manifest{
+		FormatVersion:  FormatVersion,
 		CreatedAt:      opts.Now(),
-		SwagDirBase:    filepath.Base(swagDir),
+		SwagDirBase:    base,
 		SwagCLIVersion: strings.TrimSpace(opts.Version),
 		Profile:        opts.Profile,
 		IncludeSecrets: opts.IncludeSecrets,
 		ProxyConfOnly:  opts.ProxyConfOnly,
+		Encrypted:      opts.Encryption.Enabled(),
 		ExcludeGlobs:   append([]string(nil), opts.ExcludeGlobs...),
+		IncludeGlobs:   append([]string(nil), opts.IncludeGlobs...),
+		CustomProfile:  opts.Custom,
+		Container:      opts.Container,
+		Redacted:       opts.Redact,
 	}
 
 	sort.Slice(entries, func(i, j int) bool { return entries[i].RelPath < entries[j].RelPath })
 	for _, e := range entries {
-		m.Files = append(m.Files, struct {
-			Path string `json:"path"`
-			Size int64  `json:"size"`
-		}{
-			Path: e.RelPath,
-			Size: e.Size,
+		m.Files = append(m.Files, ManifestFile{
+			Path:    e.RelPath,
+			Size:    e.Size,
+			SHA256:  e.SHA256,
+			Symlink: e.Linkname != "",
 		})
 	}
-
-	b, err := json.MarshalIndent(m, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("生成 manifest 失败: %w", err)
+	m.Digest = ArchiveDigest(m.Files)
+	if opts.incremental != nil {
+		inc := *opts.incremental
+		m.Incremental = &inc
+		inc.StateDigest = ArchiveDigest(m.State())
 	}
-	return b, nil
-}
-
-func writeZipFile(zw *zip.Writer, e fileEntry) (int64, error) {
-	if zw == nil {
-		return 0, errors.New("zip writer 为空")
-	}
-
-	f, err := os.Open(e.AbsPath)
-	if err != nil {
-		return 0, fmt.Errorf("打开文件失败 (%s): %w", e.AbsPath, err)
-	}
-	defer func() { _ = f.Close() }()
-
-	h := &zip.FileHeader{
-		Name:     toZipPath(e.RelPath),
-		Method:   zip.Deflate,
-		Modified: e.ModTime,
-	}
-	h.SetMode(e.Mode)
-
-	w, err := zw.CreateHeader(h)
-	if err != nil {
-		return 0, fmt.Errorf("写入 zip 条目失败 (%s): %w", e.RelPath, err)
-	}
-
-	n, err := io.Copy(w, f)
-	if err != nil {
-		return n, fmt.Errorf("写入 zip 内容失败 (%s): %w", e.RelPath, err)
-	}
-	return n, nil
-}
 
-func writeZipBytes(zw *zip.Writer, relPath string, data []byte, mode os.FileMode, modTime time.Time) error {
-	if zw == nil {
-		return errors.New("zip writer 为空")
-	}
-	h := &zip.FileHeader{
-		Name:     toZipPath(relPath),
-		Method:   zip.Deflate,
-		Modified: modTime,
-	}
-	h.SetMode(mode)
-
-	w, err := zw.CreateHeader(h)
+	b, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
-		return fmt.Errorf("写入 zip 条目失败 (%s): %w", relPath, err)
-	}
-	if _, err := w.Write(data); err != nil {
-		return fmt.Errorf("写入 zip 内容失败 (%s): %w", relPath, err)
+		return m, nil, fmt.Errorf("生成 manifest 失败: %w", err)
 	}
-	return nil
+	return m, b, nil
 }
 
 func toZipPath(rel string) string {
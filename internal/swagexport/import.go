@@ -0,0 +1,555 @@
+package swagexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImportOptions 描述从导出归档恢复的参数
+type ImportOptions struct {
+	ArchivePath string
+	// Increments 为按顺序在 ArchivePath 之后应用的增量归档
+	Increments []string
+	SwagDir    string
+	// Only 限定只恢复部分内容，可为子集名（如 proxy-confs，见 Subsets）或归档内路径前缀
+	Only []string
+	// BackupDir 为恢复前备份的输出目录，默认 <swag-dir>/backups
+	BackupDir string
+	NoBackup  bool
+	// Decryption 为加密归档的解密凭据
+	Decryption Decryption
+	// IncrementDecryptions 与 Increments 一一对应，为各增量归档的解密凭据；缺少时沿用 Decryption
+	IncrementDecryptions []Decryption
+
+	Now func() time.Time
+}
+
+// ImportAction 表示单个文件的恢复动作
+type ImportAction string
+
+const (
+	ActionCreate    ImportAction = "create"
+	ActionOverwrite ImportAction = "overwrite"
+	ActionUnchanged ImportAction = "unchanged"
+	// ActionDelete 表示增量归档记录该文件已删除，恢复时删除现有文件
+	ActionDelete ImportAction = "delete"
+)
+
+// ImportEntry 为恢复计划中的单个文件
+type ImportEntry struct {
+	Path    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+	Action  ImportAction
+	// Linkname 为符号链接的目标路径，普通文件为空
+	Linkname string
+}
+
+// ImportPlan 为恢复计划
+type ImportPlan struct {
+	// Manifest 为最后一个归档的 manifest
+	Manifest Manifest
+	// Chain 为按恢复顺序排列的全部归档 manifest（完整归档 + 增量归档）
+	Chain   []Manifest
+	Entries []ImportEntry
+}
+
+// ImportResult 为恢复结果
+type ImportResult struct {
+	Plan       ImportPlan
+	Written    int
+	Deleted    int
+	BackupPath string
+}
+
+// Subsets 为 --only 支持的子集名及其对应的归档路径前缀
+var Subsets = map[string][]string{
+	"compose":            {"compose.yaml"},
+	"nginx":              {"config/nginx/"},
+	"proxy-confs":        {"config/nginx/proxy-confs/"},
+	"site-confs":         {"config/nginx/site-confs/", "config/nginx/site-conf/"},
+	"custom-cont-init.d": {"config/custom-cont-init.d/"},
+	"custom-services.d":  {"config/custom-services.d/"},
+	"crontabs":           {"config/crontabs/"},
+	"php":                {"config/php/"},
+	"www":                {"config/www/"},
+	"dns-conf":           {"config/dns-conf/"},
+	"keys":               {"config/keys/"},
+	"letsencrypt":        {"config/etc/letsencrypt/"},
+	"fail2ban":           {"config/fail2ban/"},
+}
+
+// ReadManifest 读取归档中的 manifest
+func ReadManifest(archivePath string, dec Decryption) (Manifest, error) {
+	a, cleanup, err := openArchive(archivePath, dec)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer cleanup()
+	return readManifest(a)
+}
+
+// PlanImport 校验归档（及增量链）并计算恢复计划（不写入任何文件）
+func PlanImport(opts ImportOptions) (ImportPlan, error) {
+	archives, cleanup, err := openChain(opts)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	defer cleanup()
+
+	plan, _, err := planImport(archives, opts)
+	return plan, err
+}
+
+// Import 将归档内容恢复到 SwagDir：先校验 manifest 与路径，再备份将被覆盖的文件，最后按原权限与修改时间写入
+func Import(opts ImportOptions) (ImportResult, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	archives, cleanup, err := openChain(opts)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer cleanup()
+
+	plan, files, err := planImport(archives, opts)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res := ImportResult{Plan: plan}
+	swagDir := filepath.Clean(strings.TrimSpace(opts.SwagDir))
+
+	if !opts.NoBackup {
+		backupPath, err := backupBeforeImport(swagDir, opts, plan)
+		if err != nil {
+			return res, err
+		}
+		res.BackupPath = backupPath
+	}
+
+	for _, e := range plan.Entries {
+		switch e.Action {
+		case ActionUnchanged:
+			continue
+		case ActionDelete:
+			if err := os.Remove(filepath.Join(swagDir, filepath.FromSlash(e.Path))); err != nil && !os.IsNotExist(err) {
+				return res, fmt.Errorf("删除文件失败 (%s): %w", e.Path, err)
+			}
+			res.Deleted++
+			continue
+		}
+		if err := restoreFile(swagDir, e, files[e.Path]); err != nil {
+			return res, err
+		}
+		res.Written++
+	}
+	return res, nil
+}
+
+// openChain 打开 ArchivePath 与全部增量归档，每个归档使用各自的解密凭据；
+// 任一归档无法打开时直接返回错误，此时尚未写入任何文件
+func openChain(opts ImportOptions) ([]*archiveReader, func(), error) {
+	var archives []*archiveReader
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+	for i, p := range append([]string{opts.ArchivePath}, opts.Increments...) {
+		dec := opts.Decryption
+		if i > 0 && i-1 < len(opts.IncrementDecryptions) {
+			dec = opts.IncrementDecryptions[i-1]
+		}
+		a, c, err := openArchive(p, dec)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		archives = append(archives, a)
+		cleanups = append(cleanups, c)
+	}
+	return archives, cleanup, nil
+}
+
+func readManifest(a *archiveReader) (Manifest, error) {
+	for _, f := range a.Files {
+		if f.Name != manifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return Manifest{}, fmt.Errorf("读取 manifest 失败: %w", err)
+		}
+		defer func() { _ = rc.Close() }()
+
+		var m Manifest
+		if err := json.NewDecoder(rc).Decode(&m); err != nil {
+			return Manifest{}, fmt.Errorf("解析 manifest 失败: %w", err)
+		}
+		if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+			return Manifest{}, fmt.Errorf("不支持的 manifest formatVersion: %d (当前支持 1-%d)", m.FormatVersion, FormatVersion)
+		}
+		return m, nil
+	}
+	return Manifest{}, fmt.Errorf("归档中缺少 %s，不是 swag-cli 导出的文件", manifestName)
+}
+
+func planImport(archives []*archiveReader, opts ImportOptions) (ImportPlan, map[string]*archiveFile, error) {
+	swagDir := filepath.Clean(strings.TrimSpace(opts.SwagDir))
+	if strings.TrimSpace(opts.SwagDir) == "" {
+		return ImportPlan{}, nil, errors.New("swag-dir 为空")
+	}
+
+	prefixes, err := resolveOnly(opts.Only)
+	if err != nil {
+		return ImportPlan{}, nil, err
+	}
+
+	// 依次应用完整归档与增量归档，得到最终状态
+	var plan ImportPlan
+	state := make(map[string]*archiveFile)
+	deleted := make(map[string]bool)
+	for _, a := range archives {
+		m, files, err := checkArchive(a)
+		if err != nil {
+			return ImportPlan{}, nil, err
+		}
+		if m.Redacted {
+			return ImportPlan{}, nil, errors.New("归档已脱敏（swag export --redact），只用于分享，不能用于恢复")
+		}
+		if err := checkChain(plan.Chain, m); err != nil {
+			return ImportPlan{}, nil, err
+		}
+		plan.Chain = append(plan.Chain, m)
+
+		for _, mf := range m.Files {
+			state[mf.Path] = files[mf.Path]
+			delete(deleted, mf.Path)
+		}
+		if m.Incremental == nil {
+			continue
+		}
+		for _, uf := range m.Incremental.Unchanged {
+			if _, ok := state[uf.Path]; !ok {
+				return ImportPlan{}, nil, fmt.Errorf("恢复链不完整: 增量归档沿用的 %s 不在之前的归档中", uf.Path)
+			}
+		}
+		for _, d := range m.Incremental.Deleted {
+			if err := validateArchivePath(d); err != nil {
+				return ImportPlan{}, nil, err
+			}
+			delete(state, d)
+			deleted[d] = true
+		}
+	}
+	if len(plan.Chain) == 0 {
+		return ImportPlan{}, nil, errors.New("未指定归档")
+	}
+	plan.Manifest = plan.Chain[len(plan.Chain)-1]
+
+	for p, f := range state {
+		if link := symlinkAncestor(p, state); link != "" {
+			return ImportPlan{}, nil, fmt.Errorf("归档包含位于符号链接 %s 之下的路径: %q", link, p)
+		}
+		if !matchPrefixes(p, prefixes) {
+			continue
+		}
+
+		action, err := compareLive(filepath.Join(swagDir, filepath.FromSlash(p)), f)
+		if err != nil {
+			return ImportPlan{}, nil, err
+		}
+		plan.Entries = append(plan.Entries, ImportEntry{
+			Path:     p,
+			Size:     f.Size,
+			Mode:     f.Mode,
+			ModTime:  f.Modified,
+			Action:   action,
+			Linkname: f.Linkname,
+		})
+	}
+	for p := range deleted {
+		if !matchPrefixes(p, prefixes) {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(swagDir, filepath.FromSlash(p)))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		plan.Entries = append(plan.Entries, ImportEntry{Path: p, Action: ActionDelete})
+	}
+
+	sort.Slice(plan.Entries, func(i, j int) bool { return plan.Entries[i].Path < plan.Entries[j].Path })
+	return plan, state, nil
+}
+
+// checkArchive 校验单个归档：路径安全、manifest 摘要、每个文件的大小与 sha256，以及未记录的文件
+func checkArchive(a *archiveReader) (Manifest, map[string]*archiveFile, error) {
+	m, err := readManifest(a)
+	if err != nil {
+		return Manifest{}, nil, err
+	}
+
+	files := a.entries()
+	for name := range files {
+		if err := validateArchivePath(name); err != nil {
+			return Manifest{}, nil, err
+		}
+		if link := symlinkAncestor(name, files); link != "" {
+			return Manifest{}, nil, fmt.Errorf("归档包含位于符号链接 %s 之下的路径: %q", link, name)
+		}
+	}
+
+	if m.Digest != "" && ArchiveDigest(m.Files) != m.Digest {
+		return Manifest{}, nil, errors.New("归档校验失败: manifest 的 digest 与文件清单不符")
+	}
+	if m.Incremental != nil && ArchiveDigest(m.State()) != m.Incremental.StateDigest {
+		return Manifest{}, nil, errors.New("归档校验失败: 增量 manifest 的 stateDigest 与文件清单不符")
+	}
+
+	listed := make(map[string]bool, len(m.Files))
+	for _, mf := range m.Files {
+		if err := validateArchivePath(mf.Path); err != nil {
+			return Manifest{}, nil, err
+		}
+		listed[mf.Path] = true
+		f, ok := files[mf.Path]
+		if !ok {
+			return Manifest{}, nil, fmt.Errorf("归档不完整: manifest 中的 %s 不存在", mf.Path)
+		}
+		if f.Size != mf.Size {
+			return Manifest{}, nil, fmt.Errorf("归档不完整: %s 大小与 manifest 不符", mf.Path)
+		}
+		if mf.SHA256 != "" {
+			sum, err := hashEntry(f)
+			if err != nil {
+				return Manifest{}, nil, err
+			}
+			if sum != mf.SHA256 {
+				return Manifest{}, nil, fmt.Errorf("归档校验失败: %s 的 sha256 与 manifest 不符", mf.Path)
+			}
+		}
+	}
+	for name := range files {
+		if !listed[name] {
+			return Manifest{}, nil, fmt.Errorf("归档包含 manifest 未记录的文件: %s", name)
+		}
+	}
+	return m, files, nil
+}
+
+// validateArchivePath 拒绝绝对路径与包含 .. 的路径（zip-slip）
+func validateArchivePath(p string) error {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, "\\") || filepath.IsAbs(p) || filepath.VolumeName(p) != "" {
+		return fmt.Errorf("归档包含不安全的路径: %q", p)
+	}
+	if clean := path.Clean(p); clean != p || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("归档包含不安全的路径: %q", p)
+	}
+	return nil
+}
+
+// symlinkAncestor 返回 name 的上级路径中作为符号链接条目出现的路径，防止经由符号链接写出 swag-dir
+func symlinkAncestor(name string, files map[string]*archiveFile) string {
+	for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if f, ok := files[dir]; ok && f.Linkname != "" {
+			return dir
+		}
+	}
+	return ""
+}
+
+func resolveOnly(only []string) ([]string, error) {
+	var prefixes []string
+	for _, o := range only {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			continue
+		}
+		if p, ok := Subsets[strings.ToLower(o)]; ok {
+			prefixes = append(prefixes, p...)
+			continue
+		}
+		if !strings.Contains(o, "/") && o != "compose.yaml" {
+			names := make([]string, 0, len(Subsets))
+			for k := range Subsets {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("未知的 --only 子集: %s (可选: %s，或使用归档内路径前缀)", o, strings.Join(names, ", "))
+		}
+		prefixes = append(prefixes, strings.TrimPrefix(filepath.ToSlash(o), "/"))
+	}
+	return prefixes, nil
+}
+
+func matchPrefixes(p string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if p == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(p, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func compareLive(livePath string, f *archiveFile) (ImportAction, error) {
+	info, err := os.Lstat(livePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ActionCreate, nil
+		}
+		return "", fmt.Errorf("读取现有文件失败 (%s): %w", livePath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("目标路径是目录，无法恢复文件: %s", livePath)
+	}
+	// 类型不同（符号链接与普通文件）时直接覆盖
+	if (info.Mode()&os.ModeSymlink != 0) != (f.Linkname != "") {
+		return ActionOverwrite, nil
+	}
+	if f.Linkname == "" && info.Size() != f.Size {
+		return ActionOverwrite, nil
+	}
+
+	live, _, err := readLive(livePath)
+	if err != nil {
+		return "", err
+	}
+	archived, err := readEntry(f)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(live, archived) {
+		return ActionUnchanged, nil
+	}
+	return ActionOverwrite, nil
+}
+
+// backupBeforeImport 将即将被覆盖的现有文件打包为一个可再次导入的归档；无文件被覆盖时不创建
+func backupBeforeImport(swagDir string, opts ImportOptions, plan ImportPlan) (string, error) {
+	var entries []fileEntry
+	for _, e := range plan.Entries {
+		if e.Action != ActionOverwrite && e.Action != ActionDelete {
+			continue
+		}
+		abs := filepath.Join(swagDir, filepath.FromSlash(e.Path))
+		fe, ok, err := liveFileEntry(abs, e.Path)
+		if err != nil {
+			return "", fmt.Errorf("读取现有文件失败 (%s): %w", abs, err)
+		}
+		if ok {
+			entries = append(entries, fe)
+		}
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	dir := strings.TrimSpace(opts.BackupDir)
+	if dir == "" {
+		dir = filepath.Join(swagDir, "backups")
+	}
+	f, outPath, err := createUniqueFile(dir, "swag-pre-import."+opts.Now().Format("20060102-150405"), ".zip")
+	if err != nil {
+		return "", fmt.Errorf("恢复前备份失败: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := writeArchive(outPath, swagDir, Options{Profile: ProfilePreImport, Out: f, Now: opts.Now}, entries); err != nil {
+		_ = os.Remove(outPath)
+		return "", fmt.Errorf("恢复前备份失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(outPath)
+		return "", fmt.Errorf("恢复前备份失败: %w", err)
+	}
+	return outPath, nil
+}
+
+// createUniqueFile 以 O_EXCL 创建 <dir>/<name><ext>，已存在时依次尝试 <name>-2<ext>、<name>-3<ext>…，
+// 避免同一秒内的多次恢复覆盖之前的备份
+func createUniqueFile(dir, name, ext string) (*os.File, string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+	for i := 1; ; i++ {
+		p := filepath.Join(dir, name+ext)
+		if i > 1 {
+			p = filepath.Join(dir, fmt.Sprintf("%s-%d%s", name, i, ext))
+		}
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("创建备份文件失败 (%s): %w", p, err)
+		}
+		return f, p, nil
+	}
+}
+
+func restoreFile(swagDir string, e ImportEntry, f *archiveFile) error {
+	target := filepath.Join(swagDir, filepath.FromSlash(e.Path))
+	rel, err := filepath.Rel(swagDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("归档包含不安全的路径: %q", e.Path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败 (%s): %w", filepath.Dir(target), err)
+	}
+
+	tmp := target + ".swag-cli-tmp"
+	if e.Linkname != "" {
+		_ = os.Remove(tmp)
+		if err := os.Symlink(e.Linkname, tmp); err != nil {
+			return fmt.Errorf("创建符号链接失败 (%s): %w", target, err)
+		}
+		if err := os.Rename(tmp, target); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("创建符号链接失败 (%s): %w", target, err)
+		}
+		return nil
+	}
+
+	data, err := readEntry(f)
+	if err != nil {
+		return err
+	}
+
+	mode := e.Mode.Perm()
+	if mode == 0 {
+		mode = 0o644
+	}
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("写入文件失败 (%s): %w", target, err)
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("设置文件权限失败 (%s): %w", target, err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入文件失败 (%s): %w", target, err)
+	}
+	if !e.ModTime.IsZero() {
+		if err := os.Chtimes(target, e.ModTime, e.ModTime); err != nil {
+			return fmt.Errorf("设置修改时间失败 (%s): %w", target, err)
+		}
+	}
+	return nil
+}
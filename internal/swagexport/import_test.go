@@ -0,0 +1,206 @@
+package swagexport
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportRestoresFilesWithModesAndBackup(t *testing.T) {
+	t.Parallel()
+
+	swagDir := t.TempDir()
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services: {}\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "nginx.conf"), "worker_processes  1;\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "site-confs", "default"), "# default\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# a\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "custom-cont-init.d", "10-test.sh"), "echo hi\n")
+	if err := os.Chmod(filepath.Join(swagDir, "config", "custom-cont-init.d", "10-test.sh"), 0o755); err != nil {
+		t.Fatalf("chmod error = %v", err)
+	}
+	mtime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), mtime, mtime); err != nil {
+		t.Fatalf("chtimes error = %v", err)
+	}
+
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outZip, Profile: ProfileStandard, ProxyConfOnly: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	target := t.TempDir()
+	mustWrite(t, filepath.Join(target, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# changed\n")
+	mustWrite(t, filepath.Join(target, "compose.yaml"), "services: {}\n")
+
+	backupDir := t.TempDir()
+	now := func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	opts := ImportOptions{ArchivePath: outZip, SwagDir: target, BackupDir: backupDir, Now: now}
+
+	plan, err := PlanImport(opts)
+	if err != nil {
+		t.Fatalf("PlanImport() error = %v", err)
+	}
+	actions := map[string]ImportAction{}
+	for _, e := range plan.Entries {
+		actions[e.Path] = e.Action
+	}
+	if actions["config/nginx/proxy-confs/a.subdomain.conf"] != ActionOverwrite {
+		t.Fatalf("expected overwrite, got plan: %+v", actions)
+	}
+	if actions["compose.yaml"] != ActionUnchanged {
+		t.Fatalf("expected unchanged compose.yaml, got plan: %+v", actions)
+	}
+	if actions["config/nginx/nginx.conf"] != ActionCreate {
+		t.Fatalf("expected create nginx.conf, got plan: %+v", actions)
+	}
+
+	res, err := Import(opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if res.Written != len(plan.Entries)-1 {
+		t.Fatalf("Written = %d, want %d", res.Written, len(plan.Entries)-1)
+	}
+
+	b, err := os.ReadFile(filepath.Join(target, "config", "nginx", "proxy-confs", "a.subdomain.conf"))
+	if err != nil || string(b) != "# a\n" {
+		t.Fatalf("restored content = %q, err = %v", b, err)
+	}
+	info, err := os.Stat(filepath.Join(target, "config", "nginx", "proxy-confs", "a.subdomain.conf"))
+	if err != nil {
+		t.Fatalf("stat error = %v", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+	info, err = os.Stat(filepath.Join(target, "config", "custom-cont-init.d", "10-test.sh"))
+	if err != nil {
+		t.Fatalf("stat error = %v", err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Fatalf("mode = %v, want 0755", info.Mode().Perm())
+	}
+
+	if res.BackupPath != filepath.Join(backupDir, "swag-pre-import.20260102-030405.zip") {
+		t.Fatalf("unexpected backup path: %s", res.BackupPath)
+	}
+	files := listZipFiles(t, res.BackupPath)
+	assertHas(t, files, "config/nginx/proxy-confs/a.subdomain.conf")
+	assertNotHas(t, files, "compose.yaml")
+
+	// 同一秒内再次恢复不应覆盖之前的备份
+	mustWrite(t, filepath.Join(target, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# changed again\n")
+	again, err := Import(opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if again.BackupPath != filepath.Join(backupDir, "swag-pre-import.20260102-030405-2.zip") {
+		t.Fatalf("unexpected second backup path: %s", again.BackupPath)
+	}
+	assertHas(t, listZipFiles(t, res.BackupPath), "config/nginx/proxy-confs/a.subdomain.conf")
+	assertHas(t, listZipFiles(t, again.BackupPath), "config/nginx/proxy-confs/a.subdomain.conf")
+}
+
+func TestImportOnlySubset(t *testing.T) {
+	t.Parallel()
+
+	swagDir := t.TempDir()
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services: {}\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "nginx.conf"), "worker_processes  1;\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# a\n")
+
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outZip, Profile: ProfileMinimal, ProxyConfOnly: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	target := t.TempDir()
+	if _, err := Import(ImportOptions{ArchivePath: outZip, SwagDir: target, Only: []string{"proxy-confs"}}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "config", "nginx", "proxy-confs", "a.subdomain.conf")); err != nil {
+		t.Fatalf("proxy conf not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "compose.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("compose.yaml should not be restored, err = %v", err)
+	}
+
+	if _, err := PlanImport(ImportOptions{ArchivePath: outZip, SwagDir: target, Only: []string{"bogus"}}); err == nil {
+		t.Fatalf("PlanImport() should reject unknown subset")
+	}
+}
+
+func TestImportRejectsUnsafeArchives(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		files    map[string]string
+		manifest string
+	}{
+		"zip slip": {
+			files:    map[string]string{"../evil.conf": "x"},
+			manifest: `{"formatVersion":1,"files":[{"path":"../evil.conf","size":1}]}`,
+		},
+		"unlisted file": {
+			files:    map[string]string{"config/nginx/a.conf": "x"},
+			manifest: `{"formatVersion":1,"files":[]}`,
+		},
+		"missing file": {
+			manifest: `{"formatVersion":1,"files":[{"path":"config/nginx/a.conf","size":1}]}`,
+		},
+		"future format": {
+			manifest: `{"formatVersion":99,"files":[]}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			archive := filepath.Join(t.TempDir(), "bad.zip")
+			writeTestZip(t, archive, tc.files, tc.manifest)
+
+			target := t.TempDir()
+			if _, err := Import(ImportOptions{ArchivePath: archive, SwagDir: filepath.Join(target, "swag")}); err == nil {
+				t.Fatalf("Import() should fail")
+			}
+			if _, err := os.Stat(filepath.Join(target, "evil.conf")); !os.IsNotExist(err) {
+				t.Fatalf("file escaped swag-dir")
+			}
+		})
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string, manifest string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip error = %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create error = %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip write error = %v", err)
+		}
+	}
+	w, err := zw.Create(manifestName)
+	if err != nil {
+		t.Fatalf("zip create error = %v", err)
+	}
+	if _, err := strings.NewReader(manifest).WriteTo(w); err != nil {
+		t.Fatalf("zip write error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close error = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package swagexport
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Incremental 记录增量归档相对基准归档的变化
+type Incremental struct {
+	// BaseDigest 为基准归档恢复后完整状态的摘要（见 Manifest.StateDigest），用于校验恢复链的顺序
+	BaseDigest    string    `json:"baseDigest"`
+	BaseCreatedAt time.Time `json:"baseCreatedAt"`
+	// Unchanged 为与基准相同、未包含在本归档中的文件
+	Unchanged []ManifestFile `json:"unchanged"`
+	// Deleted 为基准中存在、导出时已删除的文件
+	Deleted []string `json:"deleted"`
+	// StateDigest 为应用本归档后完整状态的摘要
+	StateDigest string `json:"stateDigest"`
+}
+
+// State 返回恢复本归档后的完整文件清单：完整归档即 Files，增量归档为 Files 加上沿用基准的文件
+func (m Manifest) State() []ManifestFile {
+	if m.Incremental == nil {
+		return m.Files
+	}
+	out := append(append([]ManifestFile(nil), m.Files...), m.Incremental.Unchanged...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// StateDigest 返回恢复本归档后完整状态的摘要；完整归档与 Digest 相同
+func (m Manifest) StateDigest() string {
+	if m.Incremental == nil {
+		return m.Digest
+	}
+	return m.Incremental.StateDigest
+}
+
+// incrementalEntries 对比基准归档的完整状态，返回需要写入的新增/变化文件，以及沿用与删除的记录
+func incrementalEntries(entries []fileEntry, base Manifest) ([]fileEntry, *Incremental, error) {
+	if base.Digest == "" {
+		return nil, nil, errors.New("基准归档的 manifest 不含 sha256（formatVersion 1），无法增量导出")
+	}
+
+	baseState := make(map[string]ManifestFile)
+	for _, f := range base.State() {
+		baseState[f.Path] = f
+	}
+
+	inc := &Incremental{BaseDigest: base.StateDigest(), BaseCreatedAt: base.CreatedAt}
+	var changed []fileEntry
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.RelPath] = true
+		prev, ok := baseState[e.RelPath]
+		if !ok || prev.Symlink != (e.Linkname != "") {
+			changed = append(changed, e)
+			continue
+		}
+
+		var sum string
+		if e.Linkname != "" {
+			sum = sha256Hex([]byte(e.Linkname))
+		} else {
+			var err error
+			if sum, err = hashFile(e.AbsPath); err != nil {
+				return nil, nil, err
+			}
+		}
+		if sum != prev.SHA256 {
+			changed = append(changed, e)
+			continue
+		}
+		inc.Unchanged = append(inc.Unchanged, prev)
+	}
+	for p := range baseState {
+		if !seen[p] {
+			inc.Deleted = append(inc.Deleted, p)
+		}
+	}
+	sort.Strings(inc.Deleted)
+	sort.Slice(inc.Unchanged, func(i, j int) bool { return inc.Unchanged[i].Path < inc.Unchanged[j].Path })
+	return changed, inc, nil
+}
+
+// checkChain 校验恢复链：第一个必须为完整归档，之后每个增量归档的基准必须是前一个归档的状态
+func checkChain(prev []Manifest, m Manifest) error {
+	if len(prev) == 0 {
+		if m.Incremental != nil {
+			return errors.New("第一个归档是增量归档，需要先指定其基准（完整）归档")
+		}
+		return nil
+	}
+	if m.Incremental == nil {
+		return fmt.Errorf("恢复链中第 %d 个归档不是增量归档", len(prev)+1)
+	}
+	last := prev[len(prev)-1]
+	if m.Incremental.BaseDigest != last.StateDigest() {
+		return fmt.Errorf("恢复链中第 %d 个归档的基准不匹配（该增量基于 %s 导出的归档）", len(prev)+1, m.Incremental.BaseCreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package swagexport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncrementalExportAndChainImport(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	proxyConfs := filepath.Join(swagDir, "config", "nginx", "proxy-confs")
+	mustWrite(t, filepath.Join(proxyConfs, "old.subdomain.conf"), "# old\n")
+	out := t.TempDir()
+
+	base := filepath.Join(out, "base.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: base, Profile: ProfileMinimal}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	mustWrite(t, filepath.Join(proxyConfs, "a.subdomain.conf"), "# a changed\n")
+	mustWrite(t, filepath.Join(proxyConfs, "new.subdomain.conf"), "# new\n")
+	if err := os.Remove(filepath.Join(proxyConfs, "old.subdomain.conf")); err != nil {
+		t.Fatalf("remove error = %v", err)
+	}
+
+	inc1 := filepath.Join(out, "inc1.tar.gz")
+	// 档位沿用基准归档，传入的 Profile 被忽略
+	res, err := Export(Options{SwagDir: swagDir, OutPath: inc1, Profile: ProfileFull, IncrementalFrom: base})
+	if err != nil {
+		t.Fatalf("Export(incremental) error = %v", err)
+	}
+	m := res.Manifest
+	if m.Profile != ProfileMinimal || m.Incremental == nil {
+		t.Fatalf("incremental manifest = %+v", m)
+	}
+	if got := manifestPaths(m.Files); got != "config/nginx/proxy-confs/a.subdomain.conf,config/nginx/proxy-confs/new.subdomain.conf" {
+		t.Fatalf("Files = %s", got)
+	}
+	if strings.Join(m.Incremental.Deleted, ",") != "config/nginx/proxy-confs/old.subdomain.conf" {
+		t.Fatalf("Deleted = %v", m.Incremental.Deleted)
+	}
+	if len(m.Incremental.Unchanged) != 3 {
+		t.Fatalf("Unchanged = %+v", m.Incremental.Unchanged)
+	}
+
+	report, err := Verify(VerifyOptions{ArchivePath: inc1, SwagDir: swagDir})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() || !report.Live.Empty() {
+		t.Fatalf("Verify() = %+v, live = %+v", report, report.Live)
+	}
+
+	mustWrite(t, filepath.Join(proxyConfs, "new.subdomain.conf"), "# newer\n")
+	inc2 := filepath.Join(out, "inc2.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: inc2, IncrementalFrom: inc1}); err != nil {
+		t.Fatalf("Export(incremental) error = %v", err)
+	}
+
+	// 还原到带有已删除文件的目录：链式恢复后应与当前目录一致
+	target := t.TempDir()
+	mustWrite(t, filepath.Join(target, "config", "nginx", "proxy-confs", "old.subdomain.conf"), "# stale\n")
+	ires, err := Import(ImportOptions{ArchivePath: base, Increments: []string{inc1, inc2}, SwagDir: target, NoBackup: true})
+	if err != nil {
+		t.Fatalf("Import(chain) error = %v", err)
+	}
+	if ires.Deleted != 1 || len(ires.Plan.Chain) != 3 {
+		t.Fatalf("Import(chain) = %+v", ires)
+	}
+	for name, want := range map[string]string{"a.subdomain.conf": "# a changed\n", "new.subdomain.conf": "# newer\n"} {
+		b, err := os.ReadFile(filepath.Join(target, "config", "nginx", "proxy-confs", name))
+		if err != nil || string(b) != want {
+			t.Fatalf("%s = %q, err = %v", name, b, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, "config", "nginx", "proxy-confs", "old.subdomain.conf")); !os.IsNotExist(err) {
+		t.Fatalf("deleted file should be removed: %v", err)
+	}
+
+	for _, bad := range []ImportOptions{
+		{ArchivePath: inc1},
+		{ArchivePath: base, Increments: []string{inc2}},
+		{ArchivePath: base, Increments: []string{inc2, inc1}},
+	} {
+		bad.SwagDir = t.TempDir()
+		if _, err := PlanImport(bad); err == nil {
+			t.Fatalf("PlanImport(%v, %v) should reject the chain", filepath.Base(bad.ArchivePath), bad.Increments)
+		}
+	}
+}
+
+func manifestPaths(files []ManifestFile) string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return strings.Join(paths, ",")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -39,24 +40,11 @@ func buildFilePlan(swagDir string, opts Options) ([]fileEntry, error) {
 			return nil
 		}
 
-		info, err := os.Lstat(absPath)
-		if err != nil {
+		e, ok, err := liveFileEntry(absPath, filepath.ToSlash(relPath))
+		if err != nil || !ok {
 			return err
 		}
-		if info.Mode()&os.ModeSymlink != 0 {
-			return nil
-		}
-		if !info.Mode().IsRegular() {
-			return nil
-		}
-
-		out = append(out, fileEntry{
-			AbsPath: absPath,
-			RelPath: filepath.ToSlash(relPath),
-			Size:    info.Size(),
-			Mode:    info.Mode(),
-			ModTime: info.ModTime(),
-		})
+		out = append(out, e)
 		return nil
 	}
 
@@ -141,7 +129,8 @@ func buildFilePlan(swagDir string, opts Options) ([]fileEntry, error) {
 		return nil, err
 	}
 
-	if opts.Profile == ProfileStandard || opts.Profile == ProfileFull {
+	profile := opts.baseProfile()
+	if profile == ProfileStandard || profile == ProfileFull {
 		standardDirs := []struct {
 			abs string
 			rel string
@@ -159,7 +148,7 @@ func buildFilePlan(swagDir string, opts Options) ([]fileEntry, error) {
 		}
 	}
 
-	if opts.Profile == ProfileFull {
+	if profile == ProfileFull {
 		if opts.IncludeSecrets {
 			if err := addDirFiltered(filepath.Join(swagDir, "config", "dns-conf"), "config/dns-conf", nil); err != nil {
 				return nil, err
@@ -189,12 +178,45 @@ func buildFilePlan(swagDir string, opts Options) ([]fileEntry, error) {
 		}
 	}
 
+	out, err = applyIncludeRules(swagDir, opts, out, excluder)
+	if err != nil {
+		return nil, err
+	}
+
 	deduped := dedupeByRel(out)
 	return deduped, nil
 }
 
+// liveFileEntry 为普通文件或符号链接（记录目标路径，不跟随）构造条目；其他类型返回 false
+func liveFileEntry(absPath, relPath string) (fileEntry, bool, error) {
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return fileEntry{}, false, err
+	}
+	e := fileEntry{
+		AbsPath: absPath,
+		RelPath: relPath,
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(absPath)
+		if err != nil {
+			return fileEntry{}, false, fmt.Errorf("读取符号链接失败 (%s): %w", absPath, err)
+		}
+		e.Linkname = target
+		e.Size = int64(len(target))
+		return e, true, nil
+	case info.Mode().IsRegular():
+		return e, true, nil
+	}
+	return fileEntry{}, false, nil
+}
+
 func addFileIfExists(absPath string, relPath string, add func(abs string, rel string) error) error {
-	if _, err := os.Stat(absPath); err != nil {
+	if _, err := os.Lstat(absPath); err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
@@ -258,3 +280,51 @@ func dedupeByRel(in []fileEntry) []fileEntry {
 	sort.Slice(out, func(i, j int) bool { return out[i].RelPath < out[j].RelPath })
 	return out
 }
+
+// PlanDir 为导出计划中单个目录（不含子目录）的文件数与大小
+type PlanDir struct {
+	Dir   string
+	Files int
+	Bytes int64
+}
+
+// ExportPlan 为导出计划：将写入归档的文件及按目录的汇总
+type ExportPlan struct {
+	Profile Profile
+	Custom  *CustomProfile
+	Files   []ManifestFile
+	Dirs    []PlanDir
+	// TotalBytes 为文件总大小（符号链接按目标路径长度计）
+	TotalBytes int64
+	// Incremental 非空表示增量导出，Files 只包含新增或变化的文件
+	Incremental *Incremental
+}
+
+// PlanExport 按与 Export 相同的规则计算将导出的文件，不写入任何内容
+func PlanExport(opts Options) (ExportPlan, error) {
+	opts, _, entries, err := prepareExport(opts)
+	if err != nil {
+		return ExportPlan{}, err
+	}
+
+	plan := ExportPlan{Profile: opts.Profile, Custom: opts.Custom, Incremental: opts.incremental}
+	dirs := make(map[string]*PlanDir)
+	for _, e := range entries {
+		plan.Files = append(plan.Files, ManifestFile{Path: e.RelPath, Size: e.Size, Symlink: e.Linkname != ""})
+		plan.TotalBytes += e.Size
+
+		dir := path.Dir(e.RelPath)
+		d, ok := dirs[dir]
+		if !ok {
+			d = &PlanDir{Dir: dir}
+			dirs[dir] = d
+		}
+		d.Files++
+		d.Bytes += e.Size
+	}
+	for _, d := range dirs {
+		plan.Dirs = append(plan.Dirs, *d)
+	}
+	sort.Slice(plan.Dirs, func(i, j int) bool { return plan.Dirs[i].Dir < plan.Dirs[j].Dir })
+	return plan, nil
+}
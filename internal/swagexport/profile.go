@@ -0,0 +1,280 @@
+package swagexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CustomProfile 为用户自定义的导出档位：以内置档位为基础，按子目录增减文件
+type CustomProfile struct {
+	// Base 为基础档位（minimal/standard/full），为空时为 standard
+	Base        Profile       `json:"base"`
+	Description string        `json:"description,omitempty"`
+	Subtrees    []SubtreeRule `json:"subtrees"`
+}
+
+// SubtreeRule 为某个子目录（相对 swag-dir）的过滤规则，glob 相对该子目录。
+//
+// Include 非空时该子目录只导出匹配的文件（不在基础档位内的子目录也会被加入）；Exclude 从中排除。
+type SubtreeRule struct {
+	Path    string   `json:"path"`
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// ProfilesFile 为自定义档位文件的内容
+type ProfilesFile struct {
+	Profiles map[string]CustomProfile `json:"profiles"`
+}
+
+// BuiltinProfiles 返回内置的导出档位
+func BuiltinProfiles() []Profile {
+	return []Profile{ProfileMinimal, ProfileStandard, ProfileFull}
+}
+
+// LoadProfiles 读取自定义档位文件，文件不存在时返回空集合
+func LoadProfiles(p string) (map[string]CustomProfile, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]CustomProfile{}, nil
+		}
+		return nil, fmt.Errorf("读取自定义档位失败 (%s): %w", p, err)
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return map[string]CustomProfile{}, nil
+	}
+
+	var f ProfilesFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("解析自定义档位失败 (%s): %w", p, err)
+	}
+	out := make(map[string]CustomProfile, len(f.Profiles))
+	for name, cp := range f.Profiles {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if err := cp.validate(name); err != nil {
+			return nil, fmt.Errorf("自定义档位 %s 无效 (%s): %w", name, p, err)
+		}
+		if cp.Base == "" {
+			cp.Base = ProfileStandard
+		}
+		out[name] = cp
+	}
+	return out, nil
+}
+
+func (cp CustomProfile) validate(name string) error {
+	if name == "" {
+		return fmt.Errorf("名称为空")
+	}
+	if _, ok := builtinProfile(Profile(name)); ok || Profile(name) == ProfilePreImport {
+		return fmt.Errorf("不能与内置档位同名")
+	}
+	if cp.Base != "" {
+		if _, ok := builtinProfile(cp.Base); !ok {
+			return fmt.Errorf("base 只能是 minimal|standard|full: %s", cp.Base)
+		}
+	}
+	for _, r := range cp.Subtrees {
+		if _, err := cleanSubtree(r.Path); err != nil {
+			return err
+		}
+		if _, err := newGlobSet("include", r.Include); err != nil {
+			return err
+		}
+		if _, err := newGlobSet("exclude", r.Exclude); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveProfile 解析档位名：内置档位返回 nil，自定义档位返回其定义
+func ResolveProfile(name string, custom map[string]CustomProfile) (Profile, *CustomProfile, error) {
+	p := Profile(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := builtinProfile(p); ok {
+		return p, nil, nil
+	}
+	if cp, ok := custom[string(p)]; ok {
+		return p, &cp, nil
+	}
+
+	names := make([]string, 0, len(custom))
+	for n := range custom {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	avail := "minimal|standard|full"
+	if len(names) > 0 {
+		avail += "，自定义: " + strings.Join(names, "|")
+	}
+	return "", nil, fmt.Errorf("无效 profile: %s (可选: %s)", name, avail)
+}
+
+func builtinProfile(p Profile) (Profile, bool) {
+	for _, b := range BuiltinProfiles() {
+		if p == b {
+			return b, true
+		}
+	}
+	return "", false
+}
+
+// baseProfile 返回决定默认文件集合的内置档位
+func (o Options) baseProfile() Profile {
+	if o.Custom != nil {
+		if o.Custom.Base == "" {
+			return ProfileStandard
+		}
+		return o.Custom.Base
+	}
+	return o.Profile
+}
+
+func cleanSubtree(p string) (string, error) {
+	c := path.Clean(strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(p)), "/"))
+	if c == "." || c == ".." || strings.HasPrefix(c, "../") {
+		return "", fmt.Errorf("子目录路径无效: %q", p)
+	}
+	return c, nil
+}
+
+// isSecretDir 判断路径是否位于只有 --include-secrets 才会导出的目录
+func isSecretDir(rel string) bool {
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(rel, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyIncludeRules 按自定义档位的子目录规则与 --include-glob 调整基础档位选出的文件
+func applyIncludeRules(swagDir string, opts Options, entries []fileEntry, exclude *excluder) ([]fileEntry, error) {
+	var rules []SubtreeRule
+	if opts.Custom != nil {
+		rules = opts.Custom.Subtrees
+	}
+	include, err := newGlobSet("include-glob", opts.IncludeGlobs)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 && include == nil {
+		return entries, nil
+	}
+
+	type compiled struct {
+		dir              string
+		include, exclude *excluder
+	}
+	var crs []compiled
+	for _, r := range rules {
+		dir, err := cleanSubtree(r.Path)
+		if err != nil {
+			return nil, err
+		}
+		inc, err := newGlobSet("include", r.Include)
+		if err != nil {
+			return nil, err
+		}
+		exc, err := newGlobSet("exclude", r.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		crs = append(crs, compiled{dir: dir, include: inc, exclude: exc})
+	}
+
+	// keep 判断文件是否通过子目录规则（不在任何规则内的文件总是通过）；
+	// included 表示文件由某个子目录的 include 选中
+	keep := func(rel string) (keep bool, included bool) {
+		keep = true
+		for _, r := range crs {
+			sub, found := strings.CutPrefix(rel, r.dir+"/")
+			if !found {
+				continue
+			}
+			if r.include != nil {
+				if r.include.Match(sub) {
+					included = true
+				} else {
+					keep = false
+				}
+			}
+			if r.exclude.Match(sub) {
+				keep = false
+			}
+		}
+		return keep, included && keep
+	}
+
+	var out []fileEntry
+	for _, e := range entries {
+		if k, _ := keep(e.RelPath); k {
+			out = append(out, e)
+		}
+	}
+
+	// 收集子目录 include 与 --include-glob 额外匹配的文件；敏感目录仍需 --include-secrets
+	add := func(absPath, rel string) error {
+		if exclude.IsExcluded(rel) || (isSecretDir(rel) && !opts.IncludeSecrets) {
+			return nil
+		}
+		k, included := keep(rel)
+		if !included && !(k && include.Match(rel)) {
+			return nil
+		}
+		e, ok, err := liveFileEntry(absPath, rel)
+		if err != nil || !ok {
+			return err
+		}
+		out = append(out, e)
+		return nil
+	}
+
+	roots := map[string]bool{}
+	for _, r := range crs {
+		if r.include != nil {
+			roots[r.dir] = true
+		}
+	}
+	if include != nil {
+		roots = map[string]bool{".": true}
+	}
+	for root := range roots {
+		if err := walkFiles(swagDir, root, add); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// walkFiles 遍历 swag-dir 下的子目录（"." 为整个目录），对每个文件调用 fn(绝对路径, 归档路径)
+func walkFiles(swagDir, root string, fn func(absPath, rel string) error) error {
+	absRoot := filepath.Join(swagDir, filepath.FromSlash(root))
+	if st, err := os.Stat(absRoot); err != nil || !st.IsDir() {
+		return nil
+	}
+	return filepath.WalkDir(absRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(swagDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == "config/log" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(p, rel)
+	})
+}
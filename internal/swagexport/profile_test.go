@@ -0,0 +1,180 @@
+package swagexport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomProfileSubtreeRules(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "config", "www", "index.html"), "<html>\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "www", "assets", "app.js"), "js\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "www", "cache", "page.html"), "cached\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "fail2ban", "jail.local"), "[sshd]\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf.bak.conf"), "# bak\n")
+
+	profilesPath := filepath.Join(t.TempDir(), "export-profiles.json")
+	mustWrite(t, profilesPath, `{"profiles": {"Sites": {
+  "base": "minimal",
+  "subtrees": [
+    {"path": "config/www", "include": ["**/*.html"], "exclude": ["cache/**"]},
+    {"path": "config/fail2ban", "include": ["*.local"]},
+    {"path": "config/nginx/proxy-confs", "exclude": ["*.bak.conf"]}
+  ]}}}`)
+	custom, err := LoadProfiles(profilesPath)
+	if err != nil {
+		t.Fatalf("LoadProfiles() error = %v", err)
+	}
+	profile, cp, err := ResolveProfile("sites", custom)
+	if err != nil || cp == nil || cp.Base != ProfileMinimal {
+		t.Fatalf("ResolveProfile() = %q, %+v, %v", profile, cp, err)
+	}
+
+	files := mustExportAndList(t, Options{SwagDir: swagDir, OutPath: filepath.Join(t.TempDir(), "out.zip"), Profile: profile, Custom: cp, ProxyConfOnly: true})
+	assertHas(t, files, "config/www/index.html")
+	assertHas(t, files, "config/fail2ban/jail.local")
+	assertHas(t, files, "config/nginx/proxy-confs/a.subdomain.conf")
+	assertNotHas(t, files, "config/www/assets/app.js")
+	assertNotHas(t, files, "config/www/cache/page.html")
+	assertNotHas(t, files, "config/nginx/proxy-confs/a.subdomain.conf.bak.conf")
+
+	if _, _, err := ResolveProfile("nope", custom); err == nil || !strings.Contains(err.Error(), "sites") {
+		t.Fatalf("ResolveProfile() should list custom profiles, err = %v", err)
+	}
+}
+
+func TestLoadProfilesRejectsInvalidDefinitions(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range map[string]string{
+		"builtin name": `{"profiles": {"full": {"base": "minimal"}}}`,
+		"bad base":     `{"profiles": {"x": {"base": "pre-import"}}}`,
+		"escape":       `{"profiles": {"x": {"subtrees": [{"path": "../etc"}]}}}`,
+	} {
+		p := filepath.Join(t.TempDir(), "export-profiles.json")
+		mustWrite(t, p, content)
+		if _, err := LoadProfiles(p); err == nil {
+			t.Fatalf("LoadProfiles(%s) should fail", name)
+		}
+	}
+
+	got, err := LoadProfiles(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("LoadProfiles(missing) = %v, %v", got, err)
+	}
+}
+
+func TestIncludeGlobKeepsSecretsBehindFlag(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "config", "dns-conf", "cloudflare.ini"), "token=secret\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "geoip2db", "GeoLite2-City.conf"), "# geo\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "log", "nginx", "error.conf"), "# log\n")
+
+	opts := Options{SwagDir: swagDir, OutPath: filepath.Join(t.TempDir(), "out.zip"), Profile: ProfileMinimal, IncludeGlobs: []string{"**/*.conf", "config/dns-conf/*.ini"}}
+	files := mustExportAndList(t, opts)
+	assertHas(t, files, "config/geoip2db/GeoLite2-City.conf")
+	assertNotHas(t, files, "config/dns-conf/cloudflare.ini")
+	assertNotHas(t, files, "config/log/nginx/error.conf")
+
+	opts.IncludeSecrets = true
+	opts.OutPath = filepath.Join(t.TempDir(), "secrets.zip")
+	assertHas(t, mustExportAndList(t, opts), "config/dns-conf/cloudflare.ini")
+}
+
+func TestPlanExportSummarizesDirs(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "b.subdomain.conf"), "# bbb\n")
+
+	plan, err := PlanExport(Options{SwagDir: swagDir, Profile: ProfileMinimal})
+	if err != nil {
+		t.Fatalf("PlanExport() error = %v", err)
+	}
+	if len(plan.Files) != 5 {
+		t.Fatalf("Files = %+v", plan.Files)
+	}
+	var proxy PlanDir
+	for _, d := range plan.Dirs {
+		if d.Dir == "config/nginx/proxy-confs" {
+			proxy = d
+		}
+	}
+	if proxy.Files != 2 || proxy.Bytes != int64(len("# a\n")+len("# bbb\n")) {
+		t.Fatalf("proxy-confs = %+v, dirs = %+v", proxy, plan.Dirs)
+	}
+	if plan.Dirs[0].Dir != "." {
+		t.Fatalf("Dirs = %+v", plan.Dirs)
+	}
+
+	entries, err := os.ReadDir(swagDir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "swag-export.") {
+			t.Fatalf("PlanExport() should not write an archive: %s", e.Name())
+		}
+	}
+}
+
+func TestGlobDoubleStarMatchesZeroDirs(t *testing.T) {
+	t.Parallel()
+
+	g, err := newGlobSet("include", []string{"**/*.conf", "config/**/x"})
+	if err != nil {
+		t.Fatalf("newGlobSet() error = %v", err)
+	}
+	for p, want := range map[string]bool{
+		"a.conf":              true,
+		"config/nginx/a.conf": true,
+		"a.conf.bak":          false,
+		"config/x":            true,
+		"config/a/b/x":        true,
+	} {
+		if got := g.Match(p); got != want {
+			t.Fatalf("Match(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestDiffAndVerifyCustomProfileArchive(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	mustWrite(t, filepath.Join(swagDir, "config", "www", "index.html"), "<html>\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "dns-conf", "cloudflare.ini"), "dns_cloudflare_api_token = x\n")
+	cp := &CustomProfile{Base: ProfileMinimal, Subtrees: []SubtreeRule{{Path: "config/www", Include: []string{"**/*.html"}}}}
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{
+		SwagDir: swagDir, OutPath: outZip, Profile: "sites", Custom: cp, ProxyConfOnly: true,
+		IncludeSecrets: true, IncludeGlobs: []string{"config/dns-conf/*.ini"},
+	}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// 现有目录未变化时，自定义档位与 --include-glob 加入的文件不应显示为已删除或遗漏
+	report, err := Diff(DiffOptions{ArchivePath: outZip, SwagDir: swagDir})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(report.Changes) != 0 {
+		t.Fatalf("Diff() changes = %+v, want none", report.Changes)
+	}
+
+	mustWrite(t, filepath.Join(swagDir, "config", "www", "index.html"), "<html>changed\n")
+	verify, err := Verify(VerifyOptions{ArchivePath: outZip, SwagDir: swagDir})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	live := verify.Live
+	if live == nil || len(live.Missing) != 0 || len(live.Extra) != 0 || strings.Join(live.Modified, ",") != "config/www/index.html" {
+		t.Fatalf("Live = %+v, want only index.html modified", live)
+	}
+}
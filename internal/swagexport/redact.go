@@ -0,0 +1,450 @@
+package swagexport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RedactionMap 为脱敏映射文件的内容：占位符与原始值的对应关系，只保存在本地，不写入归档
+type RedactionMap struct {
+	FormatVersion int              `json:"formatVersion"`
+	UpdatedAt     time.Time        `json:"updatedAt"`
+	Entries       []RedactionEntry `json:"entries"`
+}
+
+// RedactionEntry 为一条脱敏记录
+type RedactionEntry struct {
+	// Kind 为 domain、host、email、ipv4、ipv6、secret 或 hash
+	Kind        string `json:"kind"`
+	Original    string `json:"original"`
+	Placeholder string `json:"placeholder"`
+}
+
+// RedactionSummary 描述一次脱敏导出
+type RedactionSummary struct {
+	MapPath string
+	// Added 为本次新增的映射数量
+	Added int
+	// Dropped 为无法脱敏（私钥、证书等）而未写入归档的文件
+	Dropped []string
+}
+
+const redactionMapFormat = 1
+
+// LoadRedactionMap 读取脱敏映射文件，文件不存在时返回空映射
+func LoadRedactionMap(p string) (RedactionMap, error) {
+	m := RedactionMap{FormatVersion: redactionMapFormat}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, fmt.Errorf("读取脱敏映射失败 (%s): %w", p, err)
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("解析脱敏映射失败 (%s): %w", p, err)
+	}
+	return m, nil
+}
+
+func saveRedactionMap(p string, m RedactionMap) error {
+	m.FormatVersion = redactionMapFormat
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化脱敏映射失败: %w", err)
+	}
+	if dir := filepath.Dir(p); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("创建目录失败 (%s): %w", dir, err)
+		}
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("写入脱敏映射失败 (%s): %w", tmp, err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入脱敏映射失败 (%s): %w", p, err)
+	}
+	return nil
+}
+
+var (
+	emailRe = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}`)
+	ipv4Re  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Re  = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+	hostRe  = regexp.MustCompile(`(?i)(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,62}[a-z0-9]`)
+
+	urlEnvRe      = regexp.MustCompile(`(?m)\b(URL|EXTRA_DOMAINS)\s*[=:]\s*["']?([^"'\s#]+)`)
+	serverNameRe  = regexp.MustCompile(`(?m)^\s*server_name\s+([^;]+);`)
+	dnsConfLineRe = regexp.MustCompile(`(?m)^(\s*[^#;\s][^=:\n]*?\s*[=:][ \t]*)(\S[^\n]*?)[ \t]*$`)
+	htpasswdRe    = regexp.MustCompile(`(?m)^([^:#\s][^:\n]*:)(\S+)$`)
+)
+
+// hostSuffixes 为看起来像域名、实际是文件名或 nginx 配置写法的后缀，不视为主机名
+var hostSuffixes = map[string]bool{
+	"conf": true, "disabled": true, "sample": true, "local": true, "ini": true, "log": true, "txt": true,
+	"md": true, "html": true, "htm": true, "php": true, "js": true, "css": true, "json": true, "yaml": true,
+	"yml": true, "xml": true, "sh": true, "py": true, "lua": true, "pem": true, "key": true, "crt": true,
+	"csr": true, "sock": true, "pid": true, "types": true, "gz": true, "zip": true, "png": true, "jpg": true,
+	"svg": true, "ico": true, "map": true, "db": true, "sqlite": true, "sqlite3": true, "so": true,
+	"bak": true, "old": true, "tmp": true, "example": true, "invalid": true, "localhost": true, "arpa": true,
+}
+
+// publicDomains 为镜像仓库、文档等公共域名，保留原样以便他人排查
+var publicDomains = []string{
+	"linuxserver.io", "lscr.io", "ghcr.io", "docker.io", "docker.com", "github.com", "githubusercontent.com",
+	"nginx.org", "nginx.com", "letsencrypt.org", "zerossl.com", "cloudflare.com", "duckdns.org",
+	"maxmind.com", "authelia.com", "goauthentik.io",
+}
+
+// redactor 以稳定的占位符替换域名、公网 IP、邮箱与密钥，同一原始值总是得到同一占位符
+type redactor struct {
+	byOriginal map[string]string
+	counts     map[string]int
+	entries    []RedactionEntry
+	added      int
+
+	domains  []string
+	domainRe *regexp.Regexp
+}
+
+func newRedactor(m RedactionMap) *redactor {
+	r := &redactor{byOriginal: make(map[string]string), counts: make(map[string]int)}
+	for _, e := range m.Entries {
+		r.byOriginal[e.Kind+"\x00"+e.Original] = e.Placeholder
+		r.counts[e.Kind]++
+		r.entries = append(r.entries, e)
+		if e.Kind == "domain" {
+			r.domains = append(r.domains, e.Original)
+		}
+	}
+	r.compileDomains()
+	return r
+}
+
+func (r *redactor) placeholder(kind, original string) string {
+	if kind == "domain" || kind == "host" || kind == "email" {
+		original = strings.ToLower(original)
+	}
+	key := kind + "\x00" + original
+	if p, ok := r.byOriginal[key]; ok {
+		return p
+	}
+	r.counts[kind]++
+	n := r.counts[kind]
+	var p string
+	switch kind {
+	case "domain":
+		p = fmt.Sprintf("domain%d.example", n)
+	case "host":
+		p = fmt.Sprintf("host%d.example", n)
+	case "email":
+		p = fmt.Sprintf("user%d@example.invalid", n)
+	case "ipv4":
+		// 使用文档保留地址段 203.0.113.0/24 与 198.51.100.0/24
+		if n <= 254 {
+			p = fmt.Sprintf("203.0.113.%d", n)
+		} else {
+			p = fmt.Sprintf("198.51.100.%d", (n-255)%254+1)
+		}
+	case "ipv6":
+		p = fmt.Sprintf("2001:db8::%x", n)
+	case "secret":
+		p = fmt.Sprintf("REDACTED-SECRET-%d", n)
+	case "hash":
+		p = fmt.Sprintf("REDACTED-HASH-%d", n)
+	default:
+		p = fmt.Sprintf("REDACTED-%d", n)
+	}
+	r.byOriginal[key] = p
+	r.entries = append(r.entries, RedactionEntry{Kind: kind, Original: original, Placeholder: p})
+	r.added++
+	return p
+}
+
+// addDomain 记录已知域名（URL、EXTRA_DOMAINS、server_name），其子域名只替换域名部分
+func (r *redactor) addDomain(d string) {
+	d = strings.ToLower(strings.Trim(strings.TrimSpace(d), ".*"))
+	d = strings.TrimPrefix(d, "*.")
+	if !strings.Contains(d, ".") || isPublicDomain(d) || !hostRe.MatchString(d) || hostRe.FindString(d) != d {
+		return
+	}
+	if hostSuffixes[d[strings.LastIndex(d, ".")+1:]] {
+		return
+	}
+	for _, known := range r.domains {
+		if known == d || strings.HasSuffix(d, "."+known) {
+			return
+		}
+	}
+	r.placeholder("domain", d)
+	r.domains = append(r.domains, d)
+	r.compileDomains()
+}
+
+func (r *redactor) compileDomains() {
+	if len(r.domains) == 0 {
+		r.domainRe = nil
+		return
+	}
+	ds := append([]string(nil), r.domains...)
+	sort.Slice(ds, func(i, j int) bool { return len(ds[i]) > len(ds[j]) })
+	for i, d := range ds {
+		ds[i] = regexp.QuoteMeta(d)
+	}
+	r.domainRe = regexp.MustCompile(`(?i)` + strings.Join(ds, "|"))
+}
+
+// collectDomains 从文本中收集 URL/EXTRA_DOMAINS 的值与 server_name 中的域名
+func (r *redactor) collectDomains(text string) {
+	for _, m := range urlEnvRe.FindAllStringSubmatch(text, -1) {
+		for _, d := range strings.Split(m[2], ",") {
+			r.addDomain(d)
+		}
+	}
+	for _, m := range serverNameRe.FindAllStringSubmatch(text, -1) {
+		for _, d := range strings.Fields(m[1]) {
+			if !strings.HasSuffix(d, ".*") {
+				r.addDomain(d)
+			}
+		}
+	}
+}
+
+// text 替换文本中的密钥、邮箱、公网 IP、已知域名与其他主机名
+func (r *redactor) text(s string) string {
+	return r.redact(s, true)
+}
+
+// redact 替换文本中的敏感值；hosts 为 false 时不替换已知域名之外的主机名（用于 js/html 等网页文件，避免误替换 window.location 之类的写法）
+func (r *redactor) redact(s string, hosts bool) string {
+	s = r.secrets(s)
+	s = replaceBounded(s, emailRe, func(m string) string { return r.placeholder("email", m) })
+	s = replaceBounded(s, ipv4Re, func(m string) string {
+		addr, err := netip.ParseAddr(m)
+		if err != nil || !isPublicIP(addr) {
+			return m
+		}
+		return r.placeholder("ipv4", m)
+	})
+	s = replaceBounded(s, ipv6Re, func(m string) string {
+		addr, err := netip.ParseAddr(m)
+		if err != nil || !addr.Is6() || !isPublicIP(addr) {
+			return m
+		}
+		return r.placeholder("ipv6", addr.String())
+	})
+	if r.domainRe != nil {
+		s = replaceBounded(s, r.domainRe, func(m string) string { return r.placeholder("domain", m) })
+	}
+	if !hosts {
+		return s
+	}
+	return replaceBounded(s, hostRe, func(m string) string {
+		l := strings.ToLower(m)
+		if hostSuffixes[l[strings.LastIndex(l, ".")+1:]] || isPublicDomain(l) {
+			return m
+		}
+		return r.placeholder("host", m)
+	})
+}
+
+// secrets 替换 token=xxx、password: xxx、Authorization "Basic xxx" 等值
+func (r *redactor) secrets(s string) string {
+	for _, re := range []*regexp.Regexp{secretValueRe, authHeaderRe} {
+		s = re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			if strings.HasPrefix(sub[2], "REDACTED-") || strings.HasPrefix(sub[2], "$") {
+				return m
+			}
+			return sub[1] + r.placeholder("secret", sub[2])
+		})
+	}
+	return s
+}
+
+// file 返回脱敏后的文件内容；dns-conf 中的所有值与 .htpasswd 中的密码哈希整体替换
+func (r *redactor) file(rel string, content []byte) []byte {
+	if !isText(content) {
+		return content
+	}
+	s := string(content)
+	switch {
+	case strings.HasPrefix(rel, "config/dns-conf/"):
+		s = dnsConfLineRe.ReplaceAllStringFunc(s, func(m string) string {
+			sub := dnsConfLineRe.FindStringSubmatch(m)
+			return sub[1] + r.placeholder("secret", sub[2])
+		})
+	case strings.EqualFold(filepath.Base(rel), ".htpasswd"):
+		s = htpasswdRe.ReplaceAllStringFunc(s, func(m string) string {
+			sub := htpasswdRe.FindStringSubmatch(m)
+			return sub[1] + r.placeholder("hash", sub[2])
+		})
+	}
+	return []byte(r.redact(s, !webFile(rel)))
+}
+
+func webFile(rel string) bool {
+	switch strings.ToLower(filepath.Ext(rel)) {
+	case ".js", ".mjs", ".css", ".html", ".htm", ".php", ".json", ".svg", ".md", ".txt":
+		return true
+	}
+	return false
+}
+
+// container 脱敏容器信息中的环境变量与标签
+func (r *redactor) container(c *Container) *Container {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Env = nil
+	for _, kv := range c.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		if k == "URL" || k == "EXTRA_DOMAINS" {
+			r.collectDomains(kv)
+		}
+		if v != maskedValue && (IsSecretEnv(k) || k == "EMAIL") {
+			v = r.placeholder("secret", v)
+		}
+		out.Env = append(out.Env, k+"="+r.text(v))
+	}
+	out.Labels = make(map[string]string, len(c.Labels))
+	for k, v := range c.Labels {
+		out.Labels[k] = r.text(v)
+	}
+	out.Mounts = nil
+	for _, m := range c.Mounts {
+		m.Source = r.text(m.Source)
+		out.Mounts = append(out.Mounts, m)
+	}
+	out.Ports = nil
+	for _, p := range c.Ports {
+		p.HostIP = r.text(p.HostIP)
+		out.Ports = append(out.Ports, p)
+	}
+	return &out
+}
+
+// texts 逐个脱敏字符串列表
+func (r *redactor) texts(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = r.text(v)
+	}
+	return out
+}
+
+// customProfile 脱敏自定义档位的说明、子目录与 glob
+func (r *redactor) customProfile(c *CustomProfile) *CustomProfile {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Description = r.text(c.Description)
+	out.Subtrees = nil
+	for _, st := range c.Subtrees {
+		out.Subtrees = append(out.Subtrees, SubtreeRule{Path: r.text(st.Path), Include: r.texts(st.Include), Exclude: r.texts(st.Exclude)})
+	}
+	return &out
+}
+
+// redactEntries 读取文件并生成脱敏后的条目；私钥、证书等无法脱敏的文件不写入归档
+func redactEntries(entries []fileEntry, r *redactor) ([]fileEntry, []string, error) {
+	contents := make([][]byte, len(entries))
+	for i, e := range entries {
+		if e.Linkname != "" || !e.Mode.IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(e.AbsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取文件失败 (%s): %w", e.AbsPath, err)
+		}
+		contents[i] = b
+		if isText(b) {
+			r.collectDomains(string(b))
+		}
+	}
+
+	var out []fileEntry
+	var dropped []string
+	for i, e := range entries {
+		if unredactable(e.RelPath) {
+			dropped = append(dropped, e.RelPath)
+			continue
+		}
+		e.RelPath = r.text(e.RelPath)
+		if e.Linkname != "" {
+			e.Linkname = r.text(e.Linkname)
+			e.Size = int64(len(e.Linkname))
+		} else if contents[i] != nil {
+			e.Content = r.file(entries[i].RelPath, contents[i])
+			e.Size = int64(len(e.Content))
+		}
+		out = append(out, e)
+	}
+	return dedupeByRel(out), dropped, nil
+}
+
+// unredactable 判断文件是否为私钥、证书等无法按文本脱敏的敏感文件
+func unredactable(rel string) bool {
+	if strings.HasPrefix(rel, "config/dns-conf/") || strings.EqualFold(filepath.Base(rel), ".htpasswd") {
+		return false
+	}
+	return IsSecretPath(rel) || strings.HasSuffix(strings.ToLower(rel), ".crt")
+}
+
+func isPublicIP(a netip.Addr) bool {
+	return a.IsValid() && a.IsGlobalUnicast() && !a.IsPrivate() && !netip.MustParsePrefix("100.64.0.0/10").Contains(a) &&
+		!netip.MustParsePrefix("192.0.2.0/24").Contains(a) && !netip.MustParsePrefix("198.51.100.0/24").Contains(a) &&
+		!netip.MustParsePrefix("203.0.113.0/24").Contains(a) && !netip.MustParsePrefix("2001:db8::/32").Contains(a)
+}
+
+func isPublicDomain(d string) bool {
+	for _, p := range publicDomains {
+		if d == p || strings.HasSuffix(d, "."+p) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceBounded 替换正则匹配，但跳过前后紧邻字母、数字、"-" 或 "_"（即属于更长标识符）的匹配。
+// 前后的 "." 不视为边界内字符，因此子域名（jellyfin.example.com）与文件名（example.com.conf）中的域名也会被替换。
+func replaceBounded(s string, re *regexp.Regexp, fn func(string) string) string {
+	idx := re.FindAllStringIndex(s, -1)
+	if len(idx) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range idx {
+		start, end := m[0], m[1]
+		if (start > 0 && isWordChar(s[start-1])) || (end < len(s) && isWordChar(s[end])) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(fn(s[start:end]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+var errRedactStream = errors.New("输出到 stdout 时需要用 --redact-map 指定脱敏映射文件的路径")
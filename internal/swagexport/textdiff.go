@@ -0,0 +1,181 @@
+package swagexport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLCSCells 限制 LCS 表的大小，超过时把中间差异整体视为替换
+const maxLCSCells = 4_000_000
+
+type diffOp struct {
+	kind byte // ' '、'-' 或 '+'
+	line string
+}
+
+// UnifiedDiff 生成与 diff -u 相同格式的统一 diff；内容相同时返回空字符串
+func UnifiedDiff(fromName, toName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	start := max(changes[0]-context, 0)
+	last := changes[0]
+	for _, c := range changes[1:] {
+		if c-last <= 2*context {
+			last = c
+			continue
+		}
+		writeHunk(&sb, ops, start, min(last+1+context, len(ops)))
+		start, last = c-context, c
+	}
+	writeHunk(&sb, ops, start, min(last+1+context, len(ops)))
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp, start, end int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+	oldLen, newLen := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			oldLen++
+		}
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+	if oldLen == 0 {
+		oldStart--
+	}
+	if newLen == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.kind)
+		if strings.HasSuffix(op.line, "\n") {
+			sb.WriteString(op.line)
+		} else {
+			sb.WriteString(op.line)
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+// splitLines 按行拆分并保留换行符，以便区分结尾是否有换行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(am)*len(bm) > maxLCSCells {
+		for _, l := range am {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range bm {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		ops = append(ops, lcsOps(am, bm)...)
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	// dp[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	dp := make([][]int32, n+1)
+	for i := range dp {
+		dp[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,195 @@
+package swagexport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VerifyOptions 描述归档校验参数
+type VerifyOptions struct {
+	ArchivePath string
+	// SwagDir 非空时，额外将归档与现有 SWAG 目录比对
+	SwagDir string
+	// Decryption 为加密归档的解密凭据
+	Decryption Decryption
+}
+
+// FileDiff 为文件清单比对结果
+type FileDiff struct {
+	Missing  []string // manifest 中有，但对方不存在
+	Modified []string // 内容（sha256 或大小）不一致
+	Extra    []string // 对方存在，但 manifest 未记录
+}
+
+// Empty 返回是否没有任何差异
+func (d FileDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Modified) == 0 && len(d.Extra) == 0
+}
+
+// VerifyReport 为校验结果
+type VerifyReport struct {
+	Manifest Manifest
+	// HasHashes 表示 manifest 是否包含 sha256（formatVersion 1 的归档只能校验大小）
+	HasHashes bool
+	// DigestOK 表示 manifest 中的 digest 与文件 sha256 汇总一致
+	DigestOK bool
+	// Archive 为归档内容与 manifest 的比对结果
+	Archive FileDiff
+	// Live 为现有 SWAG 目录与 manifest 的比对结果，未指定 SwagDir 时为 nil
+	Live *FileDiff
+}
+
+// OK 返回归档是否完整（不含与现有目录的差异）
+func (r VerifyReport) OK() bool {
+	return r.Archive.Empty() && (!r.HasHashes || r.DigestOK)
+}
+
+// ArchiveDigest 计算文件清单的汇总摘要：按路径排序后对 "<sha256>  <path>\n" 行（sha256sum 格式）整体求 sha256
+func ArchiveDigest(files []ManifestFile) string {
+	sorted := append([]ManifestFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	h := sha256.New()
+	for _, f := range sorted {
+		fmt.Fprintf(h, "%s  %s\n", f.SHA256, f.Path)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify 校验归档内容与 manifest 是否一致，并可选地与现有 SWAG 目录比对
+func Verify(opts VerifyOptions) (VerifyReport, error) {
+	a, cleanup, err := openArchive(opts.ArchivePath, opts.Decryption)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	defer cleanup()
+
+	m, err := readManifest(a)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	report := VerifyReport{Manifest: m, HasHashes: m.Digest != ""}
+
+	files := a.entries()
+
+	listed := make(map[string]bool, len(m.Files))
+	for _, mf := range m.Files {
+		listed[mf.Path] = true
+		f, ok := files[mf.Path]
+		if !ok {
+			report.Archive.Missing = append(report.Archive.Missing, mf.Path)
+			continue
+		}
+		if f.Size != mf.Size {
+			report.Archive.Modified = append(report.Archive.Modified, mf.Path)
+			continue
+		}
+		if mf.SHA256 == "" {
+			continue
+		}
+		sum, err := hashEntry(f)
+		if err != nil {
+			return report, err
+		}
+		if sum != mf.SHA256 {
+			report.Archive.Modified = append(report.Archive.Modified, mf.Path)
+		}
+	}
+	for name := range files {
+		if !listed[name] {
+			report.Archive.Extra = append(report.Archive.Extra, name)
+		}
+	}
+	sort.Strings(report.Archive.Extra)
+	report.DigestOK = report.HasHashes && ArchiveDigest(m.Files) == m.Digest &&
+		(m.Incremental == nil || ArchiveDigest(m.State()) == m.Incremental.StateDigest)
+
+	if strings.TrimSpace(opts.SwagDir) != "" {
+		live, err := verifyLive(filepath.Clean(strings.TrimSpace(opts.SwagDir)), m)
+		if err != nil {
+			return report, err
+		}
+		report.Live = &live
+	}
+	return report, nil
+}
+
+// verifyLive 按 manifest 记录的导出选项重新规划现有目录的文件，与 manifest 记录的完整状态比对
+func verifyLive(swagDir string, m Manifest) (FileDiff, error) {
+	var diff FileDiff
+
+	entries, err := buildFilePlan(swagDir, m.planOptions())
+	if err != nil {
+		return diff, err
+	}
+	live := make(map[string]fileEntry, len(entries))
+	for _, e := range entries {
+		live[e.RelPath] = e
+	}
+
+	state := m.State()
+	listed := make(map[string]bool, len(state))
+	for _, mf := range state {
+		listed[mf.Path] = true
+		abs := filepath.Join(swagDir, filepath.FromSlash(mf.Path))
+		info, err := os.Lstat(abs)
+		if err != nil {
+			if os.IsNotExist(err) {
+				diff.Missing = append(diff.Missing, mf.Path)
+				continue
+			}
+			return diff, fmt.Errorf("读取现有文件失败 (%s): %w", abs, err)
+		}
+		isLink := info.Mode()&os.ModeSymlink != 0
+		if isLink != mf.Symlink || (!isLink && info.Size() != mf.Size) {
+			diff.Modified = append(diff.Modified, mf.Path)
+			continue
+		}
+		if mf.SHA256 == "" {
+			continue
+		}
+		var sum string
+		if isLink {
+			var target []byte
+			if target, _, err = readLive(abs); err == nil {
+				sum = sha256Hex(target)
+			}
+		} else {
+			sum, err = hashFile(abs)
+		}
+		if err != nil {
+			return diff, err
+		}
+		if sum != mf.SHA256 {
+			diff.Modified = append(diff.Modified, mf.Path)
+		}
+	}
+	// pre-import 备份只包含被覆盖的文件，不检查多余文件
+	for rel := range live {
+		if !listed[rel] && m.Profile != ProfilePreImport {
+			diff.Extra = append(diff.Extra, rel)
+		}
+	}
+	sort.Strings(diff.Extra)
+	return diff, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败 (%s): %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("读取文件失败 (%s): %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,142 @@
+package swagexport
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDetectsArchiveTampering(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outZip, Profile: ProfileMinimal, ProxyConfOnly: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	m, err := ReadManifest(outZip, Decryption{})
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if m.FormatVersion != FormatVersion || m.Digest == "" {
+		t.Fatalf("manifest missing digest: %+v", m)
+	}
+	for _, f := range m.Files {
+		if len(f.SHA256) != 64 {
+			t.Fatalf("manifest file without sha256: %+v", f)
+		}
+	}
+
+	report, err := Verify(VerifyOptions{ArchivePath: outZip})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() || !report.DigestOK {
+		t.Fatalf("fresh export should verify: %+v", report)
+	}
+
+	// 相同大小的篡改内容也应被 sha256 发现
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	rewriteZip(t, outZip, tampered, map[string]string{"config/nginx/proxy-confs/a.subdomain.conf": "# b\n"})
+	report, err = Verify(VerifyOptions{ArchivePath: tampered})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.OK() || strings.Join(report.Archive.Modified, ",") != "config/nginx/proxy-confs/a.subdomain.conf" {
+		t.Fatalf("tampering not detected: %+v", report.Archive)
+	}
+	if _, err := PlanImport(ImportOptions{ArchivePath: tampered, SwagDir: t.TempDir()}); err == nil {
+		t.Fatalf("PlanImport() should reject tampered archive")
+	}
+}
+
+func TestVerifyAgainstLiveDir(t *testing.T) {
+	t.Parallel()
+
+	swagDir := newMinimalSwagDir(t)
+	outZip := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := Export(Options{SwagDir: swagDir, OutPath: outZip, Profile: ProfileMinimal, ProxyConfOnly: true}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# changed\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "new.subdomain.conf"), "# new\n")
+	if err := os.Remove(filepath.Join(swagDir, "compose.yaml")); err != nil {
+		t.Fatalf("remove error = %v", err)
+	}
+
+	report, err := Verify(VerifyOptions{ArchivePath: outZip, SwagDir: swagDir})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("archive itself should be intact: %+v", report.Archive)
+	}
+	live := report.Live
+	if live == nil {
+		t.Fatalf("Live report missing")
+	}
+	if strings.Join(live.Missing, ",") != "compose.yaml" {
+		t.Fatalf("Missing = %v", live.Missing)
+	}
+	if strings.Join(live.Modified, ",") != "config/nginx/proxy-confs/a.subdomain.conf" {
+		t.Fatalf("Modified = %v", live.Modified)
+	}
+	if strings.Join(live.Extra, ",") != "config/nginx/proxy-confs/new.subdomain.conf" {
+		t.Fatalf("Extra = %v", live.Extra)
+	}
+}
+
+func newMinimalSwagDir(t *testing.T) string {
+	t.Helper()
+
+	swagDir := t.TempDir()
+	mustWrite(t, filepath.Join(swagDir, "compose.yaml"), "services: {}\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "nginx.conf"), "worker_processes  1;\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "site-confs", "default"), "# default\n")
+	mustWrite(t, filepath.Join(swagDir, "config", "nginx", "proxy-confs", "a.subdomain.conf"), "# a\n")
+	return swagDir
+}
+
+func rewriteZip(t *testing.T, src, dst string, replace map[string]string) {
+	t.Helper()
+
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		t.Fatalf("open zip error = %v", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatalf("create zip error = %v", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			t.Fatalf("zip create error = %v", err)
+		}
+		if content, ok := replace[f.Name]; ok {
+			_, err = io.WriteString(w, content)
+		} else {
+			var rc io.ReadCloser
+			if rc, err = f.Open(); err == nil {
+				_, err = io.Copy(w, rc)
+				_ = rc.Close()
+			}
+		}
+		if err != nil {
+			t.Fatalf("zip write error = %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close error = %v", err)
+	}
+}
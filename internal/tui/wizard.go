@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"swag-cli/internal/apply"
 	"swag-cli/internal/config"
 	"swag-cli/internal/docker"
 	"swag-cli/internal/nginx"
@@ -218,6 +219,7 @@ func runAddFlow(swagDir string, swagContainerName string, network string) {
 		color.Red("Docker 连接失败: %v", err)
 		return
 	}
+	defer cli.Close()
 
 	containers, err := cli.ListContainersByNetwork(context.Background(), network)
 	if err != nil {
@@ -320,8 +322,36 @@ func runAddFlow(swagDir string, swagContainerName string, network string) {
 
 	color.Green("配置已生成: %s", path)
 
-	// 5. Restart SWAG Container
-	restartSwagContainer(swagContainerName)
+	// 5. 检查证书覆盖；若已通过 compose 重建容器则无需再重启
+	recreated, err := ensureCertCoverage(cli, cfg, swagContainerName, answers.Subdomain)
+	if err != nil {
+		color.Red("证书覆盖处理失败: %v", err)
+		return
+	}
+	if recreated {
+		return
+	}
+
+	// 6. 应用配置
+	applySwagChanges(swagContainerName)
+}
+
+// ensureCertCoverage 检查新站点是否被 SWAG 证书覆盖，未覆盖时询问是否更新 compose.yaml 并重建容器。
+// 返回 true 表示已重建容器；读取容器、更新 compose.yaml 或重建失败时返回错误。
+func ensureCertCoverage(cli *docker.Client, cfg config.Config, swagContainerName string, subdomain string) (bool, error) {
+	c := apply.CertCoverage{
+		Docker:      cli,
+		Container:   swagContainerName,
+		ComposePath: cfg.ComposePath(),
+		WaitTimeout: cfg.WaitTimeoutDuration(),
+		Confirm: func(prompt string) (bool, error) {
+			ok := false
+			err := survey.AskOne(&survey.Confirm{Message: prompt, Default: true}, &ok)
+			return ok, err
+		},
+		LogWriter: os.Stderr,
+	}
+	return c.Ensure(context.Background(), subdomain)
 }
 
 func runHomepageFlow(swagDir string, swagContainerName string, network string) {
@@ -343,6 +373,7 @@ func runHomepageFlow(swagDir string, swagContainerName string, network string) {
 		color.Red("Docker 连接失败: %v", err)
 		return
 	}
+	defer cli.Close()
 
 	containers, err := cli.ListContainersByNetwork(context.Background(), network)
 	if err != nil {
@@ -450,14 +481,7 @@ func runHomepageFlow(swagDir string, swagContainerName string, network string) {
 	}
 	color.Green("主页已更新: %s", defaultPath)
 
-	color.Yellow("正在重载 SWAG (%s) Nginx...", swagContainerName)
-	if err := cli.ReloadNginx(context.Background(), swagContainerName); err != nil {
-		color.Red("Nginx 重载失败: %v", err)
-		color.Yellow("将尝试重启容器以应用配置...")
-		restartSwagContainer(swagContainerName)
-		return
-	}
-	color.Green("Nginx 重载成功！站点应已生效。")
+	applySwagChanges(swagContainerName)
 }
 
 func runListFlow(swagDir string, swagContainerName string, network string) {
@@ -487,6 +511,7 @@ func runListFlow(swagDir string, swagContainerName string, network string) {
 					containerMap[c.Name] = c
 				}
 			}
+			_ = cli.Close()
 		}
 
 		// 分组
@@ -623,7 +648,7 @@ func runSiteActionFlow(site nginx.SiteConfig, manager *nginx.Manager, swagContai
 			} else {
 				color.Yellow("站点已禁用")
 			}
-			restartSwagContainer(swagContainerName)
+			applySwagChanges(swagContainerName)
 		}
 	case "删除站点 (Delete)":
 		confirm := false
@@ -636,22 +661,41 @@ func runSiteActionFlow(site nginx.SiteConfig, manager *nginx.Manager, swagContai
 				color.Red("删除失败: %v", err)
 			} else {
 				color.Green("站点已删除")
-				restartSwagContainer(swagContainerName)
+				applySwagChanges(swagContainerName)
 			}
 		}
 	}
 }
 
-func restartSwagContainer(swagContainerName string) {
-	color.Yellow("正在重启 SWAG 容器 (%s)...", swagContainerName)
-	cli, err := docker.NewClient()
+// applySwagChanges 按配置中的 reload 策略让 SWAG 应用配置变更
+func applySwagChanges(swagContainerName string) {
+	cfg, err := config.Load()
 	if err != nil {
-		color.Red("Docker 连接失败，无法重启容器: %v", err)
+		color.Red("加载配置失败: %v", err)
 		return
 	}
-	if err := cli.RestartContainer(context.Background(), swagContainerName); err != nil {
-		color.Red("SWAG 容器重启失败: %v", err)
-	} else {
-		color.Green("SWAG 容器重启成功！站点应已生效。")
+	strategy, err := apply.ParseStrategy(cfg.ReloadStrategy)
+	if err != nil {
+		color.Red("%v", err)
+		return
+	}
+
+	svc := apply.Service{
+		Container:   swagContainerName,
+		Strategy:    strategy,
+		WaitTimeout: cfg.WaitTimeoutDuration(),
+		LogWriter:   os.Stderr,
+	}
+	if strategy != apply.StrategyNone {
+		cli, err := docker.NewClient()
+		if err != nil {
+			color.Red("Docker 连接失败，无法应用配置: %v", err)
+			return
+		}
+		defer cli.Close()
+		svc.Docker = cli
+	}
+	if err := svc.Apply(context.Background()); err != nil {
+		color.Red("应用配置失败: %v", err)
 	}
 }
@@ -16,6 +16,11 @@ server {
     listen [::]:443 ssl;
 
     server_name {{ .Subdomain }}.*;
+{{- if .SiteLogs }}
+
+    access_log {{ siteAccessLog .Subdomain }};
+    error_log {{ siteErrorLog .Subdomain }};
+{{- end }}
 
     include /config/nginx/ssl.conf;
 